@@ -0,0 +1,151 @@
+package mysql
+
+import (
+	"errors"
+	"strings"
+)
+
+// maxPlaceholders is the MySQL limit on placeholders in a prepared statement.
+const maxPlaceholders = 65535
+
+// BulkInsert inserts rows into table using multi-row statements of the form
+// "INSERT INTO `table` (`a`, `b`) VALUES (?, ?), (?, ?), ...", executing
+// batchSize rows per statement. A non-positive batchSize inserts everything
+// in as few statements as the MySQL placeholder limit allows; larger values
+// are clamped to that limit as well.
+//
+// Every row must have exactly len(columns) values. Each distinct statement
+// shape (full batches and the final partial batch) is prepared once and
+// reused through the prepared statement cache. Identifiers are quoted with
+// backticks; table may be qualified as "database.table".
+//
+// The returned ExecResult aggregates RowsAffected across batches and reports
+// the LastInsertID of the last executed batch. If a batch fails, earlier
+// batches remain applied and the result reflects them alongside the error.
+// When Options.OnTableWrite is configured it is invoked after rows were
+// written so dependent cache entries can be invalidated.
+func BulkInsert(c *MySQL, table string, columns []string, rows [][]any, batchSize int) (*ExecResult, *MySQLError) {
+	if table == "" || len(columns) == 0 {
+		return nil, NewError(errors.New("mysql: BulkInsert requires a table and at least one column"))
+	}
+	for _, row := range rows {
+		if len(row) != len(columns) {
+			return nil, NewError(errors.New("mysql: BulkInsert row length does not match column count"))
+		}
+	}
+
+	if !c.beginQuery() {
+		return nil, ErrClosed
+	}
+	defer c.endQuery()
+
+	result := &ExecResult{}
+	if len(rows) == 0 {
+		return result, nil
+	}
+
+	limit := maxPlaceholders / len(columns)
+	if batchSize <= 0 || batchSize > limit {
+		batchSize = limit
+	}
+
+	prefix := bulkInsertPrefix(table, columns)
+	args := make([]any, 0, batchSize*len(columns))
+
+	var merr *MySQLError
+	for start := 0; start < len(rows); start += batchSize {
+		end := start + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		args = args[:0]
+		for _, row := range rows[start:end] {
+			args = append(args, row...)
+		}
+
+		if merr = c.execBatch(bulkInsertQuery(prefix, len(columns), end-start), args, result); merr != nil {
+			break
+		}
+	}
+
+	if result.RowsAffected > 0 && c.onTableWrite != nil {
+		c.onTableWrite(table)
+	}
+	return result, merr
+}
+
+// execBatch prepares (or reuses) query and executes it with args,
+// accumulating the outcome into result.
+func (c *MySQL) execBatch(query string, args []any, result *ExecResult) *MySQLError {
+	stmt, err := c.prepareStatement(Params{}, query)
+	if err != nil {
+		return c.mapError(err)
+	}
+
+	ctx, cancel := createContextWithTimeout(nil, 0)
+	defer cancel()
+
+	release, err := c.acquireQuerySlot(ctx)
+	if err != nil {
+		return c.mapError(err)
+	}
+	defer release()
+
+	res, err := c.execStmt(ctx, Params{Args: args}, query, stmt)
+	if err != nil {
+		return c.mapError(err)
+	}
+	result.add(res)
+	return nil
+}
+
+// bulkInsertPrefix builds "INSERT INTO `table` (`col1`, `col2`) VALUES ".
+func bulkInsertPrefix(table string, columns []string) string {
+	var sb strings.Builder
+	sb.WriteString("INSERT INTO ")
+	for i, part := range strings.Split(table, ".") {
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		writeIdentifier(&sb, part)
+	}
+	sb.WriteString(" (")
+	for i, col := range columns {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		writeIdentifier(&sb, col)
+	}
+	sb.WriteString(") VALUES ")
+	return sb.String()
+}
+
+// bulkInsertQuery appends rowCount placeholder tuples of width columns to prefix.
+func bulkInsertQuery(prefix string, columns, rowCount int) string {
+	var sb strings.Builder
+	// Each tuple is "(?, ?)" = 2 + 3*columns - 2, plus ", " between tuples
+	sb.Grow(len(prefix) + rowCount*(3*columns+2))
+	sb.WriteString(prefix)
+	for r := 0; r < rowCount; r++ {
+		if r > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteByte('(')
+		for col := 0; col < columns; col++ {
+			if col > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteByte('?')
+		}
+		sb.WriteByte(')')
+	}
+	return sb.String()
+}
+
+// writeIdentifier writes name quoted with backticks, doubling embedded backticks.
+func writeIdentifier(sb *strings.Builder, name string) {
+	sb.WriteByte('`')
+	sb.WriteString(strings.ReplaceAll(name, "`", "``"))
+	sb.WriteByte('`')
+}
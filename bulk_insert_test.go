@@ -0,0 +1,160 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	driver "github.com/go-sql-driver/mysql"
+)
+
+type execCall struct {
+	query string
+	args  []any
+}
+
+// execRecordingDB records every executed statement and its arguments.
+type execRecordingDB struct {
+	noDirectStatements
+	prepares []string
+	execs    []execCall
+	failOn   int // 1-based exec call that fails (0 = never)
+	err      error
+}
+
+func (d *execRecordingDB) PrepareContext(ctx context.Context, query string) (Stmt, error) {
+	d.prepares = append(d.prepares, query)
+	return &execRecordingStmt{db: d, query: query}, nil
+}
+
+func (d *execRecordingDB) Close() error { return nil }
+
+type execRecordingStmt struct {
+	db    *execRecordingDB
+	query string
+}
+
+func (s *execRecordingStmt) QueryContext(ctx context.Context, args ...any) (Rows, error) {
+	return nil, errors.New("unexpected query")
+}
+
+func (s *execRecordingStmt) QueryRowContext(ctx context.Context, args ...any) Row {
+	return rowFromRows(s.QueryContext(ctx, args...))
+}
+
+func (s *execRecordingStmt) ExecContext(ctx context.Context, args ...any) (sql.Result, error) {
+	s.db.execs = append(s.db.execs, execCall{query: s.query, args: append([]any(nil), args...)})
+	if s.db.failOn == len(s.db.execs) {
+		return nil, s.db.err
+	}
+	return MockResult{LastID: int64(len(s.db.execs) * 100), Affected: int64(len(args) / 2)}, nil
+}
+
+func (s *execRecordingStmt) Close() error { return nil }
+
+func TestBulkInsert_BatchBoundaries(t *testing.T) {
+	db := &execRecordingDB{}
+	var written []string
+	client := &MySQL{
+		DB:           db,
+		prepare:      make(map[string]Stmt),
+		onTableWrite: func(table string) { written = append(written, table) },
+	}
+
+	rows := [][]any{{1, "a"}, {2, "b"}, {3, "c"}, {4, "d"}, {5, "e"}}
+	res, err := BulkInsert(client, "app.users", []string{"id", "name"}, rows, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	full := "INSERT INTO `app`.`users` (`id`, `name`) VALUES (?, ?), (?, ?)"
+	partial := "INSERT INTO `app`.`users` (`id`, `name`) VALUES (?, ?)"
+	if len(db.execs) != 3 {
+		t.Fatalf("expected 3 batches, got %d", len(db.execs))
+	}
+	if db.execs[0].query != full || db.execs[1].query != full || db.execs[2].query != partial {
+		t.Fatalf("unexpected statements: %q, %q, %q", db.execs[0].query, db.execs[1].query, db.execs[2].query)
+	}
+	if len(db.prepares) != 2 {
+		t.Fatalf("expected one prepare per statement shape, got %v", db.prepares)
+	}
+	if got := db.execs[1].args; len(got) != 4 || got[0] != 3 || got[3] != "d" {
+		t.Fatalf("unexpected args for second batch: %v", got)
+	}
+	if got := db.execs[2].args; len(got) != 2 || got[0] != 5 {
+		t.Fatalf("unexpected args for final batch: %v", got)
+	}
+
+	if res.RowsAffected != 5 || res.LastInsertID != 300 {
+		t.Fatalf("unexpected aggregate result: %+v", res)
+	}
+	if len(written) != 1 || written[0] != "app.users" {
+		t.Fatalf("expected write hook to fire once, got %v", written)
+	}
+}
+
+func TestBulkInsert_EmptyInput(t *testing.T) {
+	db := &execRecordingDB{}
+	client := &MySQL{DB: db, prepare: make(map[string]Stmt)}
+
+	res, err := BulkInsert(client, "users", []string{"id"}, nil, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if res.RowsAffected != 0 || len(db.prepares) != 0 {
+		t.Fatalf("expected no database access for empty input")
+	}
+}
+
+func TestBulkInsert_DefaultBatchSize(t *testing.T) {
+	db := &execRecordingDB{}
+	client := &MySQL{DB: db, prepare: make(map[string]Stmt)}
+
+	rows := [][]any{{1, "a"}, {2, "b"}, {3, "c"}}
+	if _, err := BulkInsert(client, "users", []string{"id", "name"}, rows, 0); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(db.execs) != 1 || len(db.execs[0].args) != 6 {
+		t.Fatalf("expected a single batch, got %+v", db.execs)
+	}
+}
+
+func TestBulkInsert_InvalidInput(t *testing.T) {
+	client := &MySQL{DB: &execRecordingDB{}, prepare: make(map[string]Stmt)}
+
+	if _, err := BulkInsert(client, "", []string{"id"}, [][]any{{1}}, 1); err == nil {
+		t.Fatalf("expected error for empty table")
+	}
+	if _, err := BulkInsert(client, "users", nil, [][]any{{1}}, 1); err == nil {
+		t.Fatalf("expected error for missing columns")
+	}
+	if _, err := BulkInsert(client, "users", []string{"id", "name"}, [][]any{{1}}, 1); err == nil {
+		t.Fatalf("expected error for mismatched row length")
+	}
+}
+
+func TestBulkInsert_PartialFailure(t *testing.T) {
+	db := &execRecordingDB{failOn: 2, err: &driver.MySQLError{Number: 1062, Message: "Duplicate entry"}}
+	client := &MySQL{DB: db, prepare: make(map[string]Stmt)}
+
+	rows := [][]any{{1, "a"}, {2, "b"}, {3, "c"}}
+	res, err := BulkInsert(client, "users", []string{"id", "name"}, rows, 1)
+	if err == nil || !IsDuplicateKey(err) {
+		t.Fatalf("expected duplicate key error, got %+v", err)
+	}
+	if res.RowsAffected != 1 {
+		t.Fatalf("expected first batch to be reported, got %+v", res)
+	}
+	if len(db.execs) != 2 {
+		t.Fatalf("expected processing to stop after the failing batch")
+	}
+}
+
+func TestBulkInsertPrefix_QuotesIdentifiers(t *testing.T) {
+	got := bulkInsertPrefix("we`ird", []string{"a`b"})
+	want := "INSERT INTO `we``ird` (`a``b`) VALUES "
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
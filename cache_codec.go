@@ -0,0 +1,103 @@
+package mysql
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// codecHeader prefixes external cache values encoded with a per-query codec
+// (Params.Codec), or with the client codec under Options.TagCacheCodec. It
+// is followed by the uvarint-prefixed codec name, so a reader can pick the
+// matching decoder. Untagged values are decoded with the client codec and
+// remain readable by older releases.
+var codecHeader = []byte{0xC1, 'c'}
+
+// errUnknownCodec is returned when a cached value names a codec that is
+// neither the query's nor the client's.
+var errUnknownCodec = errors.New("mysql: cached value uses an unknown codec")
+
+// NamedCodec is an optional interface for codecs that report a stable name.
+// The name is recorded with values written by a per-query codec; codecs
+// without it are identified by their Go type name.
+type NamedCodec interface {
+	Codec
+	Name() string
+}
+
+// codecName returns the identifier recorded for cd.
+func codecName(cd Codec) string {
+	if named, ok := cd.(NamedCodec); ok {
+		return named.Name()
+	}
+	return fmt.Sprintf("%T", cd)
+}
+
+// marshalCacheValue serializes v with override, or with the client codec
+// when override is nil. Override payloads are tagged with the codec name, as
+// are client codec payloads with Options.TagCacheCodec.
+func (c *MySQL) marshalCacheValue(override Codec, v any) ([]byte, error) {
+	cd := override
+	if cd == nil {
+		if !c.tagCodec {
+			return c.codec.Marshal(v)
+		}
+		cd = c.codec
+	}
+
+	payload, err := cd.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	name := codecName(cd)
+	out := make([]byte, 0, len(codecHeader)+binary.MaxVarintLen64+len(name)+len(payload))
+	out = append(out, codecHeader...)
+	out = binary.AppendUvarint(out, uint64(len(name)))
+	out = append(out, name...)
+	return append(out, payload...), nil
+}
+
+// unmarshalCacheValue decodes data into dst with the codec that wrote it:
+// the one named in the header (matched against override, the client codec,
+// and Options.LegacyCodecs), or the client codec for untagged values. An
+// untagged value the client codec rejects is retried with each legacy codec
+// in order, since entries written before a codec change carry no tag.
+func (c *MySQL) unmarshalCacheValue(override Codec, data []byte, dst any) error {
+	if !bytes.HasPrefix(data, codecHeader) {
+		err := c.codec.Unmarshal(data, dst)
+		if err == nil || len(c.legacyCodecs) == 0 {
+			return err
+		}
+		for _, legacy := range c.legacyCodecs {
+			// Drop whatever the failed attempt left behind
+			resetTarget(dst)
+			if legacy.Unmarshal(data, dst) == nil {
+				c.cacheStats.legacyDecodes.Add(1)
+				return nil
+			}
+		}
+		return err
+	}
+
+	name, payload, ok := readField(data[len(codecHeader):])
+	if !ok {
+		return errUnknownCodec
+	}
+	switch {
+	case override != nil && codecName(override) == string(name):
+		return override.Unmarshal(payload, dst)
+	case codecName(c.codec) == string(name):
+		return c.codec.Unmarshal(payload, dst)
+	}
+	for _, legacy := range c.legacyCodecs {
+		if codecName(legacy) == string(name) {
+			if err := legacy.Unmarshal(payload, dst); err != nil {
+				return err
+			}
+			c.cacheStats.legacyDecodes.Add(1)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w %q", errUnknownCodec, name)
+}
@@ -0,0 +1,155 @@
+package mysql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// jsonTestCodec is a named JSON codec used to mix codecs across queries.
+type jsonTestCodec struct{}
+
+func (jsonTestCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonTestCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (jsonTestCodec) Name() string                       { return "json" }
+
+func newCodecClient(t *testing.T, cache Storage) (*MySQL, *int, func()) {
+	t.Helper()
+	calls := 0
+	db := NewMockDB()
+	for _, q := range []string{"SELECT a", "SELECT b"} {
+		q := q
+		db.WithStmt(q, &MockStmt{Factory: func() Rows {
+			calls++
+			return &MockRows{data: [][]any{{q}}}
+		}})
+	}
+	client, cleanup := newExternalClient(db, cache)
+	return client, &calls, cleanup
+}
+
+func TestQuery_PerQueryCodec(t *testing.T) {
+	cache := newFakeCache()
+	client, calls, cleanup := newCodecClient(t, cache)
+	defer cleanup()
+
+	msgpackParams := Params{Query: "SELECT a", CacheDelay: time.Minute}
+	jsonParams := Params{Query: "SELECT b", CacheDelay: time.Minute, Codec: jsonTestCodec{}}
+
+	for _, p := range []Params{msgpackParams, jsonParams} {
+		if _, err := Query(client, p, scanStrings); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	raw, _ := cache.Get(CreateKey(jsonParams, client))
+	if !bytes.HasPrefix(raw, codecHeader) || !bytes.HasSuffix(raw, []byte(`["SELECT b"]`)) {
+		t.Fatalf("expected a tagged JSON payload, got %q", raw)
+	}
+	raw, _ = cache.Get(CreateKey(msgpackParams, client))
+	if bytes.HasPrefix(raw, codecHeader) {
+		t.Fatalf("expected default-codec payload to stay untagged")
+	}
+
+	// Both entries decode with their own codec
+	for _, p := range []Params{msgpackParams, jsonParams} {
+		res, err := Query(client, p, scanStrings)
+		if err != nil || (*res)[0] != p.Query {
+			t.Fatalf("unexpected cached result %v (%v)", res, err)
+		}
+	}
+	if *calls != 2 {
+		t.Fatalf("expected cache hits, got %d database calls", *calls)
+	}
+}
+
+func TestQuery_PerQueryCodecSurvivesDefaultChange(t *testing.T) {
+	cache := newFakeCache()
+	client, calls, cleanup := newCodecClient(t, cache)
+	defer cleanup()
+
+	jsonParams := Params{Query: "SELECT b", CacheDelay: time.Minute, Codec: jsonTestCodec{}}
+	if _, err := Query(client, jsonParams, scanStrings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The default becomes JSON; the tagged entry is still matched by name,
+	// even by a reader that no longer sets the override
+	client.codec = jsonTestCodec{}
+	plain := jsonParams
+	plain.Codec = nil
+	res, err := Query(client, plain, scanStrings)
+	if err != nil || (*res)[0] != "SELECT b" || *calls != 1 {
+		t.Fatalf("expected a cache hit decoded as JSON, got %v (%v), %d calls", res, err, *calls)
+	}
+
+	// A reader that knows neither codec treats the entry as a miss
+	client.codec = MsgpackCodec{}
+	if _, err := Query(client, plain, scanStrings); err != nil || *calls != 2 {
+		t.Fatalf("expected an unknown codec to fall back to the database, got %d calls (%v)", *calls, err)
+	}
+}
+
+func TestQuery_LegacyCodecsDuringMigration(t *testing.T) {
+	for _, tagged := range []bool{false, true} {
+		cache := newFakeCache()
+		old, oldCalls, cleanupOld := newCodecClient(t, cache)
+		old.tagCodec = tagged
+
+		params := Params{Query: "SELECT a", CacheDelay: time.Minute}
+		if _, err := Query(old, params, scanStrings); err != nil || *oldCalls != 1 {
+			t.Fatalf("tagged=%v: unexpected error: %v", tagged, err)
+		}
+		cleanupOld()
+
+		// The next deploy switches to JSON but still accepts MessagePack
+		migrated, calls, cleanup := newCodecClient(t, cache)
+		migrated.codec = jsonTestCodec{}
+		migrated.tagCodec = tagged
+		migrated.legacyCodecs = []Codec{MsgpackCodec{}}
+
+		res, err := Query(migrated, params, scanStrings)
+		if err != nil || (*res)[0] != "SELECT a" || *calls != 0 {
+			t.Fatalf("tagged=%v: expected the legacy entry to be decoded, got %v (%v), %d calls", tagged, res, err, *calls)
+		}
+		if stats := migrated.Stats(); stats.LegacyDecodes == 0 || stats.DecodeErrors != 0 {
+			t.Fatalf("tagged=%v: unexpected stats %+v", tagged, stats)
+		}
+		cleanup()
+	}
+}
+
+func TestQuery_TaggedCodecMismatchIsCounted(t *testing.T) {
+	cache := newFakeCache()
+	writer, _, cleanupWriter := newCodecClient(t, cache)
+	defer cleanupWriter()
+	writer.tagCodec = true
+
+	params := Params{Query: "SELECT a", CacheDelay: time.Minute}
+	if _, err := Query(writer, params, scanStrings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := cache.Get(CreateKey(params, writer))
+	if !bytes.HasPrefix(data, codecHeader) {
+		t.Fatal("expected TagCacheCodec to tag client codec values")
+	}
+
+	reader, calls, cleanup := newCodecClient(t, cache)
+	defer cleanup()
+	reader.codec = jsonTestCodec{}
+
+	var hookErr error
+	reader.onCacheDecodeError = func(_ string, err error) { hookErr = err }
+	if _, err := Query(reader, params, scanStrings); err != nil || *calls != 1 {
+		t.Fatalf("expected a miss served by the database, got %d calls (%v)", *calls, err)
+	}
+	if stats := reader.Stats(); stats.CodecMismatches == 0 || stats.CodecMismatches != stats.DecodeErrors {
+		t.Fatalf("expected codec mismatches to be counted, got %+v", stats)
+	}
+	if !errors.Is(hookErr, errUnknownCodec) || !strings.Contains(hookErr.Error(), "mysql.MsgpackCodec") {
+		t.Fatalf("expected the hook to name the unknown codec, got %v", hookErr)
+	}
+}
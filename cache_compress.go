@@ -0,0 +1,67 @@
+package mysql
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+)
+
+// Flag bytes prefixed to external cache values when CompressOverBytes is set.
+const (
+	cacheFlagRaw  byte = 0 // Payload follows uncompressed
+	cacheFlagGzip byte = 1 // Payload follows gzip-compressed
+)
+
+// errCacheFlag is returned when a cached value carries an unknown flag byte.
+var errCacheFlag = errors.New("mysql: unknown cache value flag")
+
+// encodeCacheValue prepares serialized bytes for the external cache.
+// Without a compression threshold the data is stored unchanged. Otherwise it
+// is prefixed with a flag byte and gzip-compressed when larger than the threshold.
+func (c *MySQL) encodeCacheValue(data []byte) ([]byte, error) {
+	if c.compressOver <= 0 {
+		return data, nil
+	}
+
+	if len(data) <= c.compressOver {
+		out := make([]byte, 0, len(data)+1)
+		out = append(out, cacheFlagRaw)
+		return append(out, data...), nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(cacheFlagGzip)
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeCacheValue reverses encodeCacheValue, decompressing when flagged.
+func (c *MySQL) decodeCacheValue(data []byte) ([]byte, error) {
+	if c.compressOver <= 0 {
+		return data, nil
+	}
+	if len(data) == 0 {
+		return nil, errCacheFlag
+	}
+
+	switch data[0] {
+	case cacheFlagRaw:
+		return data[1:], nil
+	case cacheFlagGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(data[1:]))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+	default:
+		return nil, errCacheFlag
+	}
+}
@@ -0,0 +1,123 @@
+package mysql
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestCacheValue_Threshold(t *testing.T) {
+	c := &MySQL{compressOver: 16}
+
+	small := []byte("tiny")
+	large := bytes.Repeat([]byte("abcdefgh"), 64)
+
+	enc, err := c.encodeCacheValue(small)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if enc[0] != cacheFlagRaw || !bytes.Equal(enc[1:], small) {
+		t.Fatalf("expected raw flag and payload, got %v", enc)
+	}
+
+	enc, err = c.encodeCacheValue(large)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if enc[0] != cacheFlagGzip {
+		t.Fatalf("expected gzip flag, got %d", enc[0])
+	}
+	if len(enc) >= len(large) {
+		t.Fatalf("expected compressed payload to be smaller: %d >= %d", len(enc), len(large))
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(enc[1:]))
+	if err != nil {
+		t.Fatalf("payload is not gzip: %v", err)
+	}
+	if raw, _ := io.ReadAll(zr); !bytes.Equal(raw, large) {
+		t.Fatalf("gzip payload mismatch")
+	}
+
+	for _, in := range [][]byte{small, large, bytes.Repeat([]byte{'x'}, 16)} {
+		enc, _ := c.encodeCacheValue(in)
+		dec, err := c.decodeCacheValue(enc)
+		if err != nil || !bytes.Equal(dec, in) {
+			t.Fatalf("round trip failed for %d bytes: %v", len(in), err)
+		}
+	}
+}
+
+func TestCacheValue_Disabled(t *testing.T) {
+	c := &MySQL{}
+	in := bytes.Repeat([]byte("z"), 1024)
+
+	enc, _ := c.encodeCacheValue(in)
+	if !bytes.Equal(enc, in) {
+		t.Fatalf("expected data to be stored unchanged without a threshold")
+	}
+	dec, _ := c.decodeCacheValue(enc)
+	if !bytes.Equal(dec, in) {
+		t.Fatalf("expected data to be read unchanged without a threshold")
+	}
+}
+
+func TestCacheValue_BadFlag(t *testing.T) {
+	c := &MySQL{compressOver: 1}
+	if _, err := c.decodeCacheValue([]byte{7, 1, 2}); err != errCacheFlag {
+		t.Fatalf("expected errCacheFlag, got %v", err)
+	}
+	if _, err := c.decodeCacheValue(nil); err != errCacheFlag {
+		t.Fatalf("expected errCacheFlag for empty value, got %v", err)
+	}
+	if _, err := c.decodeCacheValue([]byte{cacheFlagGzip, 1, 2}); err == nil {
+		t.Fatalf("expected error for corrupt gzip payload")
+	}
+}
+
+func TestQuery_ExternalCacheCompressed(t *testing.T) {
+	cache := newFakeCache()
+	stmt := &MockStmt{
+		Factory: func() Rows {
+			data := make([][]any, 200)
+			for i := range data {
+				data[i] = []any{i}
+			}
+			return &MockRows{data: data}
+		},
+	}
+	db := NewMockDB()
+	db.WithStmt("SELECT * FROM table", stmt)
+
+	client, cleanup := newExternalClient(db, cache)
+	defer cleanup()
+	client.compressOver = 64
+
+	params := Params{Query: "SELECT * FROM table", CacheDelay: time.Minute}
+	callback := func(rows Rows) (*[]int, *MySQLError) {
+		var out []int
+		for rows.Next() {
+			var v int
+			_ = rows.Scan(&v)
+			out = append(out, v)
+		}
+		return &out, nil
+	}
+
+	if _, err := Query(client, params, callback); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw, err := cache.Get(CreateKey(params, client))
+	if err != nil || raw[0] != cacheFlagGzip {
+		t.Fatalf("expected compressed cache entry, got %v (%v)", raw, err)
+	}
+
+	// Second call is served from L2 and must decompress transparently
+	db.WithStmt("SELECT * FROM table", &MockStmt{Err: io.ErrUnexpectedEOF})
+	client.prepare = make(map[string]Stmt)
+	res, qerr := Query(client, params, callback)
+	if qerr != nil || len(*res) != 200 || (*res)[199] != 199 {
+		t.Fatalf("expected cached result, got %v (%v)", res, qerr)
+	}
+}
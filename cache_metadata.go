@@ -0,0 +1,124 @@
+package mysql
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// metadataHeader starts every external cache value written with
+// StoreMetadata. 0xC1 is never emitted by MessagePack and is not valid
+// UTF-8, so legacy payloads from the bundled codecs cannot be mistaken for
+// an envelope. The trailing byte is the envelope format version.
+var metadataHeader = []byte{0xC1, 'm', 'd', 1}
+
+// ErrNoCacheMetadata is returned by CacheMetadata for entries stored without
+// a metadata envelope.
+var ErrNoCacheMetadata = errors.New("mysql: cache entry has no metadata")
+
+// errBadMetadata is returned when an envelope header is present but the
+// envelope itself is truncated.
+var errBadMetadata = errors.New("mysql: malformed cache metadata envelope")
+
+// CacheMetadata describes how an external cache entry was produced.
+type CacheMetadata struct {
+	Query    string    // Interpolated query (see DebugQuery), or its SHA-256 hex when HashMetadataQuery is set
+	StoredAt time.Time // When the entry was written
+	Codec    string    // Name of the codec that serialized the payload (see NamedCodec)
+}
+
+// wrapMetadata prefixes the codec payload with an envelope describing the
+// query that produced it. Values are returned unchanged unless StoreMetadata
+// is enabled.
+func (c *MySQL) wrapMetadata(params Params, data []byte) []byte {
+	if !c.storeMetadata {
+		return data
+	}
+
+	query := c.DebugQuery(params)
+	if c.hashMetadataQuery {
+		sum := sha256.Sum256([]byte(query))
+		query = hex.EncodeToString(sum[:])
+	}
+	codec := codecName(c.codec)
+	if params.Codec != nil {
+		codec = codecName(params.Codec)
+	}
+
+	out := make([]byte, 0, len(metadataHeader)+len(query)+len(codec)+len(data)+3*binary.MaxVarintLen64)
+	out = append(out, metadataHeader...)
+	out = binary.AppendUvarint(out, uint64(len(query)))
+	out = append(out, query...)
+	out = binary.AppendVarint(out, time.Now().UnixNano())
+	out = binary.AppendUvarint(out, uint64(len(codec)))
+	out = append(out, codec...)
+	return append(out, data...)
+}
+
+// unwrapMetadata splits an envelope into its metadata and codec payload.
+// Values without the header are legacy entries and are returned as-is with
+// nil metadata, so entries written before StoreMetadata stay readable.
+func unwrapMetadata(data []byte) (*CacheMetadata, []byte, error) {
+	if !bytes.HasPrefix(data, metadataHeader) {
+		return nil, data, nil
+	}
+	rest := data[len(metadataHeader):]
+
+	query, rest, ok := readField(rest)
+	if !ok {
+		return nil, nil, errBadMetadata
+	}
+	nanos, n := binary.Varint(rest)
+	if n <= 0 {
+		return nil, nil, errBadMetadata
+	}
+	rest = rest[n:]
+	codec, rest, ok := readField(rest)
+	if !ok {
+		return nil, nil, errBadMetadata
+	}
+
+	meta := &CacheMetadata{
+		Query:    string(query),
+		StoredAt: time.Unix(0, nanos),
+		Codec:    string(codec),
+	}
+	return meta, rest, nil
+}
+
+// readField reads a uvarint length-prefixed byte field from b.
+func readField(b []byte) (field, rest []byte, ok bool) {
+	size, n := binary.Uvarint(b)
+	if n <= 0 || size > uint64(len(b)-n) {
+		return nil, nil, false
+	}
+	b = b[n:]
+	return b[:size], b[size:], true
+}
+
+// CacheMetadata returns the metadata stored with the external cache entry
+// for key. It returns ErrNotFound for missing keys and ErrNoCacheMetadata for
+// entries written without StoreMetadata.
+func (c *MySQL) CacheMetadata(key string) (*CacheMetadata, error) {
+	if c.cache == nil {
+		return nil, ErrNotFound
+	}
+	data, err := c.cache.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = c.decodeCacheValue(data); err != nil {
+		return nil, err
+	}
+	meta, _, err := unwrapMetadata(data)
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		return nil, ErrNoCacheMetadata
+	}
+	return meta, nil
+}
@@ -0,0 +1,119 @@
+package mysql
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newMetadataClient(t *testing.T, cache Storage) (*MySQL, func()) {
+	t.Helper()
+	db := NewMockDB()
+	db.WithStmt("SELECT name FROM users WHERE id = ?", &MockStmt{Factory: func() Rows {
+		return &MockRows{data: [][]any{{"alice"}}}
+	}})
+	client, cleanup := newExternalClient(db, cache)
+	client.storeMetadata = true
+	return client, cleanup
+}
+
+var metadataParams = Params{
+	Query:      "SELECT name FROM users WHERE id = ?",
+	Args:       []any{7},
+	CacheDelay: time.Minute,
+}
+
+func TestCacheMetadata_RoundTrip(t *testing.T) {
+	cache := newFakeCache()
+	client, cleanup := newMetadataClient(t, cache)
+	defer cleanup()
+
+	before := time.Now()
+	if _, err := Query(client, metadataParams, scanStrings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	key := CreateKey(metadataParams, client)
+	raw, _ := cache.Get(key)
+	if !bytes.HasPrefix(raw, metadataHeader) {
+		t.Fatalf("expected enveloped value, got %x", raw)
+	}
+
+	meta, err := client.CacheMetadata(key)
+	if err != nil {
+		t.Fatalf("CacheMetadata: %v", err)
+	}
+	if meta.Query != "SELECT name FROM users WHERE id = 7" {
+		t.Fatalf("unexpected query %q", meta.Query)
+	}
+	if meta.Codec != "mysql.MsgpackCodec" {
+		t.Fatalf("unexpected codec %q", meta.Codec)
+	}
+	if meta.StoredAt.Before(before.Add(-time.Second)) || meta.StoredAt.After(time.Now()) {
+		t.Fatalf("unexpected timestamp %v", meta.StoredAt)
+	}
+
+	// Reads unwrap the envelope transparently (L1 is not configured)
+	res, merr := Query(client, metadataParams, func(rows Rows) (*[]string, *MySQLError) {
+		t.Fatal("expected an L2 cache hit")
+		return nil, nil
+	})
+	if merr != nil || len(*res) != 1 || (*res)[0] != "alice" {
+		t.Fatalf("unexpected cached result %v (%v)", res, merr)
+	}
+}
+
+func TestCacheMetadata_HashedQuery(t *testing.T) {
+	cache := newFakeCache()
+	client, cleanup := newMetadataClient(t, cache)
+	defer cleanup()
+	client.hashMetadataQuery = true
+	client.compressOver = 1 // envelope must survive compression too
+
+	if _, err := Query(client, metadataParams, scanStrings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	meta, err := client.CacheMetadata(CreateKey(metadataParams, client))
+	if err != nil {
+		t.Fatalf("CacheMetadata: %v", err)
+	}
+	sum := sha256.Sum256([]byte("SELECT name FROM users WHERE id = 7"))
+	if meta.Query != hex.EncodeToString(sum[:]) {
+		t.Fatalf("expected hashed query, got %q", meta.Query)
+	}
+}
+
+func TestCacheMetadata_LegacyEntries(t *testing.T) {
+	cache := newFakeCache()
+	client, cleanup := newMetadataClient(t, cache)
+	defer cleanup()
+
+	// An entry written before StoreMetadata was enabled
+	key := CreateKey(metadataParams, client)
+	legacy, _ := MsgpackCodec{}.Marshal(&[]string{"legacy"})
+	_ = cache.Set(key, legacy, time.Minute)
+
+	res, merr := Query(client, metadataParams, func(rows Rows) (*[]string, *MySQLError) {
+		t.Fatal("expected the legacy entry to be served from cache")
+		return nil, nil
+	})
+	if merr != nil || (*res)[0] != "legacy" {
+		t.Fatalf("unexpected result %v (%v)", res, merr)
+	}
+	if _, err := client.CacheMetadata(key); !errors.Is(err, ErrNoCacheMetadata) {
+		t.Fatalf("expected ErrNoCacheMetadata, got %v", err)
+	}
+	if _, err := client.CacheMetadata("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestUnwrapMetadata_Truncated(t *testing.T) {
+	bad := append(append([]byte(nil), metadataHeader...), 0x10, 'x')
+	if _, _, err := unwrapMetadata(bad); !errors.Is(err, errBadMetadata) {
+		t.Fatalf("expected errBadMetadata, got %v", err)
+	}
+}
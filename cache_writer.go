@@ -0,0 +1,145 @@
+package mysql
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Sizing of the asynchronous L2 cache writer.
+const (
+	cacheWriteWorkers = 4    // Goroutines performing cache writes
+	cacheWriteQueue   = 1024 // Pending writes buffered before falling back to synchronous writes
+)
+
+// cacheWrite is a single deferred Storage.Set call.
+type cacheWrite struct {
+	key  string
+	data []byte
+	exp  time.Duration
+}
+
+// cacheWriter performs L2 cache writes off the query path using a fixed pool
+// of workers fed by a bounded queue. When the queue is full or the writer is
+// closed, writes happen synchronously so no result is silently dropped.
+type cacheWriter struct {
+	cache  Storage
+	jobs   chan cacheWrite
+	wg     sync.WaitGroup
+	mu     sync.RWMutex // Orders enqueue against close
+	closed bool
+
+	pendingMu sync.Mutex
+	pending   int           // Queued writes not yet performed
+	idle      chan struct{} // Closed while pending is zero
+}
+
+// newCacheWriter starts the worker pool for the given storage.
+func newCacheWriter(cache Storage) *cacheWriter {
+	w := &cacheWriter{
+		cache: cache,
+		jobs:  make(chan cacheWrite, cacheWriteQueue),
+		idle:  make(chan struct{}),
+	}
+	close(w.idle)
+	w.wg.Add(cacheWriteWorkers)
+	for i := 0; i < cacheWriteWorkers; i++ {
+		go w.run()
+	}
+	return w
+}
+
+// run drains the queue until it is closed.
+func (w *cacheWriter) run() {
+	defer w.wg.Done()
+	for job := range w.jobs {
+		_ = w.cache.Set(job.key, job.data, job.exp) // Best-effort, like synchronous writes
+		w.done()
+	}
+}
+
+// begin records a write about to be queued.
+func (w *cacheWriter) begin() {
+	w.pendingMu.Lock()
+	if w.pending == 0 {
+		w.idle = make(chan struct{})
+	}
+	w.pending++
+	w.pendingMu.Unlock()
+}
+
+// done records a queued write as performed (or never queued).
+func (w *cacheWriter) done() {
+	w.pendingMu.Lock()
+	w.pending--
+	if w.pending == 0 {
+		close(w.idle)
+	}
+	w.pendingMu.Unlock()
+}
+
+// set schedules a cache write, performing it inline if the queue is full
+// or the writer has been closed.
+func (w *cacheWriter) set(key string, data []byte, exp time.Duration) {
+	w.mu.RLock()
+	if !w.closed {
+		w.begin() // Counted before the send so a fast worker can't finish first
+		select {
+		case w.jobs <- cacheWrite{key: key, data: data, exp: exp}:
+			w.mu.RUnlock()
+			return
+		default:
+			w.done()
+		}
+	}
+	w.mu.RUnlock()
+
+	_ = w.cache.Set(key, data, exp)
+}
+
+// flush blocks until every queued write has been performed or ctx is done.
+func (w *cacheWriter) flush(ctx context.Context) error {
+	w.pendingMu.Lock()
+	idle := w.idle
+	w.pendingMu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close stops accepting new jobs and waits for queued writes to land.
+// It is safe to call multiple times.
+func (w *cacheWriter) close() {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.jobs)
+	}
+	w.mu.Unlock()
+	w.wg.Wait()
+}
+
+// setExternalCache writes serialized data to the L2 cache, asynchronously
+// when AsyncCacheWrites is enabled. Synchronous writes pass ctx to a
+// ContextStorage; queued writes outlive the request and do not.
+func (c *MySQL) setExternalCache(ctx context.Context, key string, data []byte, exp time.Duration) {
+	if c.cacheWriter != nil {
+		c.cacheWriter.set(key, data, exp)
+		return
+	}
+	_ = cacheSet(ctx, c.cache, key, data, exp)
+}
+
+// Flush blocks until all queued asynchronous cache writes have landed or ctx
+// expires, returning the context error in the latter case. It returns
+// immediately when AsyncCacheWrites is disabled.
+func (c *MySQL) Flush(ctx context.Context) error {
+	if c.cacheWriter == nil {
+		return nil
+	}
+	return c.cacheWriter.flush(ctx)
+}
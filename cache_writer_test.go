@@ -0,0 +1,172 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingCache is a Storage whose Set blocks until release is closed.
+type blockingCache struct {
+	*fakeCache
+	release chan struct{}
+}
+
+func (c *blockingCache) Set(key string, val []byte, exp time.Duration) error {
+	<-c.release
+	return c.fakeCache.Set(key, val, exp)
+}
+
+func TestQuery_AsyncCacheWriteDoesNotBlock(t *testing.T) {
+	cache := &blockingCache{fakeCache: newFakeCache(), release: make(chan struct{})}
+	db := NewMockDB()
+	db.WithStmt("SELECT * FROM table", &MockStmt{Factory: func() Rows {
+		return &MockRows{data: [][]any{{1}}}
+	}})
+
+	client, cleanup := newExternalClient(db, cache)
+	defer cleanup()
+	client.cacheWriter = newCacheWriter(cache)
+
+	params := Params{Query: "SELECT * FROM table", CacheDelay: time.Minute}
+	done := make(chan *MySQLError, 1)
+	go func() {
+		_, err := Query(client, params, func(rows Rows) (*[]int, *MySQLError) {
+			out := []int{}
+			for rows.Next() {
+				var v int
+				_ = rows.Scan(&v)
+				out = append(out, v)
+			}
+			return &out, nil
+		})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Query blocked on the cache write")
+	}
+
+	if _, err := cache.Get(CreateKey(params, client)); err != ErrNotFound {
+		t.Fatalf("expected write to still be pending, got %v", err)
+	}
+
+	close(cache.release)
+	client.cacheWriter.close()
+
+	if _, err := cache.Get(CreateKey(params, client)); err != nil {
+		t.Fatalf("expected write to land after close, got %v", err)
+	}
+}
+
+func TestCacheWriter_DrainsOnClose(t *testing.T) {
+	cache := newFakeCache()
+	w := newCacheWriter(cache)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2*cacheWriteQueue; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w.set(fmt.Sprintf("k%d", i), []byte{byte(i)}, time.Minute)
+		}(i)
+	}
+	wg.Wait()
+	w.close()
+	w.close() // Idempotent
+
+	if len(cache.items) != 2*cacheWriteQueue {
+		t.Fatalf("expected %d writes to land, got %d", 2*cacheWriteQueue, len(cache.items))
+	}
+
+	// Writes after close happen synchronously rather than being lost
+	w.set("late", []byte("x"), time.Minute)
+	if _, err := cache.Get("late"); err != nil {
+		t.Fatalf("expected late write to land, got %v", err)
+	}
+}
+
+func TestClose_DrainsCacheWriter(t *testing.T) {
+	cache := &blockingCache{fakeCache: newFakeCache(), release: make(chan struct{})}
+	client, cleanup := newExternalClient(NewMockDB(), cache)
+	defer cleanup()
+	client.stop = make(chan struct{}, 1)
+	client.cacheWriter = newCacheWriter(cache)
+
+	client.setExternalCache(nil, "k", []byte("v"), time.Minute)
+
+	closed := make(chan struct{})
+	go func() {
+		client.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+		t.Fatal("Close returned before pending cache writes landed")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(cache.release)
+	<-closed
+	if _, err := cache.Get("k"); err != nil {
+		t.Fatalf("expected pending write to land, got %v", err)
+	}
+}
+
+func TestFlush_WaitsForQueuedWrites(t *testing.T) {
+	cache := &blockingCache{fakeCache: newFakeCache(), release: make(chan struct{})}
+	client, cleanup := newExternalClient(NewMockDB(), cache)
+	defer cleanup()
+	client.cacheWriter = newCacheWriter(cache)
+	defer client.cacheWriter.close()
+
+	const writes = 10
+	for i := 0; i < writes; i++ {
+		client.setExternalCache(nil, fmt.Sprintf("k%d", i), []byte{byte(i)}, time.Minute)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := client.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected Flush to time out while writes are blocked, got %v", err)
+	}
+
+	flushed := make(chan error, 1)
+	go func() { flushed <- client.Flush(context.Background()) }()
+	close(cache.release)
+
+	select {
+	case err := <-flushed:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Flush did not return after writes were released")
+	}
+
+	for i := 0; i < writes; i++ {
+		if _, err := cache.Get(fmt.Sprintf("k%d", i)); err != nil {
+			t.Fatalf("expected k%d to be visible after Flush, got %v", i, err)
+		}
+	}
+}
+
+func TestFlush_NoopWithoutAsyncWrites(t *testing.T) {
+	client, cleanup := newExternalClient(NewMockDB(), newFakeCache())
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := client.Flush(ctx); err != nil {
+		t.Fatalf("expected nil without async writes, got %v", err)
+	}
+}
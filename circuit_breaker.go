@@ -0,0 +1,151 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCacheCircuitOpen is returned by the external cache while its circuit
+// breaker is open; queries then skip the cache and go straight to the database.
+var ErrCacheCircuitOpen = errors.New("mysql: cache circuit breaker open")
+
+// Default cooldown used when only a failure threshold is configured.
+const defaultBreakerCooldown = 30 * time.Second
+
+// breakerState is the state of a circuitBreaker.
+type breakerState int
+
+const (
+	breakerClosed   breakerState = iota // Calls pass through
+	breakerOpen                         // Calls are rejected until the cooldown elapses
+	breakerHalfOpen                     // A single probe call is in flight
+)
+
+// String returns the state name reported by Status.
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// circuitBreaker trips after threshold consecutive failures and rejects calls
+// for cooldown. After the cooldown one probe call is let through: success
+// closes the breaker, failure re-opens it for another cooldown.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	clock     clock
+	state     breakerState
+	failures  int
+	openedAt  time.Time
+}
+
+// newCircuitBreaker creates a closed breaker.
+func newCircuitBreaker(threshold int, cooldown time.Duration, clk clock) *circuitBreaker {
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, clock: clk}
+}
+
+// allow reports whether a call may proceed. Every allowed call must be
+// followed by done with the call's outcome.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.clock.Now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen // Cooldown over: let this call probe
+		return true
+	case breakerHalfOpen:
+		return false // Only one probe at a time
+	default:
+		return true
+	}
+}
+
+// current returns the breaker state.
+func (b *circuitBreaker) current() breakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// done records the outcome of an allowed call.
+func (b *circuitBreaker) done(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		b.state = breakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openedAt = b.clock.Now()
+	}
+}
+
+// breakerStorage guards a Storage with a circuitBreaker. Misses
+// (ErrNotFound) and calls abandoned by a cancelled request count as
+// successful calls.
+type breakerStorage struct {
+	Storage
+	breaker *circuitBreaker
+}
+
+// newBreakerStorage wraps cache with a breaker that opens after threshold
+// consecutive failures for cooldown.
+func newBreakerStorage(cache Storage, threshold int, cooldown time.Duration, clk clock) *breakerStorage {
+	return &breakerStorage{Storage: cache, breaker: newCircuitBreaker(threshold, cooldown, clk)}
+}
+
+// call runs fn through the breaker.
+func (s *breakerStorage) call(fn func() error) error {
+	if !s.breaker.allow() {
+		return ErrCacheCircuitOpen
+	}
+	err := fn()
+	s.breaker.done(err != nil && !errors.Is(err, ErrNotFound) && !errors.Is(err, context.Canceled))
+	return err
+}
+
+// Get implements Storage.
+func (s *breakerStorage) Get(key string) ([]byte, error) {
+	var data []byte
+	err := s.call(func() (err error) {
+		data, err = s.Storage.Get(key)
+		return err
+	})
+	return data, err
+}
+
+// Set implements Storage.
+func (s *breakerStorage) Set(key string, val []byte, exp time.Duration) error {
+	return s.call(func() error { return s.Storage.Set(key, val, exp) })
+}
+
+// Delete implements Storage.
+func (s *breakerStorage) Delete(key string) error {
+	return s.call(func() error { return s.Storage.Delete(key) })
+}
+
+// Reset implements Storage.
+func (s *breakerStorage) Reset() error {
+	return s.call(s.Storage.Reset)
+}
@@ -0,0 +1,121 @@
+package mysql
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// flakyCache counts backend calls and fails while down is set.
+type flakyCache struct {
+	*fakeCache
+	down  bool
+	calls int
+}
+
+func (c *flakyCache) Get(key string) ([]byte, error) {
+	c.calls++
+	if c.down {
+		return nil, errors.New("connection refused")
+	}
+	return c.fakeCache.Get(key)
+}
+
+func (c *flakyCache) Set(key string, val []byte, exp time.Duration) error {
+	c.calls++
+	if c.down {
+		return errors.New("connection refused")
+	}
+	return c.fakeCache.Set(key, val, exp)
+}
+
+func TestBreakerStorage_OpensAndHalfOpens(t *testing.T) {
+	clk := newFakeClock()
+	backend := &flakyCache{fakeCache: newFakeCache(), down: true}
+	cache := newBreakerStorage(backend, 3, time.Minute, clk)
+
+	// Three consecutive failures open the breaker
+	for i := 0; i < 3; i++ {
+		if _, err := cache.Get("k"); err == nil || errors.Is(err, ErrCacheCircuitOpen) {
+			t.Fatalf("call %d: expected backend error, got %v", i, err)
+		}
+	}
+	if _, err := cache.Get("k"); !errors.Is(err, ErrCacheCircuitOpen) {
+		t.Fatalf("expected open breaker, got %v", err)
+	}
+	if err := cache.Set("k", nil, 0); !errors.Is(err, ErrCacheCircuitOpen) {
+		t.Fatalf("expected Set to be short-circuited, got %v", err)
+	}
+	if backend.calls != 3 {
+		t.Fatalf("expected backend to be skipped while open, got %d calls", backend.calls)
+	}
+
+	// After the cooldown a single probe is allowed; failure re-opens
+	clk.Advance(time.Minute)
+	if _, err := cache.Get("k"); errors.Is(err, ErrCacheCircuitOpen) {
+		t.Fatalf("expected probe to reach the backend")
+	}
+	if _, err := cache.Get("k"); !errors.Is(err, ErrCacheCircuitOpen) {
+		t.Fatalf("expected failed probe to re-open the breaker, got %v", err)
+	}
+
+	// A successful probe (a miss counts as success) closes it again
+	backend.down = false
+	clk.Advance(time.Minute)
+	if _, err := cache.Get("k"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected probe miss, got %v", err)
+	}
+	if err := cache.Set("k", []byte("v"), 0); err != nil {
+		t.Fatalf("expected closed breaker, got %v", err)
+	}
+}
+
+func TestBreakerStorage_SingleProbe(t *testing.T) {
+	clk := newFakeClock()
+	b := newCircuitBreaker(1, time.Second, clk)
+	b.done(true)
+	clk.Advance(time.Second)
+
+	if !b.allow() {
+		t.Fatalf("expected probe to be allowed after cooldown")
+	}
+	if b.allow() {
+		t.Fatalf("expected concurrent calls to be rejected while probing")
+	}
+	b.done(false)
+	if !b.allow() {
+		t.Fatalf("expected successful probe to close the breaker")
+	}
+}
+
+func TestQuery_BreakerSkipsCache(t *testing.T) {
+	clk := newFakeClock()
+	backend := &flakyCache{fakeCache: newFakeCache(), down: true}
+	calls := 0
+	db := NewMockDB()
+	db.WithStmt("SELECT * FROM table", &MockStmt{Factory: func() Rows {
+		calls++
+		return &MockRows{data: [][]any{{"fresh"}}}
+	}})
+
+	client, cleanup := newExternalClient(db, newBreakerStorage(backend, 2, time.Minute, clk))
+	defer cleanup()
+	client.logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	params := Params{Query: "SELECT * FROM table", CacheDelay: time.Minute}
+	for i := 0; i < 5; i++ {
+		res, err := Query(client, params, scanStrings)
+		if err != nil || (*res)[0] != "fresh" {
+			t.Fatalf("query %d: expected DB result, got %v %+v", i, res, err)
+		}
+	}
+	if calls != 5 {
+		t.Fatalf("expected every query to hit the DB, got %d", calls)
+	}
+	// Query 1: Get fails, Set fails -> breaker open; later queries skip the backend
+	if backend.calls != 2 {
+		t.Fatalf("expected the backend to be skipped once the breaker opened, got %d calls", backend.calls)
+	}
+}
@@ -0,0 +1,42 @@
+package mysql
+
+import "time"
+
+// clock abstracts the time source used by InMemoryStorage.
+// Production code uses realClock; tests inject a fake implementation
+// so that expiry and the cleanup loop can be driven deterministically.
+type clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// NewTicker returns a ticker that fires every d.
+	NewTicker(d time.Duration) ticker
+}
+
+// ticker abstracts *time.Ticker so fake clocks can control tick delivery.
+type ticker interface {
+	// C returns the channel on which ticks are delivered.
+	C() <-chan time.Time
+
+	// Stop turns off the ticker. No more ticks are sent after Stop returns.
+	Stop()
+}
+
+// realClock implements clock using the standard time package.
+type realClock struct{}
+
+// Now returns time.Now().
+func (realClock) Now() time.Time { return time.Now() }
+
+// NewTicker wraps time.NewTicker.
+func (realClock) NewTicker(d time.Duration) ticker {
+	return realTicker{Ticker: time.NewTicker(d)}
+}
+
+// realTicker adapts *time.Ticker to the ticker interface.
+type realTicker struct {
+	*time.Ticker
+}
+
+// C returns the underlying ticker channel.
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
@@ -0,0 +1,83 @@
+package mysql
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock for deterministic TTL tests.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTicker{ch: make(chan time.Time), stopped: make(chan struct{})}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// Advance moves the clock forward without firing any tickers.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Tick delivers a tick to every ticker and waits until it has been handled.
+// Ticks are sent twice on an unbuffered channel: the second send can only
+// complete once the receiver finished processing the first one.
+func (c *fakeClock) Tick() {
+	c.mu.Lock()
+	tickers := append([]*fakeTicker(nil), c.tickers...)
+	now := c.now
+	c.mu.Unlock()
+
+	for _, t := range tickers {
+		for i := 0; i < 2; i++ {
+			select {
+			case t.ch <- now:
+			case <-t.stopped:
+			}
+		}
+	}
+}
+
+type fakeTicker struct {
+	ch      chan time.Time
+	once    sync.Once
+	stopped chan struct{}
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.ch }
+
+func (t *fakeTicker) Stop() { t.once.Do(func() { close(t.stopped) }) }
+
+func TestRealClock(t *testing.T) {
+	clk := realClock{}
+	if clk.Now().IsZero() {
+		t.Fatalf("expected non-zero time")
+	}
+
+	tk := clk.NewTicker(time.Millisecond)
+	defer tk.Stop()
+	select {
+	case <-tk.C():
+	case <-time.After(time.Second):
+		t.Fatalf("expected real ticker to fire")
+	}
+}
@@ -1,6 +1,8 @@
 package mysql
 
 import (
+	"encoding/json"
+
 	"github.com/vmihailenco/msgpack/v5"
 )
 
@@ -35,3 +37,26 @@ func (MsgpackCodec) Marshal(v any) ([]byte, error) {
 func (MsgpackCodec) Unmarshal(data []byte, v any) error {
 	return msgpack.Unmarshal(data, v)
 }
+
+// JSONCodec implements the Codec interface using encoding/json. It is
+// slower and larger than MessagePack but human-readable in the cache.
+type JSONCodec struct{}
+
+// Marshal serializes a Go value with json.Marshal.
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal deserializes JSON data into v with json.Unmarshal.
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// NewMsgpackCodec returns the default MessagePack codec.
+func NewMsgpackCodec() Codec { return MsgpackCodec{} }
+
+// NewJSONCodec returns a codec backed by encoding/json.
+//
+// Gob, CBOR, jsoniter and binc codecs live in the codec/* modules, which are
+// versioned separately so the root module does not depend on them.
+func NewJSONCodec() Codec { return JSONCodec{} }
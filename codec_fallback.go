@@ -0,0 +1,131 @@
+package mysql
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// errNoCodecMatch is returned by FallbackCodec when every codec's detector
+// rejected the data, so none was tried.
+var errNoCodecMatch = errors.New("mysql: no codec recognizes the data")
+
+// CodecDetector is an optional interface for codecs that can recognize their
+// own output. FallbackCodec skips a detecting codec whose Detect reports
+// false instead of letting it decode data written in another format, which
+// lenient decoders (MessagePack reads any leading byte as a value) would
+// otherwise accept and misinterpret.
+type CodecDetector interface {
+	Codec
+	Detect(data []byte) bool
+}
+
+// HeaderCodec wraps a codec with a fixed format header: Marshal prepends
+// Header, Unmarshal requires and strips it, and Detect reports its presence.
+// Wrap the new codec of a migration in a HeaderCodec so FallbackCodec can
+// tell its values from those of the codec it replaces.
+type HeaderCodec struct {
+	Codec  Codec
+	Header []byte
+}
+
+// Marshal encodes v with the wrapped codec and prefixes the header.
+func (h HeaderCodec) Marshal(v any) ([]byte, error) {
+	payload, err := h.Codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(h.Header)+len(payload))
+	out = append(out, h.Header...)
+	return append(out, payload...), nil
+}
+
+// Unmarshal strips the header and decodes the rest with the wrapped codec.
+// Data without the header is rejected.
+func (h HeaderCodec) Unmarshal(data []byte, v any) error {
+	if !h.Detect(data) {
+		return fmt.Errorf("mysql: data lacks the %q codec header", h.Header)
+	}
+	return h.Codec.Unmarshal(data[len(h.Header):], v)
+}
+
+// Detect reports whether data starts with the header.
+func (h HeaderCodec) Detect(data []byte) bool {
+	return bytes.HasPrefix(data, h.Header)
+}
+
+// FallbackCodec marshals with Primary and unmarshals with the first of
+// Primary and Fallbacks, in order, that accepts the data. Use it as
+// Options.Codec (or Params.Codec) while values written by a previous codec
+// are still around:
+//
+//	Codec: mysql.NewFallbackCodec(
+//		mysql.HeaderCodec{Codec: mysql.NewJSONCodec(), Header: []byte("j1")},
+//		mysql.NewMsgpackCodec(),
+//	)
+//
+// Codecs implementing CodecDetector are only tried on data they recognize.
+// Give the primary a detector (e.g. HeaderCodec), since a fallback reached
+// with the primary's own data could decode it into a wrong value.
+type FallbackCodec struct {
+	Primary   Codec
+	Fallbacks []Codec
+}
+
+// NewFallbackCodec returns a FallbackCodec writing with primary and reading
+// with primary, then each fallback in order.
+func NewFallbackCodec(primary Codec, fallbacks ...Codec) *FallbackCodec {
+	return &FallbackCodec{Primary: primary, Fallbacks: fallbacks}
+}
+
+// Name reports the primary codec's name, so tagged cache values written
+// through a FallbackCodec are identified like those of the primary.
+func (f *FallbackCodec) Name() string {
+	return codecName(f.Primary)
+}
+
+// Marshal encodes v with the primary codec.
+func (f *FallbackCodec) Marshal(v any) ([]byte, error) {
+	return f.Primary.Marshal(v)
+}
+
+// Unmarshal decodes data with the first codec that accepts it. If all fail,
+// the error of the first codec tried is returned.
+func (f *FallbackCodec) Unmarshal(data []byte, v any) error {
+	var first error
+	tried := false
+	for i := -1; i < len(f.Fallbacks); i++ {
+		cd := f.Primary
+		if i >= 0 {
+			cd = f.Fallbacks[i]
+		}
+		if det, ok := cd.(CodecDetector); ok && !det.Detect(data) {
+			continue
+		}
+		if tried {
+			// Drop whatever the failed attempt left behind
+			resetTarget(v)
+		}
+		tried = true
+		err := cd.Unmarshal(data, v)
+		if err == nil {
+			return nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	if !tried {
+		return errNoCodecMatch
+	}
+	return first
+}
+
+// resetTarget zeroes the value v points to, if v is a non-nil pointer.
+func resetTarget(v any) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv.Elem().SetZero()
+	}
+}
@@ -0,0 +1,87 @@
+package mysql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFallbackCodec_ReadsValuesOfThePreviousCodec(t *testing.T) {
+	cache := newFakeCache()
+	client, calls, cleanup := newCodecClient(t, cache)
+	defer cleanup()
+	client.codec = JSONCodec{}
+
+	params := Params{Query: "SELECT a", CacheDelay: time.Minute}
+	if _, err := Query(client, params, scanStrings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Switch to MessagePack, keeping JSON for the entries already in L2
+	client.codec = NewFallbackCodec(HeaderCodec{Codec: MsgpackCodec{}, Header: []byte("m2")}, JSONCodec{})
+	client.inMemory.(*InMemoryStorage).Reset()
+
+	res, err := Query(client, params, scanStrings)
+	if err != nil || (*res)[0] != "SELECT a" {
+		t.Fatalf("expected the JSON entry to be read, got %v (%v)", res, err)
+	}
+	if *calls != 1 {
+		t.Fatalf("expected the second read to hit L2, got %d DB calls", *calls)
+	}
+
+	// New entries are written with the primary codec
+	other := Params{Query: "SELECT b", CacheDelay: time.Minute}
+	if _, err := Query(client, other, scanStrings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw, _ := cache.Get(CreateKey(other, client))
+	if !bytes.HasPrefix(raw, []byte("m2")) {
+		t.Fatalf("expected a header-tagged MessagePack payload, got %q", raw)
+	}
+}
+
+func TestFallbackCodec_DetectorGuardsAgainstMisreads(t *testing.T) {
+	old, _ := JSONCodec{}.Marshal(7)
+
+	// Unguarded, MessagePack reads the JSON text "7" as the fixint 0x37
+	var n int
+	if err := NewFallbackCodec(MsgpackCodec{}, JSONCodec{}).Unmarshal(old, &n); err != nil || n != '7' {
+		t.Fatalf("expected the unguarded misread to yield %d, got %d (%v)", '7', n, err)
+	}
+
+	guarded := NewFallbackCodec(HeaderCodec{Codec: MsgpackCodec{}, Header: []byte("m2")}, JSONCodec{})
+	n = 0
+	if err := guarded.Unmarshal(old, &n); err != nil || n != 7 {
+		t.Fatalf("expected the JSON fallback to decode 7, got %d (%v)", n, err)
+	}
+
+	fresh, err := guarded.Marshal(8)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if err := guarded.Unmarshal(fresh, &n); err != nil || n != 8 {
+		t.Fatalf("expected the primary to decode 8, got %d (%v)", n, err)
+	}
+}
+
+func TestFallbackCodec_Errors(t *testing.T) {
+	headerOnly := NewFallbackCodec(HeaderCodec{Codec: JSONCodec{}, Header: []byte("j1")})
+	var v []string
+	if err := headerOnly.Unmarshal([]byte(`["a"]`), &v); !errors.Is(err, errNoCodecMatch) {
+		t.Fatalf("expected errNoCodecMatch, got %v", err)
+	}
+
+	// The primary's error is reported, and dst is not left half-filled
+	cd := NewFallbackCodec(JSONCodec{}, MsgpackCodec{})
+	v = []string{"stale"}
+	err := cd.Unmarshal([]byte(`["a"`), &v)
+	if err == nil || v != nil {
+		t.Fatalf("expected an error and a reset target, got %v (%v)", v, err)
+	}
+	var syntax *json.SyntaxError
+	if !errors.As(err, &syntax) || cd.Name() != "mysql.JSONCodec" {
+		t.Fatalf("unexpected error %v or name %q", err, cd.Name())
+	}
+}
@@ -1,6 +1,9 @@
 package mysql
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestMsgpackCodec_RoundTrip(t *testing.T) {
 	type payload struct {
@@ -33,3 +36,41 @@ func TestMsgpackCodec_MarshalError(t *testing.T) {
 		t.Fatalf("expected marshal error for unsupported type")
 	}
 }
+
+func TestCodecFactories_RoundTrip(t *testing.T) {
+	type payload struct {
+		ID   int
+		Name string
+		Tags []string
+	}
+	original := payload{ID: 7, Name: "alice", Tags: []string{"a", "b"}}
+
+	for name, codec := range map[string]Codec{
+		"msgpack": NewMsgpackCodec(),
+		"json":    NewJSONCodec(),
+	} {
+		data, err := codec.Marshal(original)
+		if err != nil {
+			t.Fatalf("%s: Marshal failed: %v", name, err)
+		}
+		var decoded payload
+		if err := codec.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("%s: Unmarshal failed: %v", name, err)
+		}
+		if !reflect.DeepEqual(decoded, original) {
+			t.Fatalf("%s: expected %+v, got %+v", name, original, decoded)
+		}
+	}
+}
+
+func TestCodecFactories_Errors(t *testing.T) {
+	for name, codec := range map[string]Codec{"json": NewJSONCodec()} {
+		if _, err := codec.Marshal(make(chan int)); err == nil {
+			t.Fatalf("%s: expected marshal error for unsupported type", name)
+		}
+		var v int
+		if err := codec.Unmarshal([]byte{0xff}, &v); err == nil {
+			t.Fatalf("%s: expected unmarshal error for malformed data", name)
+		}
+	}
+}
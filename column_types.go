@@ -0,0 +1,56 @@
+package mysql
+
+import "database/sql"
+
+// TypedRows is implemented by Rows that also expose column type metadata,
+// such as *sql.Rows. Generic helpers use it, when available, to decode values
+// according to their database type; they fall back to the raw driver values
+// for Rows that do not implement it (e.g. MockRows).
+type TypedRows interface {
+	Rows
+
+	// ColumnTypes returns column information such as the database type name.
+	ColumnTypes() ([]*sql.ColumnType, error)
+}
+
+// columnTypeNames returns the database type name of every column, or nil
+// when rows carries no type metadata.
+func columnTypeNames(rows Rows) []string {
+	typed, ok := rows.(TypedRows)
+	if !ok {
+		return nil
+	}
+	types, err := typed.ColumnTypes()
+	if err != nil || len(types) == 0 {
+		return nil
+	}
+	names := make([]string, len(types))
+	for i, ct := range types {
+		names[i] = ct.DatabaseTypeName()
+	}
+	return names
+}
+
+// textualType reports whether values of the named database type are text,
+// so []byte values received for it should be exposed as strings. DECIMAL is
+// included to keep exact precision instead of converting to float64.
+func textualType(name string) bool {
+	switch name {
+	case "CHAR", "VARCHAR", "TEXT", "TINYTEXT", "MEDIUMTEXT", "LONGTEXT",
+		"ENUM", "SET", "JSON", "DECIMAL",
+		"DATE", "DATETIME", "TIMESTAMP", "TIME", "YEAR":
+		return true
+	default:
+		return false
+	}
+}
+
+// coerceRow converts raw []byte values in row to strings for textual columns.
+// Binary columns (BLOB, BINARY, BIT, ...) keep their []byte values.
+func coerceRow(row []any, typeNames []string) {
+	for i, v := range row {
+		if b, ok := v.([]byte); ok && i < len(typeNames) && textualType(typeNames[i]) {
+			row[i] = string(b)
+		}
+	}
+}
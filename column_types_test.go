@@ -0,0 +1,132 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// typedConnector serves a single result set whose columns report database
+// type names, like go-sql-driver/mysql does.
+type typedConnector struct {
+	cols  []string
+	types []string
+	rows  [][]driver.Value
+}
+
+func (c *typedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &typedConn{c: c}, nil
+}
+
+func (c *typedConnector) Driver() driver.Driver {
+	return testDriver{}
+}
+
+type typedConn struct {
+	c *typedConnector
+}
+
+func (c *typedConn) Prepare(query string) (driver.Stmt, error) {
+	return &typedStmt{c: c.c}, nil
+}
+
+func (c *typedConn) Close() error {
+	return nil
+}
+
+func (c *typedConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+type typedStmt struct {
+	c *typedConnector
+}
+
+func (s *typedStmt) Close() error {
+	return nil
+}
+
+func (s *typedStmt) NumInput() int {
+	return -1
+}
+
+func (s *typedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *typedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &typedRows{c: s.c}, nil
+}
+
+type typedRows struct {
+	c   *typedConnector
+	idx int
+}
+
+func (r *typedRows) Columns() []string {
+	return r.c.cols
+}
+
+func (r *typedRows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.c.types[index]
+}
+
+func (r *typedRows) Close() error {
+	return nil
+}
+
+func (r *typedRows) Next(dest []driver.Value) error {
+	if r.idx >= len(r.c.rows) {
+		return io.EOF
+	}
+	copy(dest, r.c.rows[r.idx])
+	r.idx++
+	return nil
+}
+
+func TestScanAll_CoercesByColumnType(t *testing.T) {
+	db := sql.OpenDB(&typedConnector{
+		cols:  []string{"price", "name", "avatar", "qty", "note"},
+		types: []string{"DECIMAL", "VARCHAR", "BLOB", "INT", "TEXT"},
+		rows: [][]driver.Value{
+			{[]byte("10.50"), []byte("bob"), []byte{0x01, 0x02}, int64(3), nil},
+		},
+	})
+	defer db.Close()
+
+	rows, err := db.Query("SELECT 1")
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	defer rows.Close()
+
+	var _ TypedRows = rows
+
+	got, err := ScanAll(rows)
+	if err != nil {
+		t.Fatalf("ScanAll: %v", err)
+	}
+	want := [][]any{{"10.50", "bob", []byte{0x01, 0x02}, int64(3), nil}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestScanAll_WithoutColumnTypesKeepsRawValues(t *testing.T) {
+	rows := &MockRows{
+		cols: []string{"price"},
+		data: [][]any{{[]byte("10.50")}},
+	}
+
+	got, err := ScanAll(rows)
+	if err != nil {
+		t.Fatalf("ScanAll: %v", err)
+	}
+	if b, ok := got[0][0].([]byte); !ok || string(b) != "10.50" {
+		t.Fatalf("expected raw []byte, got %#v", got[0][0])
+	}
+}
@@ -0,0 +1,109 @@
+package mysql
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// MySQL error numbers reported for integrity constraint violations.
+const (
+	errDupEntry             = 1062 // ER_DUP_ENTRY
+	errRowIsReferenced      = 1217 // ER_ROW_IS_REFERENCED (legacy, no constraint name)
+	errNoReferencedRow      = 1216 // ER_NO_REFERENCED_ROW (legacy, no constraint name)
+	errRowIsReferenced2     = 1451 // ER_ROW_IS_REFERENCED_2
+	errNoReferencedRow2     = 1452 // ER_NO_REFERENCED_ROW_2
+	errDupEntryWithKeyName  = 1586 // ER_DUP_ENTRY_WITH_KEY_NAME
+	errForeignDuplicateKey  = 1557 // ER_FOREIGN_DUPLICATE_KEY_WITH_CHILD_INFO
+	errForeignDuplicateKey2 = 1761 // ER_FOREIGN_DUPLICATE_KEY_WITH_CHILD_INFO (5.6+)
+)
+
+// IsDuplicateKey reports whether err is a unique/primary key violation
+// (MySQL error 1062 and related codes). It unwraps both *MySQLError and
+// the driver's *mysql.MySQLError.
+func IsDuplicateKey(err error) bool {
+	switch errorNumber(err) {
+	case errDupEntry, errDupEntryWithKeyName, errForeignDuplicateKey, errForeignDuplicateKey2:
+		return true
+	}
+	return false
+}
+
+// IsForeignKeyViolation reports whether err is a foreign key violation,
+// either a missing parent row (1452/1216) or a referenced row that cannot
+// be deleted or updated (1451/1217).
+func IsForeignKeyViolation(err error) bool {
+	switch errorNumber(err) {
+	case errRowIsReferenced, errNoReferencedRow, errRowIsReferenced2, errNoReferencedRow2:
+		return true
+	}
+	return false
+}
+
+// ConstraintName extracts the name of the violated key or constraint from
+// a duplicate-key or foreign-key error message. Returns an empty string when
+// err is not such a violation or the server message does not include a name.
+//
+// Examples of recognized messages:
+//
+//	Duplicate entry 'a@b.c' for key 'users.email'          -> "users.email"
+//	... a foreign key constraint fails (`db`.`orders`, CONSTRAINT `fk_user` ...) -> "fk_user"
+func ConstraintName(err error) string {
+	msg := errorMessage(err)
+	switch {
+	case IsDuplicateKey(err):
+		// The key name is the last quoted token: "... for key 'name'"
+		idx := strings.LastIndex(msg, "for key ")
+		if idx < 0 {
+			return ""
+		}
+		return unquote(msg[idx+len("for key "):])
+	case IsForeignKeyViolation(err):
+		idx := strings.Index(msg, "CONSTRAINT ")
+		if idx < 0 {
+			return ""
+		}
+		return unquote(msg[idx+len("CONSTRAINT "):])
+	}
+	return ""
+}
+
+// errorNumber returns the MySQL error number carried by err, or 0.
+func errorNumber(err error) uint16 {
+	var merr *MySQLError
+	if errors.As(err, &merr) && merr != nil {
+		return merr.Number
+	}
+	var derr *mysql.MySQLError
+	if errors.As(err, &derr) && derr != nil {
+		return derr.Number
+	}
+	return 0
+}
+
+// errorMessage returns the server message carried by err, or "".
+func errorMessage(err error) string {
+	var merr *MySQLError
+	if errors.As(err, &merr) && merr != nil {
+		return merr.Message
+	}
+	var derr *mysql.MySQLError
+	if errors.As(err, &derr) && derr != nil {
+		return derr.Message
+	}
+	return ""
+}
+
+// unquote returns the identifier at the start of s enclosed in single quotes
+// or backticks. Returns "" when s does not start with a quoted identifier.
+func unquote(s string) string {
+	if s == "" || (s[0] != '\'' && s[0] != '`') {
+		return ""
+	}
+	end := strings.IndexByte(s[1:], s[0])
+	if end < 0 {
+		return ""
+	}
+	return s[1 : end+1]
+}
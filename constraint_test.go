@@ -0,0 +1,107 @@
+package mysql
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+func TestIsDuplicateKey(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+		key  string
+	}{
+		{
+			name: "mysql8_qualified_key",
+			err:  &MySQLError{Number: 1062, Message: "Duplicate entry 'a@b.c' for key 'users.email'"},
+			want: true,
+			key:  "users.email",
+		},
+		{
+			name: "mysql57_plain_key",
+			err:  &mysqldriver.MySQLError{Number: 1062, Message: "Duplicate entry '1' for key 'PRIMARY'"},
+			want: true,
+			key:  "PRIMARY",
+		},
+		{
+			name: "entry_contains_for_key",
+			err:  &MySQLError{Number: 1062, Message: "Duplicate entry 'x for key y' for key 'uniq_name'"},
+			want: true,
+			key:  "uniq_name",
+		},
+		{
+			name: "wrapped",
+			err:  fmt.Errorf("insert user: %w", &MySQLError{Number: 1062, Message: "Duplicate entry '1' for key 'PRIMARY'"}),
+			want: true,
+			key:  "PRIMARY",
+		},
+		{
+			name: "no_key_in_message",
+			err:  &MySQLError{Number: 1062, Message: "Duplicate entry"},
+			want: true,
+		},
+		{name: "other_error", err: &MySQLError{Number: 1064}},
+		{name: "generic", err: errors.New("boom")},
+		{name: "nil", err: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsDuplicateKey(tt.err); got != tt.want {
+				t.Fatalf("IsDuplicateKey = %v, want %v", got, tt.want)
+			}
+			if got := ConstraintName(tt.err); got != tt.key {
+				t.Fatalf("ConstraintName = %q, want %q", got, tt.key)
+			}
+		})
+	}
+}
+
+func TestIsForeignKeyViolation(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+		key  string
+	}{
+		{
+			name: "child_row",
+			err: &MySQLError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails " +
+				"(`shop`.`orders`, CONSTRAINT `fk_orders_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))"},
+			want: true,
+			key:  "fk_orders_user",
+		},
+		{
+			name: "parent_row",
+			err: &mysqldriver.MySQLError{Number: 1451, Message: "Cannot delete or update a parent row: a foreign key constraint fails " +
+				"(`shop`.`orders`, CONSTRAINT `fk_orders_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))"},
+			want: true,
+			key:  "fk_orders_user",
+		},
+		{
+			name: "legacy_without_name",
+			err:  &MySQLError{Number: 1216, Message: "Cannot add or update a child row: a foreign key constraint fails"},
+			want: true,
+		},
+		{name: "duplicate_is_not_fk", err: &MySQLError{Number: 1062, Message: "Duplicate entry '1' for key 'PRIMARY'"}},
+		{name: "generic", err: errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsForeignKeyViolation(tt.err); got != tt.want {
+				t.Fatalf("IsForeignKeyViolation = %v, want %v", got, tt.want)
+			}
+			if !tt.want {
+				return
+			}
+			if got := ConstraintName(tt.err); got != tt.key {
+				t.Fatalf("ConstraintName = %q, want %q", got, tt.key)
+			}
+		})
+	}
+}
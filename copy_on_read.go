@@ -0,0 +1,153 @@
+package mysql
+
+import (
+	"reflect"
+	"time"
+)
+
+// getL1 returns the L1 entry for key when it holds a *T. With CopyOnRead the
+// caller receives a deep copy, so mutating it cannot corrupt the cache.
+func getL1[T any](c *MySQL, key string) (*T, bool) {
+	val, err := c.inMemory.Get(key)
+	if err != nil {
+		return nil, false
+	}
+	res, ok := val.(*T)
+	if !ok {
+		return nil, false
+	}
+	c.cacheStats.l1Hits.Add(1)
+	if c.copyOnRead {
+		res = deepCopy(res)
+	}
+	return res, true
+}
+
+// setL1 stores v in L1 for ttl. With CopyOnRead a deep copy is stored, so
+// the caller keeps exclusive ownership of the value it was handed.
+func (c *MySQL) setL1(key string, v any, ttl time.Duration) {
+	if c.copyOnRead && v != nil {
+		v = copyValue(reflect.ValueOf(v)).Interface()
+	}
+	_ = c.inMemory.Set(key, v, ttl)
+}
+
+// deepCopy returns a copy of *v sharing no pointers, slices, or maps with
+// it. Unexported struct fields are copied shallowly. Shared and cyclic
+// references are preserved: a pointer, slice, or map reached twice is copied
+// once, so a value pointing to itself yields a copy pointing to itself.
+func deepCopy[T any](v *T) *T {
+	if v == nil {
+		return nil
+	}
+	return copyValue(reflect.ValueOf(v)).Interface().(*T)
+}
+
+// copyValue recursively copies src.
+func copyValue(src reflect.Value) reflect.Value {
+	var c copier
+	return c.copy(src)
+}
+
+// copyRef identifies a pointer, slice, or map already being copied. Slices
+// sharing a backing array but differing in length are distinct.
+type copyRef struct {
+	p   uintptr
+	n   int
+	typ reflect.Type
+}
+
+// copier copies values while mapping each visited reference to its copy,
+// like sizeEstimator, so cycles terminate.
+type copier struct {
+	seen map[copyRef]reflect.Value
+}
+
+// lookup returns the copy registered for ref, if any.
+func (c *copier) lookup(ref copyRef) (reflect.Value, bool) {
+	dst, ok := c.seen[ref]
+	return dst, ok
+}
+
+// register records dst as the copy of ref, before its contents are copied.
+func (c *copier) register(ref copyRef, dst reflect.Value) {
+	if c.seen == nil {
+		c.seen = make(map[copyRef]reflect.Value)
+	}
+	c.seen[ref] = dst
+}
+
+// copy recursively copies src.
+func (c *copier) copy(src reflect.Value) reflect.Value {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return src
+		}
+		ref := copyRef{p: src.Pointer(), typ: src.Type()}
+		if dst, ok := c.lookup(ref); ok {
+			return dst
+		}
+		dst := reflect.New(src.Type().Elem())
+		c.register(ref, dst)
+		dst.Elem().Set(c.copy(src.Elem()))
+		return dst
+	case reflect.Interface:
+		if src.IsNil() {
+			return src
+		}
+		dst := reflect.New(src.Type()).Elem()
+		dst.Set(c.copy(src.Elem()))
+		return dst
+	case reflect.Slice:
+		if src.IsNil() {
+			return src
+		}
+		ref := copyRef{p: src.Pointer(), n: src.Len(), typ: src.Type()}
+		if dst, ok := c.lookup(ref); ok {
+			return dst
+		}
+		dst := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		if src.Type().Elem().Kind() == reflect.Uint8 {
+			reflect.Copy(dst, src)
+			return dst
+		}
+		c.register(ref, dst)
+		for i := 0; i < src.Len(); i++ {
+			dst.Index(i).Set(c.copy(src.Index(i)))
+		}
+		return dst
+	case reflect.Map:
+		if src.IsNil() {
+			return src
+		}
+		ref := copyRef{p: src.Pointer(), typ: src.Type()}
+		if dst, ok := c.lookup(ref); ok {
+			return dst
+		}
+		dst := reflect.MakeMapWithSize(src.Type(), src.Len())
+		c.register(ref, dst)
+		iter := src.MapRange()
+		for iter.Next() {
+			dst.SetMapIndex(iter.Key(), c.copy(iter.Value()))
+		}
+		return dst
+	case reflect.Array:
+		dst := reflect.New(src.Type()).Elem()
+		for i := 0; i < src.Len(); i++ {
+			dst.Index(i).Set(c.copy(src.Index(i)))
+		}
+		return dst
+	case reflect.Struct:
+		dst := reflect.New(src.Type()).Elem()
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				dst.Field(i).Set(c.copy(src.Field(i)))
+			}
+		}
+		return dst
+	default:
+		return src
+	}
+}
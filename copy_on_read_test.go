@@ -0,0 +1,138 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type copyProfile struct {
+	Name  string
+	Tags  []string
+	Attrs map[string]int
+	Next  *copyProfile
+	Raw   []byte
+	Any   any
+	note  string
+}
+
+func TestDeepCopy_SharesNoMemory(t *testing.T) {
+	src := &copyProfile{
+		Name:  "a",
+		Tags:  []string{"x"},
+		Attrs: map[string]int{"k": 1},
+		Next:  &copyProfile{Name: "b"},
+		Raw:   []byte{1},
+		Any:   []int{1},
+		note:  "kept",
+	}
+	dst := deepCopy(src)
+	if !reflect.DeepEqual(src, dst) {
+		t.Fatalf("copy differs: %+v vs %+v", dst, src)
+	}
+
+	dst.Tags[0] = "y"
+	dst.Attrs["k"] = 2
+	dst.Next.Name = "c"
+	dst.Raw[0] = 2
+	dst.Any.([]int)[0] = 2
+	if src.Tags[0] != "x" || src.Attrs["k"] != 1 || src.Next.Name != "b" || src.Raw[0] != 1 || src.Any.([]int)[0] != 1 {
+		t.Fatalf("mutating the copy changed the source: %+v", src)
+	}
+}
+
+func TestQuery_CopyOnReadIsolatesCallers(t *testing.T) {
+	db := NewMockDB()
+	db.WithStmt("SELECT name FROM users", &MockStmt{Factory: func() Rows {
+		return &MockRows{data: [][]any{{"alice"}, {"bob"}}}
+	}})
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+	client.copyOnRead = true
+
+	params := Params{Query: "SELECT name FROM users", CacheDelay: time.Minute}
+	first, err := Query(client, params, scanStrings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	(*first)[0] = "mallory"
+
+	second, err := Query(client, params, scanStrings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if (*second)[0] != "alice" {
+		t.Fatalf("cache was corrupted by the first caller: %v", *second)
+	}
+	(*second)[1] = "eve"
+
+	third, _ := Query(client, params, scanStrings)
+	if (*third)[1] != "bob" {
+		t.Fatalf("cache hit shares memory with callers: %v", *third)
+	}
+}
+
+func TestQuery_CopyOnReadDisabledSharesValue(t *testing.T) {
+	client, cleanup := newInternalClient(&countingDB{})
+	defer cleanup()
+
+	cached := []string{"alice"}
+	client.inMemory.Set("users", &cached, time.Minute)
+
+	res, err := Query(client, Params{Query: "SELECT 1", Key: "users", CacheDelay: time.Minute}, scanStrings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res != &cached {
+		t.Fatal("expected the cached pointer to be returned without CopyOnRead")
+	}
+}
+
+func TestDeepCopy_CyclicValues(t *testing.T) {
+	n := &copyProfile{Name: "loop", Attrs: map[string]int{"a": 1}}
+	n.Next = n
+	n.Any = n.Attrs // Shared map reached twice
+
+	cp := deepCopy(n)
+	if cp == n || cp.Next != cp {
+		t.Fatalf("expected a distinct copy pointing to itself, got %p -> %p (source %p)", cp, cp.Next, n)
+	}
+	if reflect.ValueOf(cp.Any).Pointer() != reflect.ValueOf(cp.Attrs).Pointer() ||
+		reflect.ValueOf(cp.Attrs).Pointer() == reflect.ValueOf(n.Attrs).Pointer() {
+		t.Fatal("expected the shared map to be copied once")
+	}
+
+	// A slice containing itself through an interface
+	s := make([]any, 1)
+	s[0] = s
+	out := copyValue(reflect.ValueOf(s)).Interface().([]any)
+	if reflect.ValueOf(out[0]).Pointer() != reflect.ValueOf(out).Pointer() {
+		t.Fatal("expected the copied slice to contain itself")
+	}
+}
+
+func TestQuery_CopyOnReadCyclicResult(t *testing.T) {
+	db := NewMockDB()
+	db.WithStmt("SELECT name FROM users", &MockStmt{Factory: func() Rows {
+		return &MockRows{data: [][]any{{"alice"}}}
+	}})
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+	client.copyOnRead = true
+
+	scanLoop := func(rows Rows) (*copyProfile, *MySQLError) {
+		n := &copyProfile{}
+		for rows.Next() {
+			_ = rows.Scan(&n.Name)
+		}
+		n.Next = n
+		return n, nil
+	}
+	params := Params{Query: "SELECT name FROM users", CacheDelay: time.Minute}
+	for i := 0; i < 2; i++ {
+		res, err := Query(client, params, scanLoop)
+		if err != nil || res.Name != "alice" || res.Next != res {
+			t.Fatalf("query #%d: unexpected result %+v (%v)", i+1, res, err)
+		}
+	}
+}
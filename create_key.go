@@ -1,34 +1,235 @@
 package mysql
 
 import (
+	"bytes"
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
 	"strconv"
 	"time"
+	"unicode/utf8"
 	"unsafe"
 )
 
+// DefaultKeySeparator separates the query from each argument in generated
+// cache keys (ASCII unit separator). Options.CacheKeySeparator overrides it.
+const DefaultKeySeparator byte = 0x1F
+
+// keyEscape prefixes separator and escape bytes occurring inside the query
+// or argument portions of a key, so no two distinct argument lists produce
+// the same key.
+const keyEscape byte = '\\'
+
+// keyTimeLayout formats time.Time arguments in cache keys. It keeps all nine
+// fractional digits at a fixed width, so instants differing by less than a
+// second get distinct keys.
+const keyTimeLayout = "2006-01-02 15:04:05.000000000"
+
 // CreateKey generates a cache key from database parameters and query information.
-// The key is constructed in the format: "database:queryHash:arg1:arg2:...".
+// The key is constructed in the format: "database:query<sep>arg1<sep>arg2...",
+// where <sep> is DefaultKeySeparator or the client's CacheKeySeparator.
+// Separator and backslash bytes inside the query or arguments are escaped with
+// a backslash, so ("p", "a:b") and ("p:a", "b") can never share a key. The
+// version and database name escape ':' and backslash the same way.
+// time.Time arguments are formatted in UTC with nanosecond precision, so keys
+// do not depend on zones and sub-second differences are kept.
 // If no database name is provided and mysql connection is available, the connection's
 // database name is used. Query strings are hashed with MD5 for consistent key length.
+// When the client has a CacheVersion, the key is prefixed with "version:" so that
+// bumping the version orphans every previously generated key at once.
+// Params.Strict queries get a separate key (see appendStrictMarker).
 //
-// The function pre-allocates a buffer with exact size to avoid reallocations,
-// then constructs the key by concatenating components with ':' separators.
+// The function pre-allocates a buffer with exact size to avoid reallocations;
+// escaping, which is rare, may grow it.
+//
+// When the client has a KeyHasher, the full query text is used instead of its
+// MD5 and the whole key is condensed by the hasher into a fixed-length form.
+//
+// When the client has MaxKeyBytes, longer keys are shortened by capKey, so
+// L1, L2 and the stampede lock all see the same shortened key.
 //
 // Note: Uses unsafe.Pointer for zero-copy conversion from []byte to string.
 // This is safe because the byte slice is not modified after conversion.
 func CreateKey(params Params, mysql *MySQL) string {
+	limit := 0
+	if mysql != nil {
+		limit = mysql.maxKeyBytes
+	}
+
+	if mysql != nil && mysql.keyHasher != nil {
+		return capKey(mysql.keyHasher.HashKey(buildKey(params, mysql, false)), limit)
+	}
+
+	if key, ok := singleArgKey(params, mysql); ok {
+		return capKey(key, limit)
+	}
+
+	buf := buildKey(params, mysql, true)
+
+	// Zero-copy conversion from byte slice to string
+	// Safe because buf is not modified after this point
+	return capKey(*(*string)(unsafe.Pointer(&buf)), limit)
+}
+
+// keyHashSuffix separates the kept prefix of a shortened key from its hash.
+const keyHashSuffix = '#'
+
+// capKey shortens a key longer than limit bytes to a prefix of it followed
+// by '#' and a 128-bit xxHash of the whole key (32 hex characters), so keys
+// sharing the prefix stay distinct. The prefix is cut on a UTF-8 boundary.
+// A limit of 33 bytes or less leaves no room for a prefix, and the bare
+// 32-character hash is returned. Keys within limit,
+// and every key when limit is 0, are returned unchanged.
+func capKey(key string, limit int) string {
+	if limit <= 0 || len(key) <= limit {
+		return key
+	}
+	sum := XXHashKeyHasher{Double: true}.HashKey([]byte(key))
+	cut := limit - len(sum) - 1
+	if cut <= 0 {
+		return sum
+	}
+	for cut > 0 && !utf8.RuneStart(key[cut]) {
+		cut--
+	}
+
+	buf := make([]byte, 0, cut+1+len(sum))
+	buf = append(buf, key[:cut]...)
+	buf = append(buf, keyHashSuffix)
+	buf = append(buf, sum...)
+	return string(buf)
+}
+
+// maxStackQuery is the longest query singleArgKey hashes from a stack copy;
+// converting longer strings to []byte for md5.Sum would allocate.
+const maxStackQuery = 256
+
+// singleArgKey is the fast path of CreateKey for the dominant
+// "... WHERE id = ?" shape: one int, int64, or string argument. It sizes the
+// buffer exactly and hashes the query without a heap copy, producing the
+// same key as buildKey. ok is false for every other shape.
+func singleArgKey(params Params, mysql *MySQL) (string, bool) {
+	if len(params.Args) != 1 || (params.Exec == "" && params.Query == "") {
+		return "", false
+	}
+
+	var scratch [20]byte // Longest int64 including sign
+	var arg []byte
+	var argStr string
+	switch v := params.Args[0].(type) {
+	case int:
+		arg = strconv.AppendInt(scratch[:0], int64(v), 10)
+	case int64:
+		arg = strconv.AppendInt(scratch[:0], v, 10)
+	case string:
+		argStr = v
+	default:
+		return "", false
+	}
+
+	db := params.Database
+	var version string
+	sep := DefaultKeySeparator
+	if mysql != nil {
+		if db == "" {
+			db = mysql.dbName
+		}
+		version = mysql.cacheVersion
+		if mysql.keySeparator != 0 && mysql.keySeparator != keyEscape {
+			sep = mysql.keySeparator
+		}
+	}
+
+	var digest [32]byte
+	stmt := params.Exec
+	if stmt == "" {
+		if len(params.Query) > maxStackQuery {
+			return "", false
+		}
+		hashQuery(&digest, params.Query)
+	}
+
+	size := len(stmt) + len(arg) + len(argStr) + 1
+	if stmt == "" {
+		size += len(digest)
+	}
+	if version != "" {
+		size += len(version) + 1
+	}
+	if db != "" {
+		size += len(db) + 1
+	}
+	if params.Strict {
+		size += 2
+	}
+
+	buf := make([]byte, 0, size)
+	if version != "" {
+		start := len(buf)
+		buf = appendKeyPart(append(buf, version...), start, ':')
+		buf = append(buf, ':')
+	}
+	if db != "" {
+		start := len(buf)
+		buf = appendKeyPart(append(buf, db...), start, ':')
+		buf = append(buf, ':')
+	}
+	if stmt != "" {
+		start := len(buf)
+		buf = appendKeyPart(append(buf, stmt...), start, sep)
+	} else {
+		buf = append(buf, digest[:]...)
+	}
+	buf = append(buf, sep)
+	start := len(buf)
+	if arg != nil {
+		buf = append(buf, arg...)
+	} else {
+		buf = append(buf, argStr...)
+	}
+	buf = appendKeyPart(buf, start, sep)
+	if params.Strict {
+		buf = appendStrictMarker(buf, sep)
+	}
+
+	return *(*string)(unsafe.Pointer(&buf)), true
+}
+
+// hashQuery writes the hex MD5 of query (at most maxStackQuery bytes) to dst,
+// hashing a stack copy so the query is not copied to the heap.
+func hashQuery(dst *[32]byte, query string) {
+	var buf [maxStackQuery]byte
+	n := copy(buf[:], query)
+	sum := md5.Sum(buf[:n])
+	hex.Encode(dst[:], sum[:])
+}
+
+// buildKey assembles the raw cache key for params. With hashQuery the query
+// text is represented by its MD5; otherwise it is included verbatim.
+func buildKey(params Params, mysql *MySQL, hashQuery bool) []byte {
 	// Determine database name for the key
 	db := params.Database
 	if db == "" && mysql != nil {
 		db = mysql.dbName
 	}
 
+	var version string
+	sep := DefaultKeySeparator
+	if mysql != nil {
+		version = mysql.cacheVersion
+		if mysql.keySeparator != 0 && mysql.keySeparator != keyEscape {
+			sep = mysql.keySeparator
+		}
+	}
+
 	// Pre-calculate the required buffer size to allocate once
 	size := 0
 
+	// Account for cache version prefix and separator
+	if version != "" {
+		size += len(version) + 1
+	}
+
 	// Account for database name and separator
 	if db != "" {
 		size += len(db) + 1 // +1 for ':' separator
@@ -37,6 +238,8 @@ func CreateKey(params Params, mysql *MySQL) string {
 	// Account for query/exec portion
 	if params.Exec != "" {
 		size += len(params.Exec)
+	} else if params.Query != "" && !hashQuery {
+		size += len(params.Query)
 	} else if params.Query != "" {
 		size += 32 // MD5 produces 32-character hex string
 	} else {
@@ -45,7 +248,7 @@ func CreateKey(params Params, mysql *MySQL) string {
 
 	// Calculate size needed for all arguments
 	for _, arg := range params.Args {
-		size++ // For ':' separator before each argument
+		size++ // For the separator before each argument
 		switch v := arg.(type) {
 		case int, int64, int32, int16, int8,
 			uint, uint64, uint32, uint16, uint8:
@@ -59,8 +262,7 @@ func CreateKey(params Params, mysql *MySQL) string {
 		case []byte:
 			size += len(v)
 		case time.Time:
-			// "2006-01-02 15:04:05" format is 19 characters
-			size += 19
+			size += len(keyTimeLayout)
 		case bool:
 			// "true" or "false" maximum 5 characters
 			size += 5
@@ -69,18 +271,33 @@ func CreateKey(params Params, mysql *MySQL) string {
 			size += 64
 		}
 	}
+	if params.Strict {
+		size += 2
+	}
 
 	// Allocate buffer with exact capacity to avoid reallocations
 	buf := make([]byte, 0, size)
 
+	if version != "" {
+		start := len(buf)
+		buf = appendKeyPart(append(buf, version...), start, ':')
+		buf = append(buf, ':')
+	}
+
 	if db != "" {
-		buf = append(buf, db...)
+		start := len(buf)
+		buf = appendKeyPart(append(buf, db...), start, ':')
 		buf = append(buf, ':')
 	}
 
 	if params.Exec != "" {
 		// Use raw exec statement
-		buf = append(buf, params.Exec...)
+		start := len(buf)
+		buf = appendKeyPart(append(buf, params.Exec...), start, sep)
+	} else if params.Query != "" && !hashQuery {
+		// Full query text; the KeyHasher bounds the final length
+		start := len(buf)
+		buf = appendKeyPart(append(buf, params.Query...), start, sep)
 	} else if params.Query != "" {
 		// Hash query with MD5 for consistent key length and to avoid
 		// storing potentially large queries in cache keys
@@ -94,52 +311,99 @@ func CreateKey(params Params, mysql *MySQL) string {
 	}
 
 	for _, arg := range params.Args {
-		buf = append(buf, ':')
-		switch v := arg.(type) {
-		case int:
-			buf = strconv.AppendInt(buf, int64(v), 10)
-		case int64:
-			buf = strconv.AppendInt(buf, v, 10)
-		case int32:
-			buf = strconv.AppendInt(buf, int64(v), 10)
-		case int16:
-			buf = strconv.AppendInt(buf, int64(v), 10)
-		case int8:
-			buf = strconv.AppendInt(buf, int64(v), 10)
-		case uint:
-			buf = strconv.AppendUint(buf, uint64(v), 10)
-		case uint64:
-			buf = strconv.AppendUint(buf, v, 10)
-		case uint32:
-			buf = strconv.AppendUint(buf, uint64(v), 10)
-		case uint16:
-			buf = strconv.AppendUint(buf, uint64(v), 10)
-		case uint8:
-			buf = strconv.AppendUint(buf, uint64(v), 10)
-		case float64:
-			buf = strconv.AppendFloat(buf, v, 'f', -1, 64)
-		case float32:
-			buf = strconv.AppendFloat(buf, float64(v), 'f', -1, 32)
-		case string:
-			buf = append(buf, v...)
-		case []byte:
-			buf = append(buf, v...)
-		case time.Time:
-			// Format as MySQL datetime string
-			buf = v.AppendFormat(buf, "2006-01-02 15:04:05")
-		case bool:
-			if v {
-				buf = append(buf, "true"...)
-			} else {
-				buf = append(buf, "false"...)
-			}
-		default:
-			// Use fmt.Sprintf for any other type
-			buf = fmt.Appendf(buf, "%v", v)
+		buf = append(buf, sep)
+		start := len(buf)
+		if t, ok := arg.(time.Time); ok {
+			// The same instant must map to one key whatever its zone
+			buf = appendKeyPart(t.UTC().AppendFormat(buf, keyTimeLayout), start, sep)
+			continue
 		}
+		buf = appendKeyPart(appendArg(buf, arg), start, sep)
+	}
+	if params.Strict {
+		buf = appendStrictMarker(buf, sep)
 	}
 
-	// Zero-copy conversion from byte slice to string
-	// Safe because buf is not modified after this point
-	return *(*string)(unsafe.Pointer(&buf))
+	return buf
+}
+
+// appendKeyPart escapes the key part that was just appended at buf[start:],
+// prefixing every sep or keyEscape byte with keyEscape. The part is rewritten
+// only when it contains such a byte, so the common case costs a single scan.
+func appendKeyPart(buf []byte, start int, sep byte) []byte {
+	part := buf[start:]
+	if bytes.IndexByte(part, sep) < 0 && bytes.IndexByte(part, keyEscape) < 0 {
+		return buf
+	}
+
+	raw := append([]byte(nil), part...)
+	buf = buf[:start]
+	for _, b := range raw {
+		if b == sep || b == keyEscape {
+			buf = append(buf, keyEscape)
+		}
+		buf = append(buf, b)
+	}
+	return buf
+}
+
+// appendStrictMarker ends the key of a Params.Strict query with keyEscape
+// and a byte other than sep, so a strict QueryRow never serves a first row
+// cached by a non-strict one. In escaped parts keyEscape is only followed by
+// sep or keyEscape, so no argument list can produce the marker.
+func appendStrictMarker(buf []byte, sep byte) []byte {
+	mark := byte('!')
+	if sep == mark {
+		mark = '?'
+	}
+	return append(buf, keyEscape, mark)
+}
+
+// appendArg appends the textual form of a query argument to buf.
+// It is shared by cache key generation and DebugQuery so both render
+// arguments identically.
+func appendArg(buf []byte, arg any) []byte {
+	switch v := arg.(type) {
+	case int:
+		buf = strconv.AppendInt(buf, int64(v), 10)
+	case int64:
+		buf = strconv.AppendInt(buf, v, 10)
+	case int32:
+		buf = strconv.AppendInt(buf, int64(v), 10)
+	case int16:
+		buf = strconv.AppendInt(buf, int64(v), 10)
+	case int8:
+		buf = strconv.AppendInt(buf, int64(v), 10)
+	case uint:
+		buf = strconv.AppendUint(buf, uint64(v), 10)
+	case uint64:
+		buf = strconv.AppendUint(buf, v, 10)
+	case uint32:
+		buf = strconv.AppendUint(buf, uint64(v), 10)
+	case uint16:
+		buf = strconv.AppendUint(buf, uint64(v), 10)
+	case uint8:
+		buf = strconv.AppendUint(buf, uint64(v), 10)
+	case float64:
+		buf = strconv.AppendFloat(buf, v, 'f', -1, 64)
+	case float32:
+		buf = strconv.AppendFloat(buf, float64(v), 'f', -1, 32)
+	case string:
+		buf = append(buf, v...)
+	case []byte:
+		buf = append(buf, v...)
+	case time.Time:
+		// Format as MySQL datetime string
+		buf = v.AppendFormat(buf, "2006-01-02 15:04:05")
+	case bool:
+		if v {
+			buf = append(buf, "true"...)
+		} else {
+			buf = append(buf, "false"...)
+		}
+	default:
+		// Use fmt.Sprintf for any other type
+		buf = fmt.Appendf(buf, "%v", v)
+	}
+	return buf
 }
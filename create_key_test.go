@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 )
 
 func TestCreateKeyWithMySQL(t *testing.T) {
@@ -24,7 +25,7 @@ func TestCreateKeyWithMySQL(t *testing.T) {
 				Exec: "product_get",
 				Args: []any{746457348, 20, 350},
 			},
-			expect: "shop:product_get:746457348:20:350",
+			expect: "shop:product_get\x1f746457348\x1f20\x1f350",
 		},
 		{
 			name:  "exec_with_args_and_db_from_params",
@@ -34,7 +35,7 @@ func TestCreateKeyWithMySQL(t *testing.T) {
 				Exec:     "product_get",
 				Args:     []any{1},
 			},
-			expect: "catalog:product_get:1",
+			expect: "catalog:product_get\x1f1",
 		},
 		{
 			name:  "query_hash_used_when_exec_empty",
@@ -43,7 +44,7 @@ func TestCreateKeyWithMySQL(t *testing.T) {
 				Query: "SELECT * FROM users WHERE id = ?",
 				Args:  []any{42},
 			},
-			expect: "shop:f15e5e09c27c92be6ed2b586d171d68a:42",
+			expect: "shop:f15e5e09c27c92be6ed2b586d171d68a\x1f42",
 		},
 		{
 			name:  "no_database_anywhere",
@@ -64,7 +65,7 @@ func TestCreateKeyWithMySQL(t *testing.T) {
 					time.Date(2024, 11, 17, 10, 0, 0, 0, time.UTC),
 				},
 			},
-			expect: "shop:user_create:John:2024-11-17 10:00:00",
+			expect: "shop:user_create\x1fJohn\x1f2024-11-17 10:00:00.000000000",
 		},
 		{
 			name:  "large_string_arg",
@@ -73,7 +74,7 @@ func TestCreateKeyWithMySQL(t *testing.T) {
 				Exec: "blob_set",
 				Args: []any{strings.Repeat("A", 1024)},
 			},
-			expect: "shop:blob_set:" + strings.Repeat("A", 1024),
+			expect: "shop:blob_set\x1f" + strings.Repeat("A", 1024),
 		},
 	}
 
@@ -159,7 +160,7 @@ func TestCreateKey_ArgTypes(t *testing.T) {
 	}
 
 	key := CreateKey(params, mysql)
-	expected := "db:unknown:-5:7:1.25:2.5:bin:true:false:{1}"
+	expected := "db:unknown\x1f-5\x1f7\x1f1.25\x1f2.5\x1fbin\x1ftrue\x1ffalse\x1f{1}"
 	if key != expected {
 		t.Fatalf("unexpected key\nexpected: %q\ngot:      %q", expected, key)
 	}
@@ -181,8 +182,226 @@ func TestCreateKey_IntegerVariants(t *testing.T) {
 	}
 
 	key := CreateKey(params, mysql)
-	expected := "db:proc:-1:2:3:4:5:6:7"
+	expected := "db:proc\x1f-1\x1f2\x1f3\x1f4\x1f5\x1f6\x1f7"
 	if key != expected {
 		t.Fatalf("unexpected key\nexpected: %q\ngot:      %q", expected, key)
 	}
 }
+
+func TestCreateKeyWithCacheVersion(t *testing.T) {
+	params := Params{Exec: "product_get", Args: []any{1}}
+
+	v1 := CreateKey(params, &MySQL{dbName: "shop", cacheVersion: "v1"})
+	v2 := CreateKey(params, &MySQL{dbName: "shop", cacheVersion: "v2"})
+	plain := CreateKey(params, &MySQL{dbName: "shop"})
+
+	if v1 != "v1:shop:product_get\x1f1" {
+		t.Fatalf("unexpected versioned key: %q", v1)
+	}
+	if plain != "shop:product_get\x1f1" {
+		t.Fatalf("unexpected unversioned key: %q", plain)
+	}
+	if v1 == v2 {
+		t.Fatalf("expected keys to change with the cache version")
+	}
+}
+
+func TestCreateKey_NoCollisions(t *testing.T) {
+	mysql := &MySQL{dbName: "db"}
+	cases := [][2]Params{
+		// Separator-like text inside the procedure name or an argument
+		{{Exec: "proc", Args: []any{"a:b"}}, {Exec: "proc:a", Args: []any{"b"}}},
+		{{Exec: "proc", Args: []any{"a\x1fb"}}, {Exec: "proc", Args: []any{"a", "b"}}},
+		{{Exec: "proc\x1fa", Args: []any{"b"}}, {Exec: "proc", Args: []any{"a", "b"}}},
+		// Escape bytes cannot forge a separator either
+		{{Exec: "proc", Args: []any{"a\\", "b"}}, {Exec: "proc", Args: []any{"a\\\x1fb"}}},
+		{{Exec: "proc", Args: []any{"12"}}, {Exec: "proc", Args: []any{"1", "2"}}},
+		{{Exec: "proc", Args: []any{""}}, {Exec: "proc"}},
+		// Strict queries never share a key with non-strict ones
+		{{Exec: "proc", Args: []any{"a"}}, {Exec: "proc", Args: []any{"a"}, Strict: true}},
+		{{Exec: "proc", Args: []any{"a\\!"}}, {Exec: "proc", Args: []any{"a"}, Strict: true}},
+		{{Exec: "proc", Args: []any{"a\\"}}, {Exec: "proc", Args: []any{"a"}, Strict: true}},
+	}
+	for _, c := range cases {
+		a, b := CreateKey(c[0], mysql), CreateKey(c[1], mysql)
+		if a == b {
+			t.Fatalf("keys collide for %+v and %+v: %q", c[0].Args, c[1].Args, a)
+		}
+	}
+}
+
+func TestCreateKey_EscapesVersionAndDatabase(t *testing.T) {
+	params := Params{Exec: "proc", Args: []any{1}}
+	a := CreateKey(params, &MySQL{dbName: "b:c", cacheVersion: "a"})
+	b := CreateKey(params, &MySQL{dbName: "c", cacheVersion: "a:b"})
+	if a == b {
+		t.Fatalf("keys collide: %q", a)
+	}
+	if a != "a:b\\:c:proc\x1f1" {
+		t.Fatalf("unexpected key %q", a)
+	}
+}
+
+func TestCreateKey_CustomSeparator(t *testing.T) {
+	mysql := &MySQL{dbName: "db", keySeparator: '|'}
+	key := CreateKey(Params{Exec: "proc", Args: []any{"a|b", 2}}, mysql)
+	if key != "db:proc|a\\|b|2" {
+		t.Fatalf("unexpected key %q", key)
+	}
+}
+
+func TestCreateKey_TimeIsZoneIndependent(t *testing.T) {
+	mysql := &MySQL{dbName: "db"}
+	instant := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	shifted := instant.In(time.FixedZone("UTC+5", 5*60*60))
+
+	a := CreateKey(Params{Exec: "proc", Args: []any{instant}}, mysql)
+	b := CreateKey(Params{Exec: "proc", Args: []any{shifted}}, mysql)
+	if a != b {
+		t.Fatalf("expected equal keys for the same instant, got %q and %q", a, b)
+	}
+}
+
+func TestCreateKey_TimeKeepsSubSecondPrecision(t *testing.T) {
+	mysql := &MySQL{dbName: "db"}
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	a := CreateKey(Params{Exec: "proc", Args: []any{base}}, mysql)
+	b := CreateKey(Params{Exec: "proc", Args: []any{base.Add(time.Millisecond)}}, mysql)
+	if a == b {
+		t.Fatalf("expected distinct keys for times a millisecond apart, got %q", a)
+	}
+	if b != "db:proc\x1f2024-03-01 12:00:00.001000000" {
+		t.Fatalf("unexpected key %q", b)
+	}
+
+	// Digit separators inside the formatted time are still escaped
+	digit := &MySQL{dbName: "db", keySeparator: '7'}
+	later := time.Date(2027, 3, 1, 12, 0, 0, 0, time.UTC)
+	if key := CreateKey(Params{Exec: "proc", Args: []any{later}}, digit); !strings.HasSuffix(key, "7202\\7-03-01 12:00:00.000000000") {
+		t.Fatalf("expected the separator digit to be escaped, got %q", key)
+	}
+}
+
+func TestCreateKey_SingleArgMatchesGeneralPath(t *testing.T) {
+	clients := []*MySQL{
+		nil,
+		{dbName: "shop"},
+		{dbName: "shop", cacheVersion: "v2"},
+		{dbName: "shop", keySeparator: '|'},
+		{dbName: "shop", keySeparator: '7'},
+		{dbName: "shop", keySeparator: '!'},
+		{dbName: "sh:op\\", cacheVersion: "v:2"},
+	}
+	longQuery := "SELECT * FROM users WHERE id = ?" + strings.Repeat(" ", maxStackQuery)
+	paramsList := []Params{
+		{Query: "SELECT * FROM users WHERE id = ?", Args: []any{42}},
+		{Query: "SELECT * FROM users WHERE id = ?", Args: []any{int64(-9223372036854775808)}},
+		{Query: "SELECT * FROM users WHERE id = ?", Args: []any{7}},
+		{Query: "SELECT * FROM users WHERE name = ?", Args: []any{"a|b\\c\x1f"}},
+		{Query: "SELECT * FROM users WHERE name = ?", Args: []any{""}},
+		{Query: longQuery, Args: []any{1}},
+		{Exec: "user_get", Args: []any{42}},
+		{Exec: "user|get", Args: []any{"x"}},
+		{Query: "SELECT 1", Database: "other", Args: []any{1}},
+		{Query: "SELECT * FROM users WHERE id = ?", Args: []any{42}, Strict: true},
+		{Exec: "user!get", Args: []any{"x!"}, Strict: true},
+	}
+
+	for _, mysql := range clients {
+		for _, params := range paramsList {
+			want := string(buildKey(params, mysql, true))
+			if got := CreateKey(params, mysql); got != want {
+				t.Fatalf("key mismatch for %+v: got %q, want %q", params, got, want)
+			}
+		}
+	}
+
+	// Only the single int, int64, or string shapes take the fast path
+	for _, params := range []Params{
+		{Query: "SELECT 1"},
+		{Query: "SELECT 1", Args: []any{1, 2}},
+		{Query: "SELECT 1", Args: []any{int32(1)}},
+		{Query: longQuery, Args: []any{1}},
+		{Args: []any{1}},
+	} {
+		if _, ok := singleArgKey(params, nil); ok {
+			t.Fatalf("expected general path for %+v", params)
+		}
+	}
+}
+
+func BenchmarkCreateKey_SingleInt(b *testing.B) {
+	mysql := &MySQL{dbName: "shop"}
+	params := Params{Query: "SELECT id, name, email FROM users WHERE id = ?", Args: []any{746457348}}
+
+	b.Run("fast", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = CreateKey(params, mysql)
+		}
+	})
+	b.Run("general", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = buildKey(params, mysql, true)
+		}
+	})
+}
+
+func BenchmarkCreateKey_SingleString(b *testing.B) {
+	mysql := &MySQL{dbName: "shop"}
+	params := Params{Exec: "user_get_by_email", Args: []any{"alice@example.com"}}
+
+	b.Run("fast", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = CreateKey(params, mysql)
+		}
+	})
+	b.Run("general", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = buildKey(params, mysql, true)
+		}
+	})
+}
+
+func TestCreateKey_MaxKeyBytes(t *testing.T) {
+	mysql := &MySQL{dbName: "db", maxKeyBytes: 64}
+	long := strings.Repeat("x", 100)
+	raw := CreateKey(Params{Exec: "proc", Args: []any{long}}, &MySQL{dbName: "db"})
+
+	key := CreateKey(Params{Exec: "proc", Args: []any{long}}, mysql)
+	if len(key) != 64 || key[:31] != raw[:31] || key[31] != '#' {
+		t.Fatalf("expected a 64-byte key keeping a readable prefix, got %q", key)
+	}
+	if again := CreateKey(Params{Exec: "proc", Args: []any{long}}, mysql); again != key {
+		t.Fatalf("expected a deterministic key, got %q and %q", key, again)
+	}
+	// Only the hash tells keys sharing the prefix apart
+	if other := CreateKey(Params{Exec: "proc", Args: []any{long + "y"}}, mysql); other == key || other[:32] != key[:32] {
+		t.Fatalf("expected a distinct key with the same prefix, got %q and %q", key, other)
+	}
+
+	// Short keys and the single-argument fast path are unaffected below the limit
+	if short := CreateKey(Params{Exec: "proc", Args: []any{1}}, mysql); short != "db:proc\x1f1" {
+		t.Fatalf("unexpected short key %q", short)
+	}
+}
+
+func TestCapKey_Boundaries(t *testing.T) {
+	// The prefix is never cut inside a multi-byte character: 7 bytes of
+	// room keep three two-byte characters
+	key := capKey(strings.Repeat("é", 40), 40)
+	if len(key) != 39 || !strings.HasPrefix(key, "ééé#") || !utf8.ValidString(key) {
+		t.Fatalf("unexpected key %q", key)
+	}
+	// No room for a prefix: the bare hash
+	if key := capKey(strings.Repeat("x", 40), 33); len(key) != 32 || strings.ContainsRune(key, '#') {
+		t.Fatalf("expected a bare 32-character hash, got %q", key)
+	}
+	if key := capKey("abc", 0); key != "abc" {
+		t.Fatalf("expected no change without a limit, got %q", key)
+	}
+}
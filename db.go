@@ -13,6 +13,15 @@ type DB interface {
 	// Returns a Stmt interface for executing the prepared statement.
 	PrepareContext(ctx context.Context, query string) (Stmt, error)
 
+	// QueryContext executes query with args without preparing it first.
+	// Used instead of PrepareContext when prepared statements are disabled;
+	// the driver is then expected to interpolate args client-side.
+	QueryContext(ctx context.Context, query string, args ...any) (Rows, error)
+
+	// ExecContext executes a statement that returns no rows (INSERT, UPDATE,
+	// DELETE, DDL) without preparing it first.
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+
 	// Close closes the database and releases any open resources.
 	// After Close is called, the database cannot be used for further operations.
 	Close() error
@@ -25,11 +34,56 @@ type Stmt interface {
 	// Returns rows from the query result. The context controls execution timeout/cancellation.
 	QueryContext(ctx context.Context, args ...any) (Rows, error)
 
+	// QueryRowContext executes a prepared query expected to return at most
+	// one row. Errors, including sql.ErrNoRows for an empty result, are
+	// deferred until Row.Scan, which also releases the result.
+	QueryRowContext(ctx context.Context, args ...any) Row
+
+	// ExecContext executes a prepared statement that does not return rows
+	// (INSERT, UPDATE, DELETE) with the given arguments.
+	ExecContext(ctx context.Context, args ...any) (sql.Result, error)
+
 	// Close closes the statement and releases associated database resources.
 	// Statements should be closed when no longer needed to free database resources.
 	Close() error
 }
 
+// Row is the result of Stmt.QueryRowContext. *sql.Row satisfies it.
+type Row interface {
+	// Scan copies the columns of the row into dest, or returns the error of
+	// the query (sql.ErrNoRows when it returned no rows).
+	Scan(dest ...any) error
+}
+
+// rowsRow implements Row on top of Rows, for statements without a native
+// single-row path. Like *sql.Row, it reads only the first row.
+type rowsRow struct {
+	rows Rows
+	err  error
+}
+
+// rowFromRows wraps the results of a QueryContext call as a Row.
+func rowFromRows(rows Rows, err error) Row {
+	return &rowsRow{rows: rows, err: err}
+}
+
+// Scan implements Row, closing the rows. As with *sql.Row, an iteration
+// error reported by the rows' Err method (as *sql.Rows has) takes
+// precedence over sql.ErrNoRows.
+func (r *rowsRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rows.Close()
+	if !r.rows.Next() {
+		if e, ok := r.rows.(interface{ Err() error }); ok && e.Err() != nil {
+			return e.Err()
+		}
+		return sql.ErrNoRows
+	}
+	return r.rows.Scan(dest...)
+}
+
 // sqlDB is a concrete implementation of the DB interface wrapping *sql.DB.
 // This adapter pattern allows using the standard sql.DB while maintaining
 // a clean interface for the rest of the application.
@@ -47,6 +101,16 @@ func (s *sqlDB) PrepareContext(ctx context.Context, query string) (Stmt, error)
 	return &sqlStmt{stmt: stmt}, nil
 }
 
+// QueryContext implements the DB interface by delegating to the underlying *sql.DB.
+func (s *sqlDB) QueryContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	return s.db.QueryContext(ctx, query, args...)
+}
+
+// ExecContext implements the DB interface by delegating to the underlying *sql.DB.
+func (s *sqlDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return s.db.ExecContext(ctx, query, args...)
+}
+
 // Close implements the DB interface by closing the underlying database connection.
 // This closes all open connections and stops new ones from being created.
 func (s *sqlDB) Close() error {
@@ -65,6 +129,16 @@ func (s *sqlStmt) QueryContext(ctx context.Context, args ...any) (Rows, error) {
 	return s.stmt.QueryContext(ctx, args...)
 }
 
+// QueryRowContext implements the Stmt interface by delegating to the underlying *sql.Stmt.
+func (s *sqlStmt) QueryRowContext(ctx context.Context, args ...any) Row {
+	return s.stmt.QueryRowContext(ctx, args...)
+}
+
+// ExecContext implements the Stmt interface by delegating to the underlying *sql.Stmt.
+func (s *sqlStmt) ExecContext(ctx context.Context, args ...any) (sql.Result, error) {
+	return s.stmt.ExecContext(ctx, args...)
+}
+
 // Close implements the Stmt interface by closing the underlying prepared statement.
 // Releases server and client resources associated with the prepared statement.
 func (s *sqlStmt) Close() error {
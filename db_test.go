@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"testing"
 )
@@ -55,3 +56,44 @@ func TestSQLDB_PrepareContextError(t *testing.T) {
 		t.Fatalf("expected prepare error")
 	}
 }
+
+func TestSQLDB_UnpreparedQueryAndExec(t *testing.T) {
+	db := newTestSQLDB(nil)
+	defer db.Close()
+
+	wrapper := &sqlDB{db: db}
+	rows, err := wrapper.QueryContext(context.Background(), "SELECT 1")
+	if err != nil {
+		t.Fatalf("QueryContext failed: %v", err)
+	}
+	defer rows.Close()
+
+	var value string
+	if !rows.Next() || rows.Scan(&value) != nil || value != "ok" {
+		t.Fatalf("expected one row with value ok, got %q", value)
+	}
+
+	// The test driver rejects writes; the error must come back unchanged
+	if _, err := wrapper.ExecContext(context.Background(), "DELETE FROM t"); err == nil || err.Error() != "not supported" {
+		t.Fatalf("expected driver error, got %v", err)
+	}
+}
+
+// errRows is an empty result whose iteration failed, as reported by Err.
+type errRows struct {
+	MockRows
+	err error
+}
+
+func (r *errRows) Err() error { return r.err }
+
+func TestRowFromRows_IterationError(t *testing.T) {
+	boom := errors.New("connection reset")
+	var v int
+	if err := rowFromRows(&errRows{err: boom}, nil).Scan(&v); !errors.Is(err, boom) {
+		t.Fatalf("expected the iteration error, got %v", err)
+	}
+	if err := rowFromRows(&errRows{}, nil).Scan(&v); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package mysql
+
+import (
+	"encoding/hex"
+	"time"
+)
+
+// DebugQuery returns the SQL that Query would prepare for params with every
+// '?' placeholder replaced by the corresponding argument rendered as a SQL
+// literal. Strings and times are single-quoted and escaped, []byte values are
+// rendered as hex literals (X'..'), and nil as NULL. Placeholders are found as
+// ExpandIN and StrictArgs find them: a '?' inside quoted literals, identifiers
+// or comments is left untouched. Surplus placeholders stay '?'.
+//
+// The result is intended for logging and debugging (e.g. pasting into EXPLAIN)
+// only. It is NOT safe for execution: always run queries with bound arguments.
+func (c *MySQL) DebugQuery(params Params) string {
+	query := generateQuery(c.withDefaultDatabase(params))
+	buf := make([]byte, 0, len(query)+len(params.Args)*8)
+
+	next, last := 0, 0
+	forEachPlaceholder(query, func(pos int) {
+		if next >= len(params.Args) {
+			return
+		}
+		buf = append(buf, query[last:pos]...)
+		buf = appendLiteral(buf, params.Args[next])
+		next++
+		last = pos + 1
+	})
+	return string(append(buf, query[last:]...))
+}
+
+// appendLiteral appends arg to buf as a SQL literal for display purposes.
+func appendLiteral(buf []byte, arg any) []byte {
+	switch v := arg.(type) {
+	case nil:
+		return append(buf, "NULL"...)
+	case string:
+		return appendQuoted(buf, v)
+	case []byte:
+		buf = append(buf, "X'"...)
+		n := len(buf)
+		buf = append(buf, make([]byte, hex.EncodedLen(len(v)))...)
+		hex.Encode(buf[n:], v)
+		return append(buf, '\'')
+	case time.Time:
+		buf = append(buf, '\'')
+		buf = appendArg(buf, v)
+		return append(buf, '\'')
+	case bool, int, int64, int32, int16, int8,
+		uint, uint64, uint32, uint16, uint8, float64, float32:
+		return appendArg(buf, v)
+	default:
+		// Unknown types are rendered via fmt and quoted as strings
+		return appendQuoted(buf, string(appendArg(nil, v)))
+	}
+}
+
+// appendQuoted appends s as a single-quoted MySQL string literal,
+// escaping characters the way mysql_real_escape_string does.
+func appendQuoted(buf []byte, s string) []byte {
+	buf = append(buf, '\'')
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; ch {
+		case 0:
+			buf = append(buf, '\\', '0')
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\x1a':
+			buf = append(buf, '\\', 'Z')
+		case '\'', '"', '\\':
+			buf = append(buf, '\\', ch)
+		default:
+			buf = append(buf, ch)
+		}
+	}
+	return append(buf, '\'')
+}
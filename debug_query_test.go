@@ -0,0 +1,71 @@
+package mysql
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDebugQuery(t *testing.T) {
+	c := &MySQL{dbName: "shop"}
+	ts := time.Date(2024, 3, 5, 10, 20, 30, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		params Params
+		expect string
+	}{
+		{
+			name:   "mixed_args",
+			params: Params{Query: "SELECT * FROM t WHERE id = ? AND name = ? AND ok = ? AND score > ?", Args: []any{42, "bob", true, 1.5}},
+			expect: "SELECT * FROM t WHERE id = 42 AND name = 'bob' AND ok = true AND score > 1.5",
+		},
+		{
+			name:   "string_escaping",
+			params: Params{Query: "SELECT ?", Args: []any{"it's \"x\"\\\n\r\x00\x1a"}},
+			expect: `SELECT 'it\'s \"x\"\\\n\r\0\Z'`,
+		},
+		{
+			name:   "bytes_as_hex",
+			params: Params{Query: "SELECT ?", Args: []any{[]byte{0xde, 0xad, 0x01}}},
+			expect: "SELECT X'dead01'",
+		},
+		{
+			name:   "nil_and_time",
+			params: Params{Query: "UPDATE t SET a = ?, b = ?", Args: []any{nil, ts}},
+			expect: "UPDATE t SET a = NULL, b = '2024-03-05 10:20:30'",
+		},
+		{
+			name:   "placeholders_in_comments_ignored",
+			params: Params{Query: "SELECT a -- it's ?\nFROM t # why?\nWHERE /* ? */ x = ?", Args: []any{7}},
+			expect: "SELECT a -- it's ?\nFROM t # why?\nWHERE /* ? */ x = 7",
+		},
+		{
+			name:   "placeholders_in_literals_ignored",
+			params: Params{Query: "SELECT '?', `a?`, \"b\\\"?\" FROM t WHERE x = ?", Args: []any{7}},
+			expect: "SELECT '?', `a?`, \"b\\\"?\" FROM t WHERE x = 7",
+		},
+		{
+			name:   "missing_args_left_as_placeholders",
+			params: Params{Query: "SELECT ?, ?", Args: []any{uint8(1)}},
+			expect: "SELECT 1, ?",
+		},
+		{
+			name:   "procedure_call",
+			params: Params{Database: "shop", Exec: "product_get", Args: []any{int64(5), "x"}},
+			expect: "CALL shop.product_get(5, 'x')",
+		},
+		{
+			name:   "fallback_type_quoted",
+			params: Params{Query: "SELECT ?", Args: []any{struct{ A int }{1}}},
+			expect: "SELECT '{1}'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.DebugQuery(tt.params); got != tt.expect {
+				t.Fatalf("DebugQuery() = %q, want %q", got, tt.expect)
+			}
+		})
+	}
+}
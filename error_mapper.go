@@ -0,0 +1,161 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// ErrorCategory is a normalized classification of database errors.
+// It lets callers react to classes of failures (retry on deadlock,
+// report a conflict on constraint violations, ...) without matching
+// individual MySQL error numbers.
+type ErrorCategory uint8
+
+const (
+	CategoryUnknown        ErrorCategory = iota // Error could not be classified
+	CategoryDeadlock                            // Transaction deadlock; safe to retry
+	CategoryLockTimeout                         // Lock wait timeout exceeded
+	CategoryTimeout                             // Query or context deadline exceeded
+	CategoryConstraint                          // Integrity constraint violation (duplicate key, foreign key, ...)
+	CategorySyntax                              // SQL syntax or parse error
+	CategoryConnectionLost                      // Connection to the server was lost or refused
+	CategoryCanceled                            // Caller canceled the request context
+)
+
+// String returns a short, stable name for the category suitable for logs and metrics.
+func (c ErrorCategory) String() string {
+	switch c {
+	case CategoryDeadlock:
+		return "deadlock"
+	case CategoryLockTimeout:
+		return "lock_timeout"
+	case CategoryTimeout:
+		return "timeout"
+	case CategoryConstraint:
+		return "constraint"
+	case CategorySyntax:
+		return "syntax"
+	case CategoryConnectionLost:
+		return "connection_lost"
+	case CategoryCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// DefaultErrorCategories maps MySQL error numbers to categories.
+// Numbers not listed here are classified by their SQLState class
+// (see Categorize).
+var DefaultErrorCategories = map[uint16]ErrorCategory{
+	1213: CategoryDeadlock,       // ER_LOCK_DEADLOCK
+	1205: CategoryLockTimeout,    // ER_LOCK_WAIT_TIMEOUT
+	3024: CategoryTimeout,        // ER_QUERY_TIMEOUT (max_execution_time exceeded)
+	1062: CategoryConstraint,     // ER_DUP_ENTRY
+	1451: CategoryConstraint,     // ER_ROW_IS_REFERENCED_2
+	1452: CategoryConstraint,     // ER_NO_REFERENCED_ROW_2
+	1048: CategoryConstraint,     // ER_BAD_NULL_ERROR
+	3819: CategoryConstraint,     // ER_CHECK_CONSTRAINT_VIOLATED
+	1064: CategorySyntax,         // ER_PARSE_ERROR
+	1149: CategorySyntax,         // ER_SYNTAX_ERROR
+	1053: CategoryConnectionLost, // ER_SERVER_SHUTDOWN
+	2002: CategoryConnectionLost, // CR_CONNECTION_ERROR
+	2003: CategoryConnectionLost, // CR_CONN_HOST_ERROR
+	2006: CategoryConnectionLost, // CR_SERVER_GONE_ERROR
+	2013: CategoryConnectionLost, // CR_SERVER_LOST
+}
+
+// Categorize classifies a MySQL error by number, falling back to the
+// SQLState when the number is not in DefaultErrorCategories. Of class 40
+// (transaction rollback) only 40001, serialization failure, is a deadlock;
+// the other rollback states are not retryable the same way.
+func Categorize(number uint16, sqlState [5]byte) ErrorCategory {
+	if cat, ok := DefaultErrorCategories[number]; ok {
+		return cat
+	}
+
+	if string(sqlState[:]) == "40001" {
+		return CategoryDeadlock
+	}
+
+	// SQLState classes are defined by the first two characters.
+	switch string(sqlState[:2]) {
+	case "23":
+		return CategoryConstraint
+	case "08":
+		return CategoryConnectionLost
+	}
+	return CategoryUnknown
+}
+
+// ErrorMapper converts errors returned by the driver into *MySQLError values.
+// A custom implementation can be supplied via Options.ErrorMapper to change
+// how Query reports failures.
+type ErrorMapper interface {
+	// MapError converts err into a *MySQLError. err is never nil.
+	MapError(err error) *MySQLError
+}
+
+// ErrorMapperFunc adapts an ordinary function to the ErrorMapper interface.
+type ErrorMapperFunc func(err error) *MySQLError
+
+// MapError calls f(err).
+func (f ErrorMapperFunc) MapError(err error) *MySQLError {
+	return f(err)
+}
+
+// DefaultErrorMapper is the ErrorMapper used when Options.ErrorMapper is nil.
+// Deadlocks, timeouts, and cancellations are reported as the ErrDeadlock,
+// ErrTimeout, and ErrCanceled sentinels; other driver errors keep their
+// number and SQLState.
+// Every returned error carries its Category and wraps err, so errors.As can
+// recover the driver's *mysql.MySQLError.
+type DefaultErrorMapper struct{}
+
+// MapError implements ErrorMapper.
+func (DefaultErrorMapper) MapError(err error) *MySQLError {
+	// Context errors are checked before driver errors: the driver may wrap
+	// them, and they say more about why the query stopped
+	if errors.Is(err, context.DeadlineExceeded) {
+		// Query exceeded timeout
+		return ErrTimeout.WithCause(err)
+	}
+	if errors.Is(err, context.Canceled) {
+		// Caller gave up on the request
+		return ErrCanceled.WithCause(err)
+	}
+
+	var sqlErr *mysql.MySQLError
+	if errors.As(err, &sqlErr) {
+		cat := Categorize(sqlErr.Number, sqlErr.SQLState)
+		if cat == CategoryDeadlock {
+			// Deadlocks are normalized so callers can retry uniformly
+			return ErrDeadlock.WithCause(err)
+		}
+		return &MySQLError{
+			Number:   sqlErr.Number,
+			SQLState: sqlErr.SQLState,
+			Message:  sqlErr.Message,
+			Category: cat,
+			wrapped:  err,
+		}
+	}
+
+	if errors.Is(err, mysql.ErrInvalidConn) || errors.Is(err, driver.ErrBadConn) {
+		return &MySQLError{Category: CategoryConnectionLost, wrapped: err}
+	}
+
+	// Generic error (network, driver, etc.), keeping any SQL state it reports
+	return &MySQLError{SQLState: sqlStateOf(err), wrapped: err}
+}
+
+// mapError converts err using the configured ErrorMapper.
+func (c *MySQL) mapError(err error) *MySQLError {
+	if c.errorMapper != nil {
+		return c.errorMapper.MapError(err)
+	}
+	return DefaultErrorMapper{}.MapError(err)
+}
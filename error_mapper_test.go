@@ -0,0 +1,161 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+func TestDefaultErrorMapper_Categories(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		number  uint16
+		message string
+		cat     ErrorCategory
+	}{
+		{"deadlock", &mysqldriver.MySQLError{Number: 1213, SQLState: [5]byte{'4', '0', '0', '0', '1'}}, 45000, "DEADLOCK", CategoryDeadlock},
+		{"lock_timeout", &mysqldriver.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"}, 1205, "Lock wait timeout exceeded", CategoryLockTimeout},
+		{"query_timeout", &mysqldriver.MySQLError{Number: 3024, Message: "maximum statement execution time exceeded"}, 3024, "maximum statement execution time exceeded", CategoryTimeout},
+		{"context_deadline", context.DeadlineExceeded, 45000, "TIMEOUT", CategoryTimeout},
+		{"wrapped_deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), 45000, "TIMEOUT", CategoryTimeout},
+		{"context_canceled", context.Canceled, 45000, "CANCELED", CategoryCanceled},
+		{"duplicate_key", &mysqldriver.MySQLError{Number: 1062, Message: "Duplicate entry"}, 1062, "Duplicate entry", CategoryConstraint},
+		{"foreign_key", &mysqldriver.MySQLError{Number: 1452, Message: "Cannot add or update a child row"}, 1452, "Cannot add or update a child row", CategoryConstraint},
+		{"deadlock_by_sqlstate", &mysqldriver.MySQLError{Number: 4001, SQLState: [5]byte{'4', '0', '0', '0', '1'}}, 45000, "DEADLOCK", CategoryDeadlock},
+		{"other_rollback_state", &mysqldriver.MySQLError{Number: 4002, SQLState: [5]byte{'4', '0', '0', '0', '2'}}, 4002, "", CategoryUnknown},
+		{"constraint_by_sqlstate", &mysqldriver.MySQLError{Number: 4025, SQLState: [5]byte{'2', '3', '0', '0', '0'}}, 4025, "", CategoryConstraint},
+		{"syntax", &mysqldriver.MySQLError{Number: 1064, Message: "syntax"}, 1064, "syntax", CategorySyntax},
+		{"server_gone", &mysqldriver.MySQLError{Number: 2006}, 2006, "", CategoryConnectionLost},
+		{"invalid_conn", mysqldriver.ErrInvalidConn, 0, "", CategoryConnectionLost},
+		{"bad_conn", driver.ErrBadConn, 0, "", CategoryConnectionLost},
+		{"unknown_mysql", &mysqldriver.MySQLError{Number: 1146, Message: "Table doesn't exist"}, 1146, "Table doesn't exist", CategoryUnknown},
+		{"no_rows_is_generic", sql.ErrNoRows, 0, "", CategoryUnknown}, // Mapped to ErrNoRows only from callbacks and Row.Scan
+		{"generic", errors.New("boom"), 0, "", CategoryUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DefaultErrorMapper{}.MapError(tt.err)
+			if got.Number != tt.number || got.Message != tt.message || got.Category != tt.cat {
+				t.Fatalf("unexpected mapping: %+v (category %s)", got, got.Category)
+			}
+		})
+	}
+}
+
+func TestDefaultErrorMapper_PreservesSQLState(t *testing.T) {
+	state := [5]byte{'4', '2', '0', '0', '0'}
+	got := DefaultErrorMapper{}.MapError(&mysqldriver.MySQLError{Number: 1064, SQLState: state})
+	if got.SQLState != state {
+		t.Fatalf("expected SQLState to be preserved, got %q", got.SQLState)
+	}
+
+	// Generic errors keep a state they report through SQLState()
+	generic := DefaultErrorMapper{}.MapError(stateError{state: "08S01"})
+	if generic.SQLState != [5]byte{'0', '8', 'S', '0', '1'} {
+		t.Fatalf("expected generic SQLState to be preserved, got %q", generic.SQLState)
+	}
+}
+
+func TestErrorCategory_String(t *testing.T) {
+	names := map[ErrorCategory]string{
+		CategoryUnknown:        "unknown",
+		CategoryDeadlock:       "deadlock",
+		CategoryLockTimeout:    "lock_timeout",
+		CategoryTimeout:        "timeout",
+		CategoryConstraint:     "constraint",
+		CategorySyntax:         "syntax",
+		CategoryConnectionLost: "connection_lost",
+		CategoryCanceled:       "canceled",
+	}
+	for cat, name := range names {
+		if cat.String() != name {
+			t.Fatalf("expected %q, got %q", name, cat.String())
+		}
+	}
+}
+
+func TestQuery_CustomErrorMapper(t *testing.T) {
+	stmt := &MockStmt{Err: &mysqldriver.MySQLError{Number: 1062, Message: "Duplicate entry"}}
+	db := NewMockDB()
+	db.WithStmt("SELECT * FROM table", stmt)
+
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+	client.errorMapper = ErrorMapperFunc(func(err error) *MySQLError {
+		mapped := DefaultErrorMapper{}.MapError(err)
+		if mapped.Category == CategoryConstraint {
+			return &MySQLError{Number: 45000, Message: "CONFLICT", Category: mapped.Category}
+		}
+		return mapped
+	})
+
+	_, err := Query(client, Params{Query: "SELECT * FROM table"}, func(rows Rows) (*[]int, *MySQLError) {
+		t.Fatal("callback should not be invoked on query error")
+		return nil, nil
+	})
+	if err == nil || err.Message != "CONFLICT" || err.Category != CategoryConstraint {
+		t.Fatalf("expected custom mapping, got %+v", err)
+	}
+}
+
+func TestDefaultOptions_ErrorMapper(t *testing.T) {
+	mapper := ErrorMapperFunc(func(err error) *MySQLError { return NewError(err) })
+	opts := defaultOptions(Options{ErrorMapper: mapper})
+	if opts.ErrorMapper == nil {
+		t.Fatalf("expected ErrorMapper to be preserved")
+	}
+}
+
+func TestQuery_ErrorUnwrapsToDriverError(t *testing.T) {
+	driverErr := &mysqldriver.MySQLError{Number: 1062, SQLState: [5]byte{'2', '3', '0', '0', '0'}, Message: "Duplicate entry"}
+	db := NewMockDB()
+	db.WithStmt("SELECT * FROM table", &MockStmt{Err: driverErr})
+
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	_, merr := Query(client, Params{Query: "SELECT * FROM table"}, func(rows Rows) (*[]int, *MySQLError) {
+		t.Fatal("callback should not be invoked on query error")
+		return nil, nil
+	})
+
+	var got *mysqldriver.MySQLError
+	if !errors.As(merr, &got) || got != driverErr {
+		t.Fatalf("expected errors.As to recover the driver error, got %v", got)
+	}
+	if errors.Unwrap(merr) != driverErr {
+		t.Fatalf("expected Unwrap to return the driver error")
+	}
+}
+
+func TestDefaultErrorMapper_SentinelsWrapCause(t *testing.T) {
+	deadlock := &mysqldriver.MySQLError{Number: 1213, Message: "Deadlock found"}
+	merr := DefaultErrorMapper{}.MapError(deadlock)
+	if !errors.Is(merr, ErrDeadlock) {
+		t.Fatalf("expected ErrDeadlock, got %+v", merr)
+	}
+	var got *mysqldriver.MySQLError
+	if !errors.As(merr, &got) || got.Number != 1213 {
+		t.Fatalf("expected the deadlock driver error in the chain")
+	}
+	if ErrDeadlock.Unwrap() != nil {
+		t.Fatalf("expected the shared sentinel to stay unmodified")
+	}
+
+	timeout := DefaultErrorMapper{}.MapError(fmt.Errorf("query: %w", context.DeadlineExceeded))
+	if !errors.Is(timeout, ErrTimeout) || !errors.Is(timeout, context.DeadlineExceeded) {
+		t.Fatalf("expected timeout to match ErrTimeout and context.DeadlineExceeded")
+	}
+
+	cause := errors.New("boom")
+	if errors.Unwrap(NewError(cause)) != cause {
+		t.Fatalf("expected NewError to wrap its argument")
+	}
+}
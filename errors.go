@@ -1,6 +1,11 @@
 package mysql
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+)
 
 // MySQLError represents a MySQL-specific error with structured information.
 // It implements the error interface and provides additional context beyond
@@ -10,6 +15,10 @@ type MySQLError struct {
 	Number   uint16  // MySQL-specific error code (e.g., 1062 for duplicate entry)
 	SQLState [5]byte // ANSI SQL state (5-character code categorizing the error type)
 	Message  string  // Human-readable error description
+
+	Category ErrorCategory // Normalized classification assigned by the ErrorMapper
+
+	wrapped error // Original error this one was converted from, exposed via Unwrap
 }
 
 // Error implements the error interface for MySQLError.
@@ -29,13 +38,36 @@ func (me *MySQLError) Error() string {
 // It allows errors.Is() to match MySQLError instances by their error number,
 // enabling error type checking without exact instance comparison.
 // Returns true if both errors are MySQLError instances with the same error number.
+// User-defined 45000 errors (ErrTimeout, ErrDeadlock, ...) share one number,
+// so for them the messages must match too. This also lets applications define
+// their own sentinels with NewError and match them via errors.Is.
 func (me *MySQLError) Is(err error) bool {
 	if merr, ok := err.(*MySQLError); ok {
+		if me.Number == ErrCodeUserDefined {
+			return merr.Number == me.Number && merr.Message == me.Message
+		}
 		return merr.Number == me.Number
 	}
 	return false
 }
 
+// Unwrap returns the original error the MySQLError was converted from, such
+// as the *mysql.MySQLError reported by the driver, so errors.As and
+// errors.Unwrap can reach it. It returns nil for errors raised by the
+// package itself.
+func (me *MySQLError) Unwrap() error {
+	return me.wrapped
+}
+
+// WithCause returns a copy of me that wraps cause. Sentinels such as
+// ErrTimeout are shared, so mappers use it to attach the original error
+// without mutating them; errors.Is still matches the sentinel.
+func (me *MySQLError) WithCause(cause error) *MySQLError {
+	cp := *me
+	cp.wrapped = cause
+	return &cp
+}
+
 // NewError creates a MySQLError from a standard Go error.
 // This is useful for converting generic errors into MySQL-compatible errors
 // with a standardized structure. The resulting error uses a generic error
@@ -43,10 +75,163 @@ func (me *MySQLError) Is(err error) bool {
 //
 // Use this function when you need to propagate errors through MySQL protocol
 // or maintain consistent error formatting across the application.
+// The SQL state of a driver error in err's chain is kept; otherwise it is
+// zeroed.
 func NewError(err error) *MySQLError {
 	return &MySQLError{
-		Number:   45000,                  // Generic user-defined error code in MySQL
-		SQLState: [5]byte{0, 0, 0, 0, 0}, // Zeroed SQL state indicates no specific category
-		Message:  err.Error(),            // Preserve the original error message
+		Number:   ErrCodeUserDefined, // Generic user-defined error code in MySQL
+		SQLState: sqlStateOf(err),    // Zeroed when err carries no SQL state
+		Message:  err.Error(),        // Preserve the original error message
+		wrapped:  err,                // Keep the original for errors.As
+	}
+}
+
+// NewMySQLError creates a MySQLError with an explicit error number and SQL
+// state, e.g. NewMySQLError(1062, "23000", "duplicate entry"). A sqlState
+// that is not exactly 5 characters long is ignored and leaves the state
+// zeroed.
+func NewMySQLError(number uint16, sqlState string, msg string) *MySQLError {
+	return &MySQLError{
+		Number:   number,
+		SQLState: parseSQLState(sqlState),
+		Message:  msg,
+	}
+}
+
+// parseSQLState converts a 5-character SQL state to its fixed-size form.
+func parseSQLState(s string) [5]byte {
+	var state [5]byte
+	if len(s) == len(state) {
+		copy(state[:], s)
+	}
+	return state
+}
+
+// sqlStateOf returns the SQL state carried by err's chain: that of a
+// *mysql.MySQLError, or of any error with a SQLState() string method (as
+// reported by other drivers and proxies). It is zero when there is none.
+func sqlStateOf(err error) [5]byte {
+	var sqlErr *mysql.MySQLError
+	if errors.As(err, &sqlErr) {
+		return sqlErr.SQLState
+	}
+	var stater interface{ SQLState() string }
+	if errors.As(err, &stater) {
+		return parseSQLState(stater.SQLState())
 	}
+	return [5]byte{}
 }
+
+// Error numbers and messages used by errors the package raises itself.
+// They all share MySQL's "unhandled user-defined exception" number and are
+// told apart by message.
+const (
+	ErrCodeUserDefined uint16 = 45000 // SQLSTATE 45000 style user-defined error
+
+	ErrCodeTimeout   = ErrCodeUserDefined // Query exceeded its timeout
+	ErrCodeDeadlock  = ErrCodeUserDefined // Driver reported a deadlock
+	ErrCodeSerialize = ErrCodeUserDefined // Result could not be encoded for the cache
+	ErrCodeClosed    = ErrCodeUserDefined // Client was closed
+	ErrCodeLock      = ErrCodeUserDefined // Stampede-protection lock could not be acquired
+	ErrCodeCache     = ErrCodeUserDefined // External cache backend failed
+	ErrCodeEmpty     = ErrCodeUserDefined // Params had neither Query nor Exec
+	ErrCodeCanceled  = ErrCodeUserDefined // Request context was canceled
+	ErrCodeCollision = ErrCodeUserDefined // Cached entry was written by a different statement
+	ErrCodePanic     = ErrCodeUserDefined // Query callback panicked (with Options.RecoverCallback)
+	ErrCodeRowLimit  = ErrCodeUserDefined // Result had more rows than Params.MaxRows
+	ErrCodeSession   = ErrCodeUserDefined // Params.SessionVars could not be applied
+
+	ErrMsgTimeout   = "TIMEOUT"
+	ErrMsgDeadlock  = "DEADLOCK"
+	ErrMsgSerialize = "SERIALIZE"
+	ErrMsgClosed    = "CLOSED"
+	ErrMsgLock      = "LOCK"
+	ErrMsgCache     = "CACHE"
+	ErrMsgEmpty     = "EMPTY_QUERY"
+	ErrMsgCanceled  = "CANCELED"
+	ErrMsgCollision = "KEY_COLLISION"
+	ErrMsgPanic     = "PANIC"
+	ErrMsgRowLimit  = "ROW_LIMIT"
+	ErrMsgSession   = "SESSION_VARS"
+)
+
+var (
+	// ErrTimeout is returned when a query exceeds its timeout.
+	ErrTimeout = &MySQLError{Number: ErrCodeTimeout, Message: ErrMsgTimeout, Category: CategoryTimeout}
+
+	// ErrCanceled is returned when the request context (Params.Context) was
+	// canceled before the query finished. Unlike ErrTimeout it means the
+	// caller gave up, so it is usually not worth retrying.
+	ErrCanceled = &MySQLError{Number: ErrCodeCanceled, Message: ErrMsgCanceled, Category: CategoryCanceled}
+
+	// ErrDeadlock is returned when the server aborted the query because of a
+	// deadlock; the operation can usually be retried.
+	ErrDeadlock = &MySQLError{Number: ErrCodeDeadlock, Message: ErrMsgDeadlock, Category: CategoryDeadlock}
+
+	// ErrSerialize is returned alongside a valid result when the result could
+	// not be encoded for the external cache.
+	ErrSerialize = &MySQLError{Number: ErrCodeSerialize, Message: ErrMsgSerialize}
+
+	// ErrLockFailed is returned when the keyed mutex guarding a cache fill
+	// cannot be acquired and Options.DegradeOnCacheError is false.
+	ErrLockFailed = &MySQLError{Number: ErrCodeLock, Message: ErrMsgLock}
+
+	// ErrCacheUnavailable is returned when the external cache backend fails
+	// and Options.DegradeOnCacheError is false.
+	ErrCacheUnavailable = &MySQLError{Number: ErrCodeCache, Message: ErrMsgCache}
+
+	// ErrKeyCollision is returned with DetectKeyCollisions when an external
+	// cache entry was written by a different statement than the one reading
+	// it, e.g. two queries sharing a manual Params.Key.
+	ErrKeyCollision = &MySQLError{Number: ErrCodeCollision, Message: ErrMsgCollision}
+
+	// ErrEmptyQuery is returned when Params has neither Query nor Exec set,
+	// which would otherwise produce the invalid statement "CALL ()".
+	ErrEmptyQuery = &MySQLError{Number: ErrCodeEmpty, Message: ErrMsgEmpty}
+
+	// ErrPanic is matched by the error returned when a Query callback panics
+	// and Options.RecoverCallback is set. The recovered value is reachable
+	// through errors.Unwrap (an error value is wrapped as-is).
+	ErrPanic = &MySQLError{Number: ErrCodePanic, Message: ErrMsgPanic}
+
+	// ErrRowLimit is returned when a query produced more rows than
+	// Params.MaxRows. Iteration stops at the limit, so the callback's
+	// partial result is discarded and never cached.
+	ErrRowLimit = &MySQLError{Number: ErrCodeRowLimit, Message: ErrMsgRowLimit}
+
+	// ErrSessionVars is returned when Params.SessionVars cannot be used: the
+	// DB cannot pin a connection (SessionDB) or a variable name is invalid.
+	// The cause names the problem.
+	ErrSessionVars = &MySQLError{Number: ErrCodeSession, Message: ErrMsgSession}
+
+	// ErrArgCount is matched (via errors.Is) by errors reporting that the
+	// number of arguments differs from the number of placeholders. It mirrors
+	// MySQL error 1210 ("Incorrect arguments to mysqld_stmt_execute"); the
+	// returned errors carry a message with the actual counts.
+	ErrArgCount = &MySQLError{
+		Number:   1210,
+		SQLState: [5]byte{'H', 'Y', '0', '0', '0'},
+		Message:  "incorrect number of arguments",
+	}
+
+	// ErrNoRows is returned by single-row helpers such as QueryRow when the
+	// query produced an empty result set. It mirrors MySQL error 1329
+	// ("No data - zero rows fetched, selected, or processed").
+	ErrNoRows = &MySQLError{
+		Number:   1329,
+		SQLState: [5]byte{'0', '2', '0', '0', '0'},
+		Message:  "no rows in result set",
+	}
+
+	// ErrClosed is returned when a query is issued after the client has been shut down.
+	ErrClosed = &MySQLError{Number: ErrCodeClosed, Message: ErrMsgClosed}
+
+	// ErrTooManyRows is returned by single-row helpers in strict mode when the
+	// query produced more than one row. It mirrors MySQL error 1172
+	// ("Result consisted of more than one row").
+	ErrTooManyRows = &MySQLError{
+		Number:   1172,
+		SQLState: [5]byte{'4', '2', '0', '0', '0'},
+		Message:  "result consisted of more than one row",
+	}
+)
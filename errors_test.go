@@ -1,8 +1,13 @@
 package mysql
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
+
+	driver "github.com/go-sql-driver/mysql"
 )
 
 func TestMySQLError_ErrorFormatting(t *testing.T) {
@@ -24,6 +29,45 @@ func TestMySQLError_ErrorFormatting(t *testing.T) {
 	}
 }
 
+func TestNewMySQLError_SQLState(t *testing.T) {
+	withState := NewMySQLError(1062, "23000", "duplicate entry")
+	if withState.SQLState != [5]byte{'2', '3', '0', '0', '0'} {
+		t.Fatalf("unexpected SQL state: %q", withState.SQLState)
+	}
+	if got := withState.Error(); got != "Error 1062 (23000): duplicate entry" {
+		t.Fatalf("unexpected Error() output: %q", got)
+	}
+
+	for _, state := range []string{"", "2300", "230000"} {
+		err := NewMySQLError(1062, state, "duplicate entry")
+		if got := err.Error(); got != "Error 1062: duplicate entry" {
+			t.Fatalf("expected state %q to be ignored, got %q", state, got)
+		}
+	}
+}
+
+type stateError struct{ state string }
+
+func (e stateError) Error() string    { return "proxy failure" }
+func (e stateError) SQLState() string { return e.state }
+
+func TestNewError_KeepsSQLState(t *testing.T) {
+	plain := NewError(errors.New("boom"))
+	if plain.SQLState != [5]byte{} {
+		t.Fatalf("expected zero SQL state, got %q", plain.SQLState)
+	}
+
+	fromDriver := NewError(fmt.Errorf("wrapped: %w", &driver.MySQLError{Number: 1213, SQLState: [5]byte{'4', '0', '0', '0', '1'}, Message: "deadlock"}))
+	if fromDriver.SQLState != [5]byte{'4', '0', '0', '0', '1'} || fromDriver.Number != ErrCodeUserDefined {
+		t.Fatalf("expected driver SQL state with number 45000, got %v", fromDriver)
+	}
+
+	fromStater := NewError(stateError{state: "08S01"})
+	if got := fromStater.Error(); got != "Error 45000 (08S01): proxy failure" {
+		t.Fatalf("unexpected Error() output: %q", got)
+	}
+}
+
 func TestMySQLError_Is(t *testing.T) {
 	base := &MySQLError{Number: 1234}
 	if !errors.Is(base, &MySQLError{Number: 1234}) {
@@ -50,3 +94,92 @@ func TestNewError(t *testing.T) {
 		t.Fatalf("expected SQLState to be zeroed")
 	}
 }
+
+func TestMySQLError_IsUserDefined(t *testing.T) {
+	sentinels := []*MySQLError{ErrTimeout, ErrCanceled, ErrDeadlock, ErrSerialize, ErrClosed, ErrKeyCollision}
+	for _, target := range sentinels {
+		for _, other := range sentinels {
+			if got, want := errors.Is(target, other), target == other; got != want {
+				t.Errorf("errors.Is(%v, %v) = %v, want %v", target, other, got, want)
+			}
+		}
+		// Copies and wrapped errors still match
+		cp := *target
+		if !errors.Is(fmt.Errorf("wrapped: %w", &cp), target) {
+			t.Errorf("expected wrapped copy of %v to match", target)
+		}
+	}
+
+	custom := NewError(errors.New("QUOTA"))
+	if !errors.Is(NewError(errors.New("QUOTA")), custom) {
+		t.Fatalf("expected custom 45000 errors with equal messages to match")
+	}
+	if errors.Is(custom, ErrTimeout) {
+		t.Fatalf("expected custom 45000 error not to match ErrTimeout")
+	}
+}
+
+func TestQuery_ReturnsSentinels(t *testing.T) {
+	callback := func(rows Rows) (*[]int, *MySQLError) {
+		out := []int{1}
+		return &out, nil
+	}
+
+	t.Run("deadlock", func(t *testing.T) {
+		db := NewMockDB()
+		db.WithStmt("SELECT 1", &MockStmt{Err: &driver.MySQLError{Number: 1213}})
+		client, cleanup := newInternalClient(db)
+		defer cleanup()
+
+		_, err := Query(client, Params{Query: "SELECT 1"}, callback)
+		if !errors.Is(err, ErrDeadlock) {
+			t.Fatalf("expected ErrDeadlock, got %+v", err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		db := NewMockDB()
+		db.WithStmt("SELECT 1", &MockStmt{Delay: time.Second})
+		client, cleanup := newInternalClient(db)
+		defer cleanup()
+
+		_, err := Query(client, Params{Query: "SELECT 1", Timeout: time.Millisecond}, callback)
+		if !errors.Is(err, ErrTimeout) || errors.Is(err, ErrCanceled) {
+			t.Fatalf("expected ErrTimeout, got %+v", err)
+		}
+		if err.Category != CategoryTimeout || !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected timeout category wrapping the deadline, got %+v", err)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		db := NewMockDB()
+		db.WithStmt("SELECT 1", &MockStmt{Delay: time.Second})
+		client, cleanup := newInternalClient(db)
+		defer cleanup()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(5*time.Millisecond, cancel)
+
+		_, err := Query(client, Params{Query: "SELECT 1", Context: ctx}, callback)
+		if !errors.Is(err, ErrCanceled) || errors.Is(err, ErrTimeout) {
+			t.Fatalf("expected ErrCanceled, got %+v", err)
+		}
+		if err.Category != CategoryCanceled || !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected canceled category wrapping context.Canceled, got %+v", err)
+		}
+	})
+
+	t.Run("serialize", func(t *testing.T) {
+		db := NewMockDB()
+		db.WithStmt("SELECT 1", &MockStmt{Factory: func() Rows { return &MockRows{} }})
+		client, cleanup := newExternalClient(db, newFakeCache())
+		defer cleanup()
+		client.codec = failingCodec{}
+
+		res, err := Query(client, Params{Query: "SELECT 1", CacheDelay: time.Minute}, callback)
+		if !errors.Is(err, ErrSerialize) || res == nil {
+			t.Fatalf("expected result with ErrSerialize, got %v %+v", res, err)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package mysql
+
+import "database/sql"
+
+// ExecResult summarizes the outcome of a write statement.
+type ExecResult struct {
+	RowsAffected int64 // Number of rows inserted, updated, or deleted
+	LastInsertID int64 // AUTO_INCREMENT value generated by the last executed statement
+}
+
+// add accumulates the counters reported by res into r.
+// Drivers that cannot report a counter leave the corresponding field untouched.
+func (r *ExecResult) add(res sql.Result) {
+	if res == nil {
+		return
+	}
+	if n, err := res.RowsAffected(); err == nil {
+		r.RowsAffected += n
+	}
+	if id, err := res.LastInsertId(); err == nil {
+		r.LastInsertID = id
+	}
+}
+
+// Exec runs a write statement described by params (Query, or a stored
+// procedure in Exec) and reports the affected row count and last insert ID.
+// Cache fields in params are ignored; see ExecWriteThrough.
+func Exec(c *MySQL, params Params) (*ExecResult, *MySQLError) {
+	if !c.beginQuery() {
+		return nil, ErrClosed
+	}
+	defer c.endQuery()
+
+	params = c.withDefaultDatabase(params.withExpandedSlices())
+	if merr := c.validateStatement(params); merr != nil {
+		return nil, merr
+	}
+
+	query := generateQuery(params)
+	stmt, err := c.prepareStatement(params, query)
+	if err != nil {
+		return nil, c.mapError(err)
+	}
+
+	ctx, cancel := createContextWithTimeout(params.requestContext(), params.Timeout)
+	defer cancel()
+
+	release, err := c.acquireQuerySlot(ctx)
+	if err != nil {
+		return nil, c.mapError(err)
+	}
+	defer release()
+
+	res, err := c.execStmt(ctx, params, query, stmt)
+	if err != nil {
+		return nil, c.mapError(err)
+	}
+
+	result := &ExecResult{}
+	result.add(res)
+	return result, nil
+}
+
+// ExecWriteThrough runs a write like Exec and, on success, stores updated
+// under params.Key so later reads are served from cache without a database
+// round trip. params.Key must be the key of the read query for the entity
+// (its manual Key, or CreateKey of its Params); CacheDelay and NodeCacheDelay
+// give the TTLs exactly as they would for Query.
+//
+// If updated cannot be cached (codec failure or MaxValueBytes), the key is
+// invalidated instead so readers never see the pre-update value; a codec
+// failure is also reported as ErrSerialize alongside the write result.
+// Without a Key or updated value, ExecWriteThrough behaves like Exec.
+func ExecWriteThrough[T any](c *MySQL, params Params, updated *T) (*ExecResult, *MySQLError) {
+	result, merr := Exec(c, params)
+	if merr != nil || params.Key == "" || updated == nil {
+		return result, merr
+	}
+
+	key := params.Key
+	if c.cache == nil {
+		// L1 only, using CacheDelay like internalQuery
+		if params.CacheDelay > 0 && fitsCache(c, updated) {
+			c.setL1(key, updated, params.CacheDelay)
+		} else {
+			_ = c.inMemory.Delete(key)
+		}
+		return result, nil
+	}
+
+	// No fingerprint: params describe the write, not the read that owns key
+	stored, merr := c.storeExternal(key, params, 0, updated)
+	// Fall back to invalidation so the old value is not served
+	if !stored || c.nodeTTL(params) <= 0 {
+		_ = c.inMemory.Delete(key)
+	}
+	if !stored {
+		_ = c.cache.Delete(key)
+	}
+	return result, merr
+}
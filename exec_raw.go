@@ -0,0 +1,52 @@
+package mysql
+
+import "context"
+
+// ExecRaw runs sql directly on the database, without preparing it, binding
+// arguments, or touching the caches. It is meant for migrations and admin
+// tasks whose statements cannot be prepared, such as DDL (CREATE TABLE,
+// ALTER TABLE), SET, or scripts of several statements separated by ';',
+// which additionally require Options.MultiStatements.
+//
+// ctx bounds the statement; without a deadline (or with a nil ctx) the
+// default query timeout applies. The counters in the result are those the
+// driver reports for the script.
+// Never build sql from untrusted input: nothing is escaped.
+func (c *MySQL) ExecRaw(ctx context.Context, sql string) (*ExecResult, *MySQLError) {
+	if !c.beginQuery() {
+		return nil, ErrClosed
+	}
+	defer c.endQuery()
+
+	if sql == "" {
+		return nil, ErrEmptyQuery
+	}
+
+	// Long migrations set their own deadline; otherwise use the default
+	if ctx == nil || !hasDeadline(ctx) {
+		var cancel context.CancelFunc
+		ctx, cancel = createContextWithTimeout(ctx, 0)
+		defer cancel()
+	}
+
+	release, err := c.acquireQuerySlot(ctx)
+	if err != nil {
+		return nil, c.mapError(err)
+	}
+	defer release()
+
+	res, err := c.DB.ExecContext(ctx, sql)
+	if err != nil {
+		return nil, c.mapError(err)
+	}
+
+	result := &ExecResult{}
+	result.add(res)
+	return result, nil
+}
+
+// hasDeadline reports whether ctx carries a deadline.
+func hasDeadline(ctx context.Context) bool {
+	_, ok := ctx.Deadline()
+	return ok
+}
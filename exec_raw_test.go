@@ -0,0 +1,93 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecRaw_RunsUnprepared(t *testing.T) {
+	db := NewMockDB()
+	db.ExecResult = MockResult{Affected: 3}
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	script := "CREATE TABLE t (id INT); INSERT INTO t VALUES (1), (2), (3)"
+	res, err := client.ExecRaw(context.Background(), script)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.RowsAffected != 3 {
+		t.Fatalf("expected 3 affected rows, got %d", res.RowsAffected)
+	}
+
+	if len(db.Execs) != 1 || db.Execs[0].Query != script || len(db.Execs[0].Args) != 0 {
+		t.Fatalf("expected one raw exec of the script, got %+v", db.Execs)
+	}
+	if db.Prepares != 0 || len(client.prepare) != 0 {
+		t.Fatalf("expected the prepared statement cache to be bypassed, got %d prepares, %d cached", db.Prepares, len(client.prepare))
+	}
+}
+
+func TestExecRaw_Errors(t *testing.T) {
+	db := NewMockDB()
+	syntaxErr := errors.New("syntax error")
+	db.ExecErr = syntaxErr
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	if _, err := client.ExecRaw(context.Background(), ""); !errors.Is(err, ErrEmptyQuery) {
+		t.Fatalf("expected ErrEmptyQuery, got %v", err)
+	}
+	if len(db.Execs) != 0 {
+		t.Fatal("expected an empty script not to reach the database")
+	}
+
+	if _, err := client.ExecRaw(context.Background(), "SET @x = 1"); !errors.Is(err, syntaxErr) {
+		t.Fatalf("expected driver error, got %v", err)
+	}
+
+	client.markClosed()
+	if _, err := client.ExecRaw(context.Background(), "SET @x = 1"); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+// deadlineRecordingDB records the deadline of the context passed to ExecContext.
+type deadlineRecordingDB struct {
+	*MockDB
+	deadline *time.Time
+}
+
+func (d *deadlineRecordingDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	*d.deadline, _ = ctx.Deadline()
+	return d.MockDB.ExecContext(ctx, query, args...)
+}
+
+func TestExecRaw_KeepsCallerDeadline(t *testing.T) {
+	var deadline time.Time
+	db := &deadlineRecordingDB{MockDB: NewMockDB(), deadline: &deadline}
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	if _, err := client.ExecRaw(ctx, "ALTER TABLE t ADD COLUMN c INT"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if time.Until(deadline) < 59*time.Minute {
+		t.Fatalf("expected the caller's one-hour deadline, got %v", time.Until(deadline))
+	}
+}
+
+func TestDefaultOptions_MultiStatements(t *testing.T) {
+	if !strings.Contains(defaultOptions(Options{MultiStatements: true}).ConnectionString, "&multiStatements=true") {
+		t.Fatal("expected multiStatements in DSN")
+	}
+	if strings.Contains(defaultOptions(Options{}).ConnectionString, "multiStatements") {
+		t.Fatal("expected multiStatements only when enabled")
+	}
+}
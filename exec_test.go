@@ -0,0 +1,171 @@
+package mysql
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	driver "github.com/go-sql-driver/mysql"
+)
+
+type account struct {
+	ID      int
+	Balance int
+}
+
+func readAccount(rows Rows) (*account, *MySQLError) {
+	var a account
+	if !rows.Next() {
+		return nil, ErrNoRows
+	}
+	_ = rows.Scan(&a.ID, &a.Balance)
+	return &a, nil
+}
+
+func TestExec_ReportsResult(t *testing.T) {
+	db := &execRecordingDB{}
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	res, err := Exec(client, Params{Query: "UPDATE accounts SET balance = ? WHERE id = ?", Args: []any{10, 1}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.RowsAffected != 1 || res.LastInsertID != 100 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if len(db.execs) != 1 || db.execs[0].query != "UPDATE accounts SET balance = ? WHERE id = ?" {
+		t.Fatalf("unexpected execs: %+v", db.execs)
+	}
+}
+
+func TestExec_MockDBStatementResult(t *testing.T) {
+	const update = "UPDATE accounts SET balance = ? WHERE id = ?"
+	db := NewMockDB()
+	db.WithStmt(update, &MockStmt{ResultFactory: func() sql.Result { return MockResult{LastID: 5, Affected: 3} }})
+	db.WithStmt("DELETE FROM accounts", &MockStmt{
+		ResultFactory: func() sql.Result { return MockResult{} },
+		Err:           &driver.MySQLError{Number: 1451, Message: "foreign key constraint fails"},
+	})
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	res, err := Exec(client, Params{Query: update, Args: []any{10, 1}})
+	if err != nil || res.RowsAffected != 3 || res.LastInsertID != 5 {
+		t.Fatalf("expected the statement's result, got %+v (%v)", res, err)
+	}
+
+	// The statement prepares and fails on execution
+	if _, err := Exec(client, Params{Query: "DELETE FROM accounts"}); err == nil || err.Number != 1451 {
+		t.Fatalf("expected error 1451 from the exec, got %v", err)
+	}
+	if db.Prepares != 2 {
+		t.Fatalf("expected both statements to be prepared, got %d prepares", db.Prepares)
+	}
+
+	// Unprepared writes fall back to the registered statement
+	client.disablePrepare = true
+	if res, err := Exec(client, Params{Query: update}); err != nil || res.RowsAffected != 3 || len(db.Execs) != 1 {
+		t.Fatalf("expected the statement's result unprepared, got %+v (%v)", res, err)
+	}
+}
+
+func TestExec_EmptyQuery(t *testing.T) {
+	client, cleanup := newInternalClient(&execRecordingDB{})
+	defer cleanup()
+
+	if _, err := Exec(client, Params{}); !errors.Is(err, ErrEmptyQuery) {
+		t.Fatalf("expected ErrEmptyQuery, got %v", err)
+	}
+}
+
+func TestExecWriteThrough_External(t *testing.T) {
+	cache := newFakeCache()
+	// execRecordingDB fails every read, so cached values are the only source
+	client, cleanup := newExternalClient(&execRecordingDB{}, cache)
+	defer cleanup()
+
+	updated := &account{ID: 1, Balance: 50}
+	params := Params{
+		Key:            "account:1",
+		Query:          "UPDATE accounts SET balance = ? WHERE id = ?",
+		Args:           []any{50, 1},
+		CacheDelay:     time.Minute,
+		NodeCacheDelay: time.Minute,
+	}
+	if _, err := ExecWriteThrough(client, params, updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := cache.Get("account:1"); err != nil {
+		t.Fatalf("expected L2 to hold the new value, got %v", err)
+	}
+
+	read := Params{Key: "account:1", Query: "SELECT id, balance FROM accounts WHERE id = ?", Args: []any{1}, CacheDelay: time.Minute}
+	got, err := Query(client, read, readAccount)
+	if err != nil || *got != *updated {
+		t.Fatalf("expected cached %+v, got %+v (%v)", updated, got, err)
+	}
+
+	// L1 alone also serves the value
+	_ = cache.Delete("account:1")
+	read.NodeCacheDelay = time.Minute
+	if got, err := Query(client, read, readAccount); err != nil || *got != *updated {
+		t.Fatalf("expected L1 to hold %+v, got %+v (%v)", updated, got, err)
+	}
+}
+
+func TestExecWriteThrough_Internal(t *testing.T) {
+	client, cleanup := newInternalClient(&execRecordingDB{})
+	defer cleanup()
+
+	updated := &account{ID: 2, Balance: 7}
+	params := Params{Key: "account:2", Query: "UPDATE accounts SET balance = ? WHERE id = ?", Args: []any{7, 2}, CacheDelay: time.Minute}
+	if _, err := ExecWriteThrough(client, params, updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	read := Params{Key: "account:2", Query: "SELECT id, balance FROM accounts WHERE id = ?", Args: []any{2}, CacheDelay: time.Minute}
+	if got, err := Query(client, read, readAccount); err != nil || *got != *updated {
+		t.Fatalf("expected cached %+v, got %+v (%v)", updated, got, err)
+	}
+}
+
+func TestExecWriteThrough_InvalidatesWhenNotCacheable(t *testing.T) {
+	cache := newFakeCache()
+	client, cleanup := newExternalClient(&execRecordingDB{}, cache)
+	defer cleanup()
+	client.codec = failingCodec{}
+
+	_ = cache.Set("account:3", []byte("stale"), time.Minute)
+	_ = client.inMemory.Set("account:3", &account{ID: 3}, time.Minute)
+
+	params := Params{Key: "account:3", Query: "UPDATE accounts SET balance = 1 WHERE id = 3", CacheDelay: time.Minute, NodeCacheDelay: time.Minute}
+	res, err := ExecWriteThrough(client, params, &account{ID: 3, Balance: 1})
+	if res == nil || !errors.Is(err, ErrSerialize) {
+		t.Fatalf("expected write result with ErrSerialize, got %+v (%v)", res, err)
+	}
+	if _, err := cache.Get("account:3"); err != ErrNotFound {
+		t.Fatalf("expected stale L2 entry to be invalidated, got %v", err)
+	}
+	if _, err := client.inMemory.Get("account:3"); err != ErrNotFound {
+		t.Fatalf("expected stale L1 entry to be invalidated, got %v", err)
+	}
+}
+
+func TestExecWriteThrough_FailedWriteLeavesCache(t *testing.T) {
+	cache := newFakeCache()
+	db := &execRecordingDB{failOn: 1, err: errors.New("boom")}
+	client, cleanup := newExternalClient(db, cache)
+	defer cleanup()
+
+	_ = cache.Set("account:4", []byte("old"), time.Minute)
+	params := Params{Key: "account:4", Query: "UPDATE accounts SET balance = 0 WHERE id = 4", CacheDelay: time.Minute}
+	if _, err := ExecWriteThrough(client, params, &account{ID: 4}); err == nil {
+		t.Fatal("expected the write error")
+	}
+	if v, _ := cache.Get("account:4"); string(v) != "old" {
+		t.Fatalf("expected cache untouched after a failed write, got %q", v)
+	}
+}
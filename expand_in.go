@@ -0,0 +1,92 @@
+package mysql
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+)
+
+// ExpandIN rewrites query so slice arguments can be bound to a single '?',
+// as in "WHERE id IN (?)". Each placeholder whose argument is a slice is
+// replaced by one marker per element ("?,?,?") and the elements are spliced
+// into the returned argument list in place of the slice. An empty slice
+// becomes NULL, so "IN (?)" matches no rows instead of being a syntax error.
+//
+// Byte slices and driver.Valuer arguments are passed through unchanged. Markers in
+// quoted text and comments are ignored, as with StrictArgs. When no argument
+// is a slice, query and args are returned as given.
+func ExpandIN(query string, args []any) (string, []any) {
+	if !hasSliceArg(args) {
+		return query, args
+	}
+
+	var sb strings.Builder
+	out := make([]any, 0, len(args))
+	last, n := 0, 0
+	forEachPlaceholder(query, func(pos int) {
+		if n >= len(args) {
+			return
+		}
+		arg := args[n]
+		n++
+		v, ok := expandable(arg)
+		if !ok {
+			out = append(out, arg)
+			return
+		}
+
+		sb.WriteString(query[last:pos])
+		last = pos + 1
+		if v.Len() == 0 {
+			sb.WriteString("NULL")
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteByte('?')
+			out = append(out, v.Index(i).Interface())
+		}
+	})
+	sb.WriteString(query[last:])
+
+	// Arguments without a marker are left for the driver to report
+	out = append(out, args[n:]...)
+	return sb.String(), out
+}
+
+// hasSliceArg reports whether any of args would be expanded by ExpandIN.
+func hasSliceArg(args []any) bool {
+	for _, arg := range args {
+		if _, ok := expandable(arg); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// expandable returns arg as a reflect.Value when it is a slice that ExpandIN
+// should expand. Byte slices (including named ones like json.RawMessage)
+// are single values.
+func expandable(arg any) (reflect.Value, bool) {
+	switch arg.(type) {
+	case nil, driver.Valuer:
+		return reflect.Value{}, false
+	}
+	v := reflect.ValueOf(arg)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+		return reflect.Value{}, false
+	}
+	return v, true
+}
+
+// withExpandedSlices applies ExpandIN to a direct Query when
+// Params.ExpandSlices is set, so cache keys and StrictArgs see the final
+// statement and every element of an IN list.
+func (p Params) withExpandedSlices() Params {
+	if p.ExpandSlices && p.Query != "" {
+		p.Query, p.Args = ExpandIN(p.Query, p.Args)
+	}
+	return p
+}
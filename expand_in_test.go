@@ -0,0 +1,99 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExpandIN(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		args      []any
+		wantQuery string
+		wantArgs  []any
+	}{
+		{
+			name:      "no slices",
+			query:     "SELECT * FROM t WHERE id = ?",
+			args:      []any{1},
+			wantQuery: "SELECT * FROM t WHERE id = ?",
+			wantArgs:  []any{1},
+		},
+		{
+			name:      "single slice",
+			query:     "SELECT * FROM t WHERE id IN (?)",
+			args:      []any{[]int{1, 2, 3}},
+			wantQuery: "SELECT * FROM t WHERE id IN (?,?,?)",
+			wantArgs:  []any{1, 2, 3},
+		},
+		{
+			name:      "multiple slices and scalars",
+			query:     "SELECT * FROM t WHERE a = ? AND id IN (?) AND name IN (?) LIMIT ?",
+			args:      []any{"x", []int64{4, 5}, []string{"a", "b", "c"}, 10},
+			wantQuery: "SELECT * FROM t WHERE a = ? AND id IN (?,?) AND name IN (?,?,?) LIMIT ?",
+			wantArgs:  []any{"x", int64(4), int64(5), "a", "b", "c", 10},
+		},
+		{
+			name:      "empty slice",
+			query:     "SELECT * FROM t WHERE id IN (?)",
+			args:      []any{[]int{}},
+			wantQuery: "SELECT * FROM t WHERE id IN (NULL)",
+			wantArgs:  []any{},
+		},
+		{
+			name:      "bytes stay scalar and quoted markers are skipped",
+			query:     "SELECT '?' FROM t WHERE h = ? AND id IN (?)",
+			args:      []any{[]byte{1, 2}, []any{7, "8"}},
+			wantQuery: "SELECT '?' FROM t WHERE h = ? AND id IN (?,?)",
+			wantArgs:  []any{[]byte{1, 2}, 7, "8"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := ExpandIN(tt.query, tt.args)
+			if query != tt.wantQuery {
+				t.Fatalf("query = %q, want %q", query, tt.wantQuery)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Fatalf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestQuery_ExpandSlices(t *testing.T) {
+	db := NewMockDB()
+	db.WithStmt("SELECT name FROM users WHERE id IN (?,?)", &MockStmt{Factory: func() Rows {
+		return &MockRows{data: [][]any{{"alice"}, {"bob"}}}
+	}})
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+	client.strictArgs = true
+
+	params := Params{
+		Query:        "SELECT name FROM users WHERE id IN (?)",
+		Args:         []any{[]int{1, 2}},
+		ExpandSlices: true,
+		CacheDelay:   time.Minute,
+	}
+	res, err := Query(client, params, scanStrings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*res) != 2 {
+		t.Fatalf("unexpected result: %v", *res)
+	}
+
+	keyA := CreateKey(params.withExpandedSlices(), client)
+	params.Args = []any{[]int{1, 3}}
+	keyB := CreateKey(params.withExpandedSlices(), client)
+	if keyA == keyB {
+		t.Fatalf("expected different IN lists to produce different keys, got %q", keyA)
+	}
+	if _, err := client.inMemory.Get(keyA); err != nil {
+		t.Fatalf("expected result cached under the expanded key: %v", err)
+	}
+}
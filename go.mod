@@ -2,7 +2,10 @@ module github.com/elum-utils/mysql
 
 go 1.21.0
 
-require github.com/go-sql-driver/mysql v1.9.3
+require (
+	github.com/cespare/xxhash/v2 v2.3.0
+	github.com/go-sql-driver/mysql v1.9.3
+)
 
 require github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
 
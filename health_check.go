@@ -0,0 +1,65 @@
+package mysql
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// healthChecker pings the database on a fixed interval in the background
+// and records the outcome in MySQL.unhealthy (see Options.HealthCheckInterval).
+type healthChecker struct {
+	quit     chan struct{} // Closed to stop the loop
+	done     chan struct{} // Closed once the loop has exited
+	stopOnce sync.Once
+}
+
+// startHealthCheck launches the ping loop for c. Each ping is bounded by
+// interval so a hung connection cannot stall the loop.
+func (c *MySQL) startHealthCheck(interval time.Duration, clk clock) *healthChecker {
+	h := &healthChecker{quit: make(chan struct{}), done: make(chan struct{})}
+	t := clk.NewTicker(interval)
+
+	go func() {
+		defer close(h.done)
+		defer t.Stop()
+		for {
+			select {
+			case <-h.quit:
+				return
+			case <-t.C():
+				c.checkHealth(interval)
+			}
+		}
+	}()
+	return h
+}
+
+// stop ends the ping loop and waits for it to exit. It is safe to call
+// more than once.
+func (h *healthChecker) stop() {
+	h.stopOnce.Do(func() { close(h.quit) })
+	<-h.done
+}
+
+// checkHealth pings the database once and logs transitions between healthy
+// and unhealthy, so an outage produces one warning rather than one per tick.
+func (c *MySQL) checkHealth(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := c.db.PingContext(ctx)
+	wasUnhealthy := c.unhealthy.Swap(err != nil)
+	switch {
+	case err != nil && !wasUnhealthy:
+		c.log().Warn("mysql: health check failed", "error", err)
+	case err == nil && wasUnhealthy:
+		c.log().Info("mysql: health check recovered")
+	}
+}
+
+// Healthy reports whether the last background health check reached the
+// database. It is always true when Options.HealthCheckInterval is unset.
+func (c *MySQL) Healthy() bool {
+	return !c.unhealthy.Load()
+}
@@ -0,0 +1,75 @@
+package mysql
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHealthCheck_FlipsOnPingFailure(t *testing.T) {
+	db := newTestSQLDB(errors.New("server down"))
+	client := newClient(db, defaultOptions())
+	var logs bytes.Buffer
+	client.logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	clk := newFakeClock()
+	client.healthCheck = client.startHealthCheck(time.Second, clk)
+
+	if !client.Healthy() {
+		t.Fatal("expected client to start healthy")
+	}
+
+	clk.Tick()
+	if client.Healthy() || client.Status().Healthy {
+		t.Fatal("expected failed ping to mark the client unhealthy")
+	}
+
+	// A continuing outage is logged once
+	clk.Tick()
+	if n := strings.Count(logs.String(), "health check failed"); n != 1 {
+		t.Fatalf("expected one warning, got %d:\n%s", n, logs.String())
+	}
+
+	client.Close()
+	select {
+	case <-client.healthCheck.done:
+	default:
+		t.Fatal("expected the health check loop to stop on Close")
+	}
+}
+
+func TestHealthCheck_StaysHealthy(t *testing.T) {
+	client := newClient(newTestSQLDB(nil), defaultOptions())
+	defer client.Close()
+
+	clk := newFakeClock()
+	client.healthCheck = client.startHealthCheck(time.Second, clk)
+	clk.Tick()
+	if !client.Healthy() {
+		t.Fatal("expected successful ping to keep the client healthy")
+	}
+}
+
+func TestHealthCheck_StartedByOption(t *testing.T) {
+	client := newClient(newTestSQLDB(nil), defaultOptions(Options{HealthCheckInterval: time.Hour}))
+	if client.healthCheck == nil {
+		t.Fatal("expected HealthCheckInterval to start the health check")
+	}
+
+	client.Close()
+	client.Close() // Stopping twice must not block or panic
+	select {
+	case <-client.healthCheck.done:
+	default:
+		t.Fatal("expected the health check loop to stop on Close")
+	}
+
+	disabled := newClient(newTestSQLDB(nil), defaultOptions())
+	defer disabled.Close()
+	if disabled.healthCheck != nil {
+		t.Fatal("expected no health check by default")
+	}
+}
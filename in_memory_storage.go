@@ -1,14 +1,23 @@
 package mysql
 
 import (
+	"bytes"
 	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 var (
 	// ErrNotFound is returned when a requested key does not exist in the cache.
 	ErrNotFound = errors.New("key not found")
+
+	// ErrValueTooLarge is returned by Set when a value exceeds the configured
+	// maximum value size; the value is not stored.
+	ErrValueTooLarge = errors.New("value exceeds maximum cache value size")
+
+	// ErrNotBytes is returned by GetRaw when the stored value is not a []byte.
+	ErrNotBytes = errors.New("cached value is not []byte")
 )
 
 // entryStorage represents a single cache entry stored in a doubly-linked list.
@@ -17,9 +26,55 @@ var (
 type entryStorage struct {
 	key       string        // Cache key identifier
 	value     any           // Stored value (interface{} for type flexibility)
-	expiresIn time.Duration // TTL (Time To Live) from cache creation
+	expiresAt time.Time     // Absolute expiration time (zero means no expiration)
+	size      int           // Approximate in-memory size in bytes (tracked when a byte budget is set)
+	version   uint64        // Store write count when value was set (see SetMaxBytes)
 	prev      *entryStorage // Previous node in LRU list (nil for head)
 	next      *entryStorage // Next node in LRU list (nil for tail)
+	// Read since it last reached the tail (EvictionClock only)
+	referenced atomic.Bool
+}
+
+// EvictReason describes why an entry left the InMemoryStorage.
+type EvictReason int
+
+const (
+	// EvictLRU means the entry was dropped to make room for a newer one.
+	EvictLRU EvictReason = iota
+	// EvictExpired means the entry's TTL elapsed.
+	EvictExpired
+	// EvictManual means the entry was removed with Delete or DeletePrefix.
+	EvictManual
+	// EvictReplaced means Set overwrote the entry's value.
+	EvictReplaced
+)
+
+// String returns a human-readable name for the reason.
+func (r EvictReason) String() string {
+	switch r {
+	case EvictLRU:
+		return "lru"
+	case EvictExpired:
+		return "expired"
+	case EvictManual:
+		return "manual"
+	case EvictReplaced:
+		return "replaced"
+	default:
+		return "unknown"
+	}
+}
+
+// NoExpiration is the TTL that stores an InMemoryStorage entry until it is
+// evicted by size limits or removed explicitly. Negative TTLs are treated
+// the same way.
+const NoExpiration time.Duration = 0
+
+// eviction records an entry removal pending delivery to the OnEvict hook.
+type eviction struct {
+	key    string
+	value  any
+	reason EvictReason
 }
 
 // entryPool is a sync.Pool for recycling entryStorage instances.
@@ -31,39 +86,208 @@ var entryPool = sync.Pool{
 // InMemoryStorage implements an LRU (Least Recently Used) cache with TTL support.
 // It maintains items in a doubly-linked list for O(1) access and eviction,
 // with a map for O(1) lookups. Thread-safe with fine-grained locking.
+//
+// Entries set with a TTL of NoExpiration (0) are permanent: neither reads
+// nor the cleanup loop ever expire them, and they only leave the cache
+// through LRU eviction under MaxEntries or MaxBytes, Delete, Reset, or a
+// later Set with a TTL.
+//
+// The exported type is a thin handle around the cache state. The background
+// cleanup goroutine only references the inner state, so a storage that is
+// dropped without calling Stop can still be garbage collected; a finalizer
+// then stops the goroutine. Calling Stop or Close remains the primary,
+// deterministic way to release it.
 type InMemoryStorage struct {
-	mu           sync.RWMutex             // Protects concurrent access to the cache
-	items        map[string]*entryStorage // Hash table for key lookups
-	head         *entryStorage            // Most recently used item (front of LRU list)
-	tail         *entryStorage            // Least recently used item (back of LRU list)
-	maxSize      int                      // Maximum number of items cache can hold
-	curSize      int                      // Current number of items in cache
-	ttlCheck     time.Duration            // Interval for periodic TTL cleanup
-	stopCh       chan struct{}            // Channel to signal background cleanup stop
-	creationTime time.Time                // Cache creation time for TTL calculations
+	*inMemoryStore
+}
+
+// inMemoryStore holds the cache state shared by InMemoryStorage and its
+// cleanup goroutine.
+type inMemoryStore struct {
+	mu       sync.RWMutex                   // Protects concurrent access to the cache
+	items    map[string]*entryStorage       // Hash table for key lookups
+	head     *entryStorage                  // Most recently used item (front of LRU list)
+	tail     *entryStorage                  // Least recently used item (back of LRU list)
+	maxSize  int                            // Maximum number of items cache can hold
+	curSize  int                            // Current number of items in cache
+	maxBytes int                            // Byte budget for all values (0 = count limit only)
+	curBytes int                            // Approximate bytes held by values
+	ttlCheck time.Duration                  // Interval for periodic TTL cleanup
+	stopCh   chan struct{}                  // Channel to signal background cleanup stop
+	stopOnce sync.Once                      // Makes Stop idempotent
+	clock    clock                          // Time source for expiry and cleanup ticks
+	maxValue int                            // Maximum size of a []byte or string value (0 = unlimited)
+	onEvict  func(string, any, EvictReason) // Optional eviction hook
+	pending  []eviction                     // Evictions to report once the lock is released
+	policy   EvictionPolicy                 // Recency tracking on reads
+	writes   uint64                         // Values written so far; stamps entry versions
 }
 
 // NewInMemoryStorage creates and initializes a new LRU cache with TTL.
 // The cache starts a background goroutine for periodic expiration checks.
 // maxSize determines cache capacity; ttlCheck controls TTL cleanup frequency.
+// Use NewInMemoryStorageWithConfig for the remaining tunables.
 func NewInMemoryStorage(maxSize int, ttlCheck time.Duration) *InMemoryStorage {
-	st := &InMemoryStorage{
-		items:        make(map[string]*entryStorage),
-		maxSize:      maxSize,
-		ttlCheck:     ttlCheck,
-		stopCh:       make(chan struct{}),
-		creationTime: time.Now(),
+	return NewInMemoryStorageWithConfig(StorageConfig{MaxEntries: maxSize, CleanupInterval: ttlCheck})
+}
+
+// newInMemoryStorageWithClock creates an InMemoryStorage driven by the given clock.
+// Tests use it to inject a fake clock.
+func newInMemoryStorageWithClock(maxSize int, ttlCheck time.Duration, clk clock) *InMemoryStorage {
+	return NewInMemoryStorageWithConfig(StorageConfig{MaxEntries: maxSize, CleanupInterval: ttlCheck, clock: clk})
+}
+
+// SetOnEvict installs a hook that is called whenever an entry is removed
+// through LRU eviction, expiry, Delete, or replacement by Set. The hook runs
+// after the cache lock is released, so it may safely call back into the cache.
+// Passing nil removes the hook.
+func (s *inMemoryStore) SetOnEvict(fn func(key string, value any, reason EvictReason)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onEvict = fn
+}
+
+// SetMaxValueBytes limits the size of []byte and string values accepted by Set.
+// Larger values are rejected with ErrValueTooLarge. Other value types are not
+// measured. A non-positive limit disables the check.
+func (s *inMemoryStore) SetMaxValueBytes(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxValue = n
+}
+
+// SetMaxBytes sets a byte budget for the cache in addition to the item limit.
+// Value sizes are estimated from their decoded in-memory form (see Sizer), and
+// least recently used entries are evicted until the total fits the budget.
+// Values larger than the whole budget are rejected with ErrValueTooLarge.
+// A non-positive budget disables byte accounting.
+//
+// Entries stored before the budget was configured are sized without holding
+// the write lock, so readers are not stalled by the estimation; only entries
+// written in the meantime are sized again under the lock.
+func (s *inMemoryStore) SetMaxBytes(n int) {
+	var pending []sizedEntry
+	if n > 0 {
+		s.mu.RLock()
+		if s.maxBytes <= 0 {
+			pending = make([]sizedEntry, 0, len(s.items))
+			for _, e := range s.items {
+				pending = append(pending, sizedEntry{e: e, version: e.version, value: e.value})
+			}
+		}
+		s.mu.RUnlock()
+		for i := range pending {
+			pending[i].size = estimateSize(pending[i].value)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.unlockAndNotify()
+
+	wasAccounting := s.maxBytes > 0
+	s.maxBytes = n
+	if n <= 0 || wasAccounting {
+		s.evictOverflow()
+		return
 	}
-	go st.cleanupLoop() // Start background cleanup goroutine
-	return st
+
+	// Size entries stored before the budget was configured
+	sized := make(map[*entryStorage]int, len(pending))
+	for _, p := range pending {
+		if p.e.version == p.version {
+			sized[p.e] = p.size
+		}
+	}
+	s.curBytes = 0
+	for _, e := range s.items {
+		size, ok := sized[e]
+		if !ok {
+			size = estimateSize(e.value) // Written while the others were sized
+		}
+		e.size = size
+		s.curBytes += size
+	}
+	s.evictOverflow()
+}
+
+// sizedEntry is an entry with the version and value it held when its size
+// was estimated. An entry whose version changed since, including a pooled
+// entry reused for another key, is sized again.
+type sizedEntry struct {
+	e       *entryStorage
+	version uint64
+	value   any
+	size    int
+}
+
+// budget returns the byte budget set with SetMaxBytes.
+func (s *inMemoryStore) budget() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.maxBytes
+}
+
+// Len returns the number of entries in the cache, including expired entries
+// not yet removed by the cleanup loop.
+func (s *inMemoryStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.curSize
+}
+
+// Bytes returns the approximate number of bytes held by cached values.
+// It is only tracked while a byte budget is set.
+func (s *inMemoryStore) Bytes() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.curBytes
 }
 
 // Get retrieves a value from the cache by key.
 // If the key exists and hasn't expired, it's moved to the front (most recently used).
 // Returns ErrNotFound if key doesn't exist or has expired.
-func (s *InMemoryStorage) Get(key string) (any, error) {
+// A []byte value is returned as a copy, so callers cannot corrupt the stored bytes.
+func (s *inMemoryStore) Get(key string) (any, error) {
+	val, err := s.lookup(key)
+	if b, ok := val.([]byte); ok {
+		return bytes.Clone(b), err
+	}
+	return val, err
+}
+
+// GetRaw returns a copy of the []byte value stored under key. It returns
+// ErrNotFound for missing or expired keys and ErrNotBytes for other values.
+func (s *inMemoryStore) GetRaw(key string) ([]byte, error) {
+	b, err := s.GetRawUnsafe(key)
+	return bytes.Clone(b), err
+}
+
+// GetRawUnsafe is like GetRaw but returns the stored slice itself, saving
+// the copy. The caller must treat the result as read-only: modifying or
+// appending to it changes what later readers see.
+func (s *inMemoryStore) GetRawUnsafe(key string) ([]byte, error) {
+	val, err := s.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := val.([]byte)
+	if !ok {
+		return nil, ErrNotBytes
+	}
+	return b, nil
+}
+
+// lookup returns the stored value for key without copying it, updating its
+// LRU position and dropping it if expired.
+func (s *inMemoryStore) lookup(key string) (any, error) {
+	if s.policy == EvictionClock {
+		if val, done, err := s.peek(key); done {
+			return val, err
+		}
+	}
+
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlockAndNotify()
 
 	e, ok := s.items[key]
 	if !ok {
@@ -71,8 +295,8 @@ func (s *InMemoryStorage) Get(key string) (any, error) {
 	}
 
 	// Check if entry has expired based on TTL
-	if e.expiresIn > 0 && time.Since(s.creationTime) > e.expiresIn {
-		s.removeElement(e) // Remove expired entry
+	if e.expired(s.clock.Now()) {
+		s.removeElement(e, EvictExpired) // Remove expired entry
 		return nil, ErrNotFound
 	}
 
@@ -83,26 +307,69 @@ func (s *InMemoryStorage) Get(key string) (any, error) {
 // Set adds or updates a key-value pair in the cache.
 // If key already exists, updates its value and TTL, moving it to front.
 // If cache is at capacity, evicts the least recently used item.
-// exp is TTL duration from cache creation time; 0 means no expiration.
-func (s *InMemoryStorage) Set(key string, val any, exp time.Duration) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// exp is TTL duration from the moment of the call; NoExpiration (0) keeps
+// the entry until it is evicted or deleted.
+func (s *inMemoryStore) Set(key string, val any, exp time.Duration) error {
+	return s.SetWithSize(key, val, -1, exp)
+}
+
+// SetWithSize is like Set but takes the value's in-memory size in bytes,
+// avoiding reflection-based estimation when the caller already knows it.
+// A negative size means unknown; it is then estimated if a byte budget is
+// set, before the write lock is taken, so readers are not stalled by it.
+func (s *inMemoryStore) SetWithSize(key string, val any, size int, exp time.Duration) error {
+	for {
+		if size < 0 && s.budget() > 0 {
+			size = estimateSize(val)
+		}
+		s.mu.Lock()
+		if size >= 0 || s.maxBytes <= 0 {
+			break
+		}
+		// A budget was set concurrently; estimate again without the lock
+		s.mu.Unlock()
+	}
+	defer s.unlockAndNotify()
+
+	// Reject oversized values before touching the cache
+	if s.maxValue > 0 && valueSize(val) > s.maxValue {
+		return ErrValueTooLarge
+	}
+
+	// Size accounting is only paid for when a byte budget is configured
+	if s.maxBytes <= 0 {
+		size = 0
+	} else if size > s.maxBytes {
+		return ErrValueTooLarge
+	}
+
+	expiresAt := s.expiresAt(exp)
 
 	// Update existing entry
 	if old, ok := s.items[key]; ok {
+		s.notify(old.key, old.value, EvictReplaced)
+		s.writes++
 		old.value = val
-		old.expiresIn = exp
+		old.version = s.writes
+		old.expiresAt = expiresAt
+		s.curBytes += size - old.size
+		old.size = size
 		s.moveToFront(old) // Update LRU position
+		s.evictOverflow()
 		return nil
 	}
 
 	// Create new entry (reuse from pool if available)
 	ent := entryPool.Get().(*entryStorage)
 	ent.key = key
+	s.writes++
 	ent.value = val
-	ent.expiresIn = exp
+	ent.version = s.writes
+	ent.expiresAt = expiresAt
+	ent.size = size
 	ent.prev = nil
 	ent.next = nil
+	ent.referenced.Store(false)
 
 	// Add to front of LRU list
 	ent.next = s.head
@@ -116,52 +383,119 @@ func (s *InMemoryStorage) Set(key string, val any, exp time.Duration) error {
 
 	s.items[key] = ent
 	s.curSize++
+	s.curBytes += size
 
-	// Evict LRU item if capacity exceeded
-	if s.curSize > s.maxSize {
-		s.evict()
-	}
+	// Evict LRU items while count or byte capacity is exceeded
+	s.evictOverflow()
 
 	return nil
 }
 
 // Delete removes a key-value pair from the cache.
 // Returns ErrNotFound if the key doesn't exist.
-func (s *InMemoryStorage) Delete(key string) error {
+func (s *inMemoryStore) Delete(key string) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlockAndNotify()
 
 	e, ok := s.items[key]
 	if !ok {
 		return ErrNotFound
 	}
-	s.removeElement(e)
+	s.removeElement(e, EvictManual)
 	return nil
 }
 
+// Keys returns a snapshot of all live keys, ordered from most to least
+// recently used. Expired entries awaiting cleanup are omitted. The LRU order
+// is not changed.
+func (s *inMemoryStore) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.clock.Now()
+	keys := make([]string, 0, s.curSize)
+	for e := s.head; e != nil; e = e.next {
+		if !e.expired(now) {
+			keys = append(keys, e.key)
+		}
+	}
+	return keys
+}
+
+// Range calls fn for every live entry, from most to least recently used, until
+// fn returns false. remainingTTL is the time left before the entry expires, or
+// zero for entries without a TTL. Range does not change the LRU order.
+//
+// The store is read-locked for the whole iteration, so fn must not call back
+// into the store (doing so deadlocks on writes) and should return quickly.
+func (s *inMemoryStore) Range(fn func(key string, value any, remainingTTL time.Duration) bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.clock.Now()
+	for e := s.head; e != nil; e = e.next {
+		if e.expired(now) {
+			continue
+		}
+		var ttl time.Duration
+		if !e.expiresAt.IsZero() {
+			ttl = e.expiresAt.Sub(now)
+		}
+		if !fn(e.key, e.value, ttl) {
+			return
+		}
+	}
+}
+
 // Reset clears all entries from the cache and resets its state.
-// Resets creation time for TTL calculations.
-func (s *InMemoryStorage) Reset() {
+func (s *inMemoryStore) Reset() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	s.items = make(map[string]*entryStorage)
 	s.head, s.tail = nil, nil
 	s.curSize = 0
-	s.creationTime = time.Now()
+	s.curBytes = 0
 }
 
 // Close stops background cleanup and releases resources.
 // Implements io.Closer interface for use with defer and resource management.
-func (s *InMemoryStorage) Close() {
+func (s *inMemoryStore) Close() {
 	s.Stop()
 }
 
 // -------- Internal Methods (not exported) --------
 
+// expired reports whether the entry has a TTL and it has elapsed at now.
+func (e *entryStorage) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+// valueSize reports the byte length of measurable values ([]byte and string).
+// Other types report zero and are never rejected by the size limit.
+func valueSize(val any) int {
+	switch v := val.(type) {
+	case []byte:
+		return len(v)
+	case string:
+		return len(v)
+	default:
+		return 0
+	}
+}
+
+// expiresAt converts a relative TTL into an absolute deadline using the clock.
+// A non-positive TTL yields the zero time, meaning the entry never expires.
+func (s *inMemoryStore) expiresAt(exp time.Duration) time.Time {
+	if exp <= 0 {
+		return time.Time{}
+	}
+	return s.clock.Now().Add(exp)
+}
+
 // pushFront inserts an entry at the front of the LRU list.
 // Updates head and tail pointers accordingly.
-func (s *InMemoryStorage) pushFront(e *entryStorage) {
+func (s *inMemoryStore) pushFront(e *entryStorage) {
 	e.prev = nil
 	e.next = s.head
 	if s.head != nil {
@@ -175,7 +509,7 @@ func (s *InMemoryStorage) pushFront(e *entryStorage) {
 
 // moveToFront moves an existing entry to the front of LRU list.
 // If entry is already at front, does nothing.
-func (s *InMemoryStorage) moveToFront(e *entryStorage) {
+func (s *inMemoryStore) moveToFront(e *entryStorage) {
 	if e == s.head {
 		return
 	}
@@ -185,7 +519,7 @@ func (s *InMemoryStorage) moveToFront(e *entryStorage) {
 
 // remove extracts an entry from the LRU list without deleting from map.
 // Maintains list integrity by updating neighboring nodes' pointers.
-func (s *InMemoryStorage) remove(e *entryStorage) {
+func (s *inMemoryStore) remove(e *entryStorage) {
 	if e.prev != nil {
 		e.prev.next = e.next
 	} else {
@@ -201,48 +535,92 @@ func (s *InMemoryStorage) remove(e *entryStorage) {
 
 // removeElement completely removes an entry from cache.
 // Removes from LRU list, deletes from map, returns entry to pool.
-func (s *InMemoryStorage) removeElement(e *entryStorage) {
+// The removal is queued for the OnEvict hook with the given reason.
+func (s *inMemoryStore) removeElement(e *entryStorage, reason EvictReason) {
+	s.notify(e.key, e.value, reason)
 	s.remove(e)
 	delete(s.items, e.key)
 	s.curSize--
+	s.curBytes -= e.size
 	entryPool.Put(e) // Recycle for future use
 }
 
 // evict removes the least recently used item (tail) from cache.
-// Called when cache exceeds its maximum capacity.
-func (s *InMemoryStorage) evict() {
+// Called when cache exceeds its maximum capacity. Under EvictionClock, keep
+// (the entry just written) is passed over like a referenced entry.
+func (s *inMemoryStore) evict(keep *entryStorage) {
 	if s.tail == nil {
 		return
 	}
-	s.removeElement(s.tail)
+	if s.policy == EvictionClock {
+		s.secondChance(keep)
+	}
+	s.removeElement(s.tail, EvictLRU)
+}
+
+// evictOverflow evicts least recently used items until both the item limit
+// and the byte budget (if any) are satisfied. The most recently used entry is
+// never evicted for bytes, since Set already rejects values above the budget.
+func (s *inMemoryStore) evictOverflow() {
+	newest := s.head
+	if s.curSize > s.maxSize {
+		s.evict(newest)
+	}
+	for s.maxBytes > 0 && s.curBytes > s.maxBytes && s.tail != s.head {
+		s.evict(newest)
+	}
+}
+
+// notify queues an eviction for the OnEvict hook. Must be called with s.mu held.
+func (s *inMemoryStore) notify(key string, value any, reason EvictReason) {
+	if s.onEvict != nil {
+		s.pending = append(s.pending, eviction{key: key, value: value, reason: reason})
+	}
+}
+
+// unlockAndNotify releases s.mu and then delivers queued evictions to the
+// OnEvict hook, so the hook never runs while the cache is locked.
+func (s *inMemoryStore) unlockAndNotify() {
+	fn, pending := s.onEvict, s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	for _, ev := range pending {
+		fn(ev.key, ev.value, ev.reason)
+	}
 }
 
 // cleanupLoop runs in a background goroutine, periodically removing expired entries.
 // Uses a ticker to check TTL at configured intervals.
-func (s *InMemoryStorage) cleanupLoop() {
-	ticker := time.NewTicker(s.ttlCheck)
-	defer ticker.Stop()
+func (s *inMemoryStore) cleanupLoop(t ticker) {
+	defer t.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
-			s.mu.Lock()
-			now := time.Now()
-			elapsed := now.Sub(s.creationTime)
-			for _, e := range s.items {
-				if e.expiresIn > 0 && elapsed > e.expiresIn {
-					s.removeElement(e)
-				}
-			}
-			s.mu.Unlock()
+		case <-t.C():
+			s.removeExpired()
 		case <-s.stopCh:
 			return
 		}
 	}
 }
 
+// removeExpired deletes every entry whose TTL has elapsed.
+func (s *inMemoryStore) removeExpired() {
+	s.mu.Lock()
+	defer s.unlockAndNotify()
+
+	now := s.clock.Now()
+	for _, e := range s.items {
+		if e.expired(now) {
+			s.removeElement(e, EvictExpired)
+		}
+	}
+}
+
 // Stop signals the background cleanup loop to terminate.
-// Should be called before discarding the cache to prevent goroutine leaks.
-func (s *InMemoryStorage) Stop() {
-	close(s.stopCh)
+// Should be called before discarding the cache; the GC finalizer is only a fallback.
+// It is safe to call multiple times.
+func (s *inMemoryStore) Stop() {
+	s.stopOnce.Do(func() { close(s.stopCh) })
 }
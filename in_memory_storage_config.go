@@ -0,0 +1,58 @@
+package mysql
+
+import (
+	"math"
+	"runtime"
+	"time"
+)
+
+// defaultCleanupInterval is used when StorageConfig.CleanupInterval is not set.
+const defaultCleanupInterval = time.Minute
+
+// StorageConfig aggregates the tunables of an InMemoryStorage.
+// Zero values select the defaults noted on each field.
+type StorageConfig struct {
+	MaxEntries      int                                             // Maximum number of items (<= 0 = no item limit)
+	CleanupInterval time.Duration                                   // How often expired entries are swept (<= 0 = one minute)
+	MaxBytes        int                                             // Byte budget for all values, see SetMaxBytes (0 = count limit only)
+	MaxValueBytes   int                                             // Maximum size of a []byte or string value, see SetMaxValueBytes (0 = unlimited)
+	OnEvict         func(key string, value any, reason EvictReason) // Optional eviction hook, see SetOnEvict
+	Eviction        EvictionPolicy                                  // Recency tracking on reads (zero = EvictionLRU)
+
+	clock clock // Time source; nil uses the real clock. Set by tests.
+}
+
+// NewInMemoryStorageWithConfig creates an InMemoryStorage from cfg and starts
+// its background cleanup goroutine.
+func NewInMemoryStorageWithConfig(cfg StorageConfig) *InMemoryStorage {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = math.MaxInt
+	}
+	if cfg.CleanupInterval <= 0 {
+		cfg.CleanupInterval = defaultCleanupInterval
+	}
+	if cfg.clock == nil {
+		cfg.clock = realClock{}
+	}
+
+	core := &inMemoryStore{
+		items:    make(map[string]*entryStorage),
+		maxSize:  cfg.MaxEntries,
+		maxBytes: cfg.MaxBytes,
+		maxValue: cfg.MaxValueBytes,
+		ttlCheck: cfg.CleanupInterval,
+		stopCh:   make(chan struct{}),
+		clock:    cfg.clock,
+		onEvict:  cfg.OnEvict,
+		policy:   cfg.Eviction,
+	}
+	// The ticker is created before the cleanup goroutine starts so a fake
+	// clock observes it synchronously. The goroutine captures only core,
+	// never the handle, so the handle can become unreachable and trigger
+	// the finalizer below.
+	go core.cleanupLoop(cfg.clock.NewTicker(cfg.CleanupInterval))
+
+	st := &InMemoryStorage{inMemoryStore: core}
+	runtime.SetFinalizer(st, func(st *InMemoryStorage) { st.Stop() })
+	return st
+}
@@ -0,0 +1,79 @@
+package mysql
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStorageConfig_Defaults(t *testing.T) {
+	store := NewInMemoryStorageWithConfig(StorageConfig{})
+	defer store.Stop()
+
+	if store.maxSize != math.MaxInt {
+		t.Fatalf("expected no item limit, got %d", store.maxSize)
+	}
+	if store.ttlCheck != defaultCleanupInterval {
+		t.Fatalf("expected default cleanup interval, got %v", store.ttlCheck)
+	}
+	if store.maxBytes != 0 || store.maxValue != 0 || store.onEvict != nil {
+		t.Fatalf("expected optional limits to be disabled")
+	}
+}
+
+func TestStorageConfig_FieldsTakeEffect(t *testing.T) {
+	clk := newFakeClock()
+	var evicted []string
+	store := NewInMemoryStorageWithConfig(StorageConfig{
+		MaxEntries:      2,
+		CleanupInterval: 5 * time.Second,
+		MaxValueBytes:   8,
+		OnEvict: func(key string, value any, reason EvictReason) {
+			evicted = append(evicted, key+":"+reason.String())
+		},
+		clock: clk,
+	})
+	defer store.Stop()
+
+	// MaxEntries and OnEvict
+	_ = store.Set("a", "1", time.Minute)
+	_ = store.Set("b", "2", time.Minute)
+	_ = store.Set("c", "3", time.Minute)
+	if len(evicted) != 1 || evicted[0] != "a:lru" {
+		t.Fatalf("expected LRU eviction of a, got %v", evicted)
+	}
+
+	// MaxValueBytes
+	if err := store.Set("big", strings.Repeat("x", 9), time.Minute); !errors.Is(err, ErrValueTooLarge) {
+		t.Fatalf("expected ErrValueTooLarge, got %v", err)
+	}
+
+	// Clock and CleanupInterval
+	if len(clk.tickers) != 1 {
+		t.Fatalf("expected cleanup ticker on the injected clock, got %d", len(clk.tickers))
+	}
+	if store.ttlCheck != 5*time.Second {
+		t.Fatalf("expected cleanup interval 5s, got %v", store.ttlCheck)
+	}
+	_ = store.Set("short", "s", time.Second)
+	clk.Advance(2 * time.Second)
+	if _, err := store.Get("short"); err != ErrNotFound {
+		t.Fatalf("expected expiry through injected clock, got %v", err)
+	}
+}
+
+func TestStorageConfig_MaxBytes(t *testing.T) {
+	store := NewInMemoryStorageWithConfig(StorageConfig{MaxBytes: 100})
+	defer store.Stop()
+
+	_ = store.SetWithSize("a", "1", 60, time.Minute)
+	_ = store.SetWithSize("b", "2", 60, time.Minute)
+	if _, err := store.Get("a"); err != ErrNotFound {
+		t.Fatalf("expected a to be evicted by the byte budget, got %v", err)
+	}
+	if store.Bytes() != 60 {
+		t.Fatalf("expected 60 bytes tracked, got %d", store.Bytes())
+	}
+}
@@ -0,0 +1,168 @@
+package mysql
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// dumpMagic identifies the InMemoryStorage dump format and its version.
+var dumpMagic = [4]byte{'I', 'M', 'S', '1'}
+
+// Value kinds stored in a dump.
+const (
+	dumpKindBytes  byte = 0
+	dumpKindString byte = 1
+)
+
+// maxDumpField bounds key and value lengths accepted by Load, so a corrupt
+// length prefix cannot trigger a huge allocation.
+const maxDumpField = 1 << 30
+
+// errBadDump is returned by Load when the input is not a valid dump.
+var errBadDump = errors.New("mysql: invalid in-memory storage dump")
+
+// dumpEntry is a snapshot of one live entry taken for Dump.
+type dumpEntry struct {
+	key   string
+	value any
+	ttl   time.Duration
+}
+
+// Dump writes every live entry to w together with its remaining TTL, from
+// least to most recently used, so that Load restores the same LRU order.
+// Expired entries are skipped. Only []byte and string values can be dumped;
+// any other value type makes Dump fail before anything is written.
+//
+// Each entry is framed as: uvarint key length, key, kind byte, uvarint value
+// length, value, varint remaining TTL in nanoseconds (0 = no expiry), after a
+// 4-byte format header.
+func (s *inMemoryStore) Dump(w io.Writer) error {
+	entries, err := s.snapshot()
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(dumpMagic[:]); err != nil {
+		return err
+	}
+
+	var scratch [binary.MaxVarintLen64]byte
+	for _, e := range entries {
+		bw.Write(scratch[:binary.PutUvarint(scratch[:], uint64(len(e.key)))])
+		bw.WriteString(e.key)
+
+		switch v := e.value.(type) {
+		case []byte:
+			bw.WriteByte(dumpKindBytes)
+			bw.Write(scratch[:binary.PutUvarint(scratch[:], uint64(len(v)))])
+			bw.Write(v)
+		case string:
+			bw.WriteByte(dumpKindString)
+			bw.Write(scratch[:binary.PutUvarint(scratch[:], uint64(len(v)))])
+			bw.WriteString(v)
+		}
+
+		// bufio.Writer keeps the first error, which Flush reports below
+		bw.Write(scratch[:binary.PutVarint(scratch[:], int64(e.ttl))])
+	}
+	return bw.Flush()
+}
+
+// snapshot collects live entries from least to most recently used.
+func (s *inMemoryStore) snapshot() ([]dumpEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.clock.Now()
+	entries := make([]dumpEntry, 0, s.curSize)
+	for e := s.tail; e != nil; e = e.prev {
+		if e.expired(now) {
+			continue
+		}
+		switch e.value.(type) {
+		case []byte, string:
+		default:
+			return nil, fmt.Errorf("mysql: cannot dump value of type %T for key %q", e.value, e.key)
+		}
+		var ttl time.Duration
+		if !e.expiresAt.IsZero() {
+			if ttl = e.expiresAt.Sub(now); ttl <= 0 {
+				continue // Expires this instant; a zero TTL would mean "forever"
+			}
+		}
+		entries = append(entries, dumpEntry{key: e.key, value: e.value, ttl: ttl})
+	}
+	return entries, nil
+}
+
+// Load reads entries written by Dump and stores them with their remaining
+// TTL, in the dumped order, so the most recently used entry ends up at the
+// front. Existing entries with the same keys are replaced. Entries that were
+// restored before a malformed record is found are kept.
+func (s *inMemoryStore) Load(r io.Reader) error {
+	br := bufio.NewReader(r)
+
+	var magic [4]byte
+	if _, err := io.ReadFull(br, magic[:]); err != nil || magic != dumpMagic {
+		return errBadDump
+	}
+
+	for {
+		key, err := readDumpBytes(br)
+		if err == io.EOF {
+			return nil // Clean end of dump
+		}
+		if err != nil {
+			return err
+		}
+
+		kind, err := br.ReadByte()
+		if err != nil {
+			return errBadDump
+		}
+		raw, err := readDumpBytes(br)
+		if err != nil {
+			return errBadDump
+		}
+		ttl, err := binary.ReadVarint(br)
+		if err != nil {
+			return errBadDump
+		}
+
+		var value any
+		switch kind {
+		case dumpKindBytes:
+			value = raw
+		case dumpKindString:
+			value = string(raw)
+		default:
+			return errBadDump
+		}
+
+		if err := s.Set(string(key), value, time.Duration(ttl)); err != nil {
+			return err
+		}
+	}
+}
+
+// readDumpBytes reads a uvarint length-prefixed byte string. It returns
+// io.EOF only when the input ends exactly before the length prefix.
+func readDumpBytes(br *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(br)
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil || n > maxDumpField {
+		return nil, errBadDump
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(br, buf); err != nil {
+		return nil, errBadDump
+	}
+	return buf, nil
+}
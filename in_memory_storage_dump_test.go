@@ -0,0 +1,102 @@
+package mysql
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDumpLoadRoundTrip(t *testing.T) {
+	clk := newFakeClock()
+	src := newInMemoryStorageWithClock(10, time.Minute, clk)
+	defer src.Stop()
+
+	_ = src.Set("a", []byte("alpha"), time.Hour)
+	_ = src.Set("b", "beta", 0)
+	_ = src.Set("c", []byte{}, 30*time.Second)
+	_ = src.Set("expired", "x", time.Millisecond)
+	_, _ = src.Get("a") // Make "a" the most recently used
+	clk.Advance(10 * time.Second)
+
+	var buf bytes.Buffer
+	if err := src.Dump(&buf); err != nil {
+		t.Fatalf("Dump() error: %v", err)
+	}
+
+	dstClock := newFakeClock()
+	dst := newInMemoryStorageWithClock(10, time.Minute, dstClock)
+	defer dst.Stop()
+	if err := dst.Load(&buf); err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if got, want := dst.Keys(), []string{"a", "c", "b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("restored LRU order = %v, want %v", got, want)
+	}
+
+	ttls := map[string]time.Duration{}
+	values := map[string]any{}
+	dst.Range(func(key string, value any, ttl time.Duration) bool {
+		ttls[key], values[key] = ttl, value
+		return true
+	})
+	if !bytes.Equal(values["a"].([]byte), []byte("alpha")) || values["b"] != "beta" || len(values["c"].([]byte)) != 0 {
+		t.Fatalf("unexpected restored values: %v", values)
+	}
+	if ttls["a"] != time.Hour-10*time.Second || ttls["c"] != 20*time.Second || ttls["b"] != 0 {
+		t.Fatalf("unexpected restored TTLs: %v", ttls)
+	}
+
+	// Restored TTLs keep counting down
+	dstClock.Advance(21 * time.Second)
+	if _, err := dst.Get("c"); err != ErrNotFound {
+		t.Fatalf("expected restored entry to expire, got %v", err)
+	}
+}
+
+func TestDumpUnsupportedValue(t *testing.T) {
+	store := NewInMemoryStorage(10, time.Minute)
+	defer store.Stop()
+	_ = store.Set("typed", &struct{}{}, 0)
+
+	var buf bytes.Buffer
+	err := store.Dump(&buf)
+	if err == nil || !strings.Contains(err.Error(), `"typed"`) {
+		t.Fatalf("expected unsupported value error, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("expected nothing to be written on error")
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	store := NewInMemoryStorage(10, time.Minute)
+	defer store.Stop()
+
+	if err := store.Load(strings.NewReader("nope")); !errors.Is(err, errBadDump) {
+		t.Fatalf("expected errBadDump for bad header, got %v", err)
+	}
+
+	// Truncate a valid dump in the middle of a record
+	src := NewInMemoryStorage(10, time.Minute)
+	defer src.Stop()
+	_ = src.Set("k", "value", 0)
+	var buf bytes.Buffer
+	_ = src.Dump(&buf)
+	truncated := buf.Bytes()[:buf.Len()-3]
+	if err := store.Load(bytes.NewReader(truncated)); !errors.Is(err, errBadDump) {
+		t.Fatalf("expected errBadDump for truncated dump, got %v", err)
+	}
+
+	// An empty dump is valid
+	var empty bytes.Buffer
+	fresh := NewInMemoryStorage(10, time.Minute)
+	defer fresh.Stop()
+	_ = fresh.Dump(&empty)
+	if err := store.Load(&empty); err != nil {
+		t.Fatalf("expected empty dump to load, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package mysql
+
+// EvictionPolicy selects how an InMemoryStorage tracks recency for eviction.
+type EvictionPolicy int
+
+const (
+	// EvictionLRU moves an entry to the front of the LRU list on every hit.
+	// Eviction is exact, but every read takes the write lock, so concurrent
+	// readers are serialized.
+	EvictionLRU EvictionPolicy = iota
+
+	// EvictionClock (second chance) only marks an entry as referenced on a
+	// hit, under the read lock, so concurrent reads proceed in parallel.
+	// When room is needed, referenced entries at the LRU end are cleared and
+	// moved to the front instead of being evicted, approximating LRU. Keys
+	// and Range then list entries in approximate recency order.
+	EvictionClock
+)
+
+// String returns a human-readable name for the policy.
+func (p EvictionPolicy) String() string {
+	switch p {
+	case EvictionLRU:
+		return "lru"
+	case EvictionClock:
+		return "clock"
+	default:
+		return "unknown"
+	}
+}
+
+// peek serves a hit under the read lock for EvictionClock, marking the entry
+// referenced. done is false for expired entries, which must be removed by
+// the write-locked path in lookup.
+func (s *inMemoryStore) peek(key string) (val any, done bool, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.items[key]
+	if !ok {
+		return nil, true, ErrNotFound
+	}
+	if e.expired(s.clock.Now()) {
+		return nil, false, nil
+	}
+	// Avoid the store when the bit is already set, keeping hot entries'
+	// cache lines shared between readers
+	if !e.referenced.Load() {
+		e.referenced.Store(true)
+	}
+	return e.value, true, nil
+}
+
+// secondChance moves referenced entries at the tail to the front, clearing
+// their bit, until the tail is an unreferenced entry to evict. keep is
+// passed over too, so a Set never evicts its own entry. Must be called with
+// s.mu held; after one full pass every bit is clear, so it performs at most
+// curSize moves.
+func (s *inMemoryStore) secondChance(keep *entryStorage) {
+	for n := s.curSize; n > 0; n-- {
+		e := s.tail
+		if e != keep && !e.referenced.Load() {
+			return
+		}
+		e.referenced.Store(false)
+		s.moveToFront(e)
+	}
+}
@@ -0,0 +1,170 @@
+package mysql
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newPolicyStorage(maxEntries int, policy EvictionPolicy) *InMemoryStorage {
+	return NewInMemoryStorageWithConfig(StorageConfig{MaxEntries: maxEntries, Eviction: policy})
+}
+
+func sortedKeys(s *InMemoryStorage) []string {
+	keys := s.Keys()
+	sort.Strings(keys)
+	return keys
+}
+
+func TestEvictionClock_MatchesLRUForSimpleAccess(t *testing.T) {
+	for _, policy := range []EvictionPolicy{EvictionLRU, EvictionClock} {
+		t.Run(policy.String(), func(t *testing.T) {
+			s := newPolicyStorage(3, policy)
+			defer s.Stop()
+
+			for _, k := range []string{"a", "b", "c"} {
+				_ = s.Set(k, k, 0)
+			}
+			_, _ = s.Get("a")
+			_ = s.Set("d", "d", 0)
+			_, _ = s.Get("a")
+			_, _ = s.Get("c")
+			_ = s.Set("e", "e", 0)
+
+			// b and then d were the least recently used
+			if got := sortedKeys(s); !reflect.DeepEqual(got, []string{"a", "c", "e"}) {
+				t.Fatalf("unexpected survivors: %v", got)
+			}
+		})
+	}
+}
+
+func TestEvictionClock_SetNeverEvictsItsOwnEntry(t *testing.T) {
+	s := newPolicyStorage(2, EvictionClock)
+	defer s.Stop()
+
+	_ = s.Set("a", "a", 0)
+	_ = s.Set("b", "b", 0)
+	_, _ = s.Get("a")
+	_, _ = s.Get("b")
+	_ = s.Set("c", "c", 0)
+
+	if got := sortedKeys(s); !reflect.DeepEqual(got, []string{"b", "c"}) {
+		t.Fatalf("expected the new entry and one referenced entry to survive, got %v", got)
+	}
+
+	// Same under a byte budget, where several entries may be evicted at once
+	b := NewInMemoryStorageWithConfig(StorageConfig{MaxBytes: 3 << 10, Eviction: EvictionClock})
+	defer b.Stop()
+	for i := 0; i < 3; i++ {
+		_ = b.SetWithSize("k"+strconv.Itoa(i), i, 1<<10, 0)
+		_, _ = b.Get("k" + strconv.Itoa(i))
+	}
+	_ = b.SetWithSize("big", "big", 2<<10, 0)
+	if _, err := b.Get("big"); err != nil {
+		t.Fatalf("expected the new entry to survive byte eviction: %v", err)
+	}
+}
+
+func TestEvictionClock_KeepsHotSetUnderChurn(t *testing.T) {
+	const size, hot = 100, 50
+	s := newPolicyStorage(size, EvictionClock)
+	defer s.Stop()
+
+	for i := 0; i < size; i++ {
+		_ = s.Set("k"+strconv.Itoa(i), i, 0)
+	}
+	for round := 0; round < 5; round++ {
+		for i := 0; i < hot; i++ {
+			if _, err := s.Get("k" + strconv.Itoa(i)); err != nil {
+				t.Fatalf("round %d: hot key k%d was evicted", round, i)
+			}
+		}
+		// A scan of cold keys displaces the entries that were not read
+		for i := 0; i < size-hot; i++ {
+			_ = s.Set("cold"+strconv.Itoa(round)+"-"+strconv.Itoa(i), i, 0)
+		}
+	}
+	if s.Len() != size {
+		t.Fatalf("expected %d entries, got %d", size, s.Len())
+	}
+}
+
+func TestEvictionClock_ExpiredEntriesAreRemovedOnRead(t *testing.T) {
+	clk := newFakeClock()
+	s := NewInMemoryStorageWithConfig(StorageConfig{MaxEntries: 10, Eviction: EvictionClock, clock: clk})
+	defer s.Stop()
+
+	var evicted []EvictReason
+	s.SetOnEvict(func(_ string, _ any, reason EvictReason) { evicted = append(evicted, reason) })
+
+	_ = s.Set("k", "v", time.Second)
+	if v, err := s.Get("k"); err != nil || v != "v" {
+		t.Fatalf("expected a hit, got %v (%v)", v, err)
+	}
+	clk.Advance(2 * time.Second)
+	if _, err := s.Get("k"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after expiry, got %v", err)
+	}
+	if s.Len() != 0 || !reflect.DeepEqual(evicted, []EvictReason{EvictExpired}) {
+		t.Fatalf("expected the expired entry to be removed, len %d, hooks %v", s.Len(), evicted)
+	}
+	if _, err := s.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a missing key, got %v", err)
+	}
+}
+
+func TestEvictionClock_ConcurrentReadsAndWrites(t *testing.T) {
+	s := newPolicyStorage(64, EvictionClock)
+	defer s.Stop()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := "k" + strconv.Itoa((g*31+i)%128)
+				if i%4 == 0 {
+					_ = s.Set(key, i, 0)
+				} else {
+					_, _ = s.Get(key)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if s.Len() > 64 {
+		t.Fatalf("expected at most 64 entries, got %d", s.Len())
+	}
+}
+
+// BenchmarkGetParallel compares read throughput of the eviction policies
+// under concurrent readers, e.g. go test -bench GetParallel -cpu 1,8.
+func BenchmarkGetParallel(b *testing.B) {
+	for _, policy := range []EvictionPolicy{EvictionLRU, EvictionClock} {
+		b.Run(policy.String(), func(b *testing.B) {
+			s := newPolicyStorage(100000, policy)
+			defer s.Stop()
+
+			keys := make([]string, 10000)
+			for i := range keys {
+				keys[i] = "key" + strconv.Itoa(i)
+				_ = s.Set(keys[i], "value", time.Minute)
+			}
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					_, _ = s.Get(keys[i%len(keys)])
+					i++
+				}
+			})
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package mysql
 
 import (
+	"reflect"
+	"runtime"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 )
@@ -38,19 +41,23 @@ func TestSetGet(t *testing.T) {
 // TestGetExpired verifies that expired items are not returned by Get.
 // Tests TTL expiration logic when Get is called after item expiration.
 func TestGetExpired(t *testing.T) {
-	store := NewInMemoryStorage(1024, 5*time.Millisecond)
+	clk := newFakeClock()
+	store := newInMemoryStorageWithClock(1024, time.Minute, clk)
 	defer store.Stop()
 
 	key := "foo"
 	val := "bar"
 
-	// Set item with very short TTL (5ms)
 	_ = store.Set(key, val, 5*time.Millisecond)
 
-	// Wait longer than TTL to ensure expiration
-	time.Sleep(15 * time.Millisecond) // ждём пока протухнет
+	// Entry is still visible right up to its deadline
+	clk.Advance(5 * time.Millisecond)
+	if _, err := store.Get(key); err != nil {
+		t.Fatalf("Expected key to be alive at its deadline, got %v", err)
+	}
 
 	// Attempt to get expired item
+	clk.Advance(time.Millisecond)
 	_, err := store.Get(key)
 	if err != ErrNotFound {
 		t.Errorf("Expected ErrNotFound for expired key, got %v", err)
@@ -61,25 +68,81 @@ func TestGetExpired(t *testing.T) {
 // removes expired items from the storage map.
 // This tests the periodic cleanup mechanism rather than on-access expiration.
 func TestGetExpiredByCleanup(t *testing.T) {
-	store := NewInMemoryStorage(1024, 5*time.Millisecond)
+	clk := newFakeClock()
+	store := newInMemoryStorageWithClock(1024, time.Minute, clk)
 	defer store.Stop()
 
-	key := "foo"
-	val := "bar"
-
-	// Set item with short TTL
-	_ = store.Set(key, val, 5*time.Millisecond)
+	_ = store.Set("short", "bar", 5*time.Millisecond)
+	_ = store.Set("long", "bar", time.Hour)
+	_ = store.Set("forever", "bar", 0)
 
-	// Wait for cleanup goroutine to run (TTL + some margin)
-	time.Sleep(15 * time.Millisecond) // ждём пока cleanup удалит
+	clk.Advance(10 * time.Millisecond)
+	clk.Tick()
 
 	// Directly check storage map (requires locking)
 	store.mu.Lock()
-	_, exists := store.items[key]
+	_, short := store.items["short"]
+	_, long := store.items["long"]
+	_, forever := store.items["forever"]
 	store.mu.Unlock()
 
-	if exists {
-		t.Errorf("Expected key to be removed by cleanup")
+	if short {
+		t.Errorf("Expected expired key to be removed by cleanup")
+	}
+	if !long || !forever {
+		t.Errorf("Expected live keys to survive cleanup")
+	}
+}
+
+// TestTTLMeasuredFromSet verifies that TTL counts from the Set call,
+// not from the creation of the storage.
+func TestTTLMeasuredFromSet(t *testing.T) {
+	clk := newFakeClock()
+	store := newInMemoryStorageWithClock(1024, time.Minute, clk)
+	defer store.Stop()
+
+	clk.Advance(time.Hour)
+	_ = store.Set("foo", "bar", time.Second)
+
+	clk.Advance(500 * time.Millisecond)
+	if _, err := store.Get("foo"); err != nil {
+		t.Fatalf("Expected key set late in the storage lifetime to be alive, got %v", err)
+	}
+
+	// Updating the key restarts its TTL
+	_ = store.Set("foo", "baz", time.Second)
+	clk.Advance(900 * time.Millisecond)
+	if _, err := store.Get("foo"); err != nil {
+		t.Fatalf("Expected updated key to have a fresh TTL, got %v", err)
+	}
+}
+
+// TestEvictionWithFakeClock verifies that LRU eviction and expiry interact
+// correctly: an expired entry is removed on access while live entries
+// keep their LRU positions.
+func TestEvictionWithFakeClock(t *testing.T) {
+	clk := newFakeClock()
+	store := newInMemoryStorageWithClock(2, time.Minute, clk)
+	defer store.Stop()
+
+	_ = store.Set("a", "1", time.Second)
+	_ = store.Set("b", "2", time.Hour)
+
+	clk.Advance(2 * time.Second)
+	if _, err := store.Get("a"); err != ErrNotFound {
+		t.Fatalf("Expected 'a' to be expired, got %v", err)
+	}
+
+	// Room freed by expiry means no eviction is needed for "c"
+	_ = store.Set("c", "3", time.Hour)
+	if _, err := store.Get("b"); err != nil {
+		t.Fatalf("Expected 'b' to survive, got %v", err)
+	}
+
+	// "c" is now least recently used and is evicted by "d"
+	_ = store.Set("d", "4", time.Hour)
+	if _, err := store.Get("c"); err != ErrNotFound {
+		t.Fatalf("Expected 'c' to be evicted, got %v", err)
 	}
 }
 
@@ -329,13 +392,145 @@ func TestInMemoryStorage_EvictEmpty(t *testing.T) {
 	store := NewInMemoryStorage(10, time.Second)
 	defer store.Stop()
 
-	store.evict()
+	store.evict(nil)
 }
 
 // --------- Benchmarks ----------
 
 // BenchmarkSet measures performance of Set operations.
 // Tests throughput and memory allocations for setting items.
+type evictRecord struct {
+	key    string
+	value  any
+	reason EvictReason
+}
+
+// TestMaxValueBytes verifies oversized values are rejected without
+// disturbing entries already in the cache.
+func TestMaxValueBytes(t *testing.T) {
+	store := NewInMemoryStorage(10, time.Minute)
+	defer store.Stop()
+	store.SetMaxValueBytes(8)
+
+	_ = store.Set("small", []byte("1234"), 0)
+	_ = store.Set("edge", "12345678", 0)
+
+	if err := store.Set("big", []byte("123456789"), 0); err != ErrValueTooLarge {
+		t.Fatalf("expected ErrValueTooLarge, got %v", err)
+	}
+	if err := store.Set("bigstr", "123456789", 0); err != ErrValueTooLarge {
+		t.Fatalf("expected ErrValueTooLarge for string, got %v", err)
+	}
+	if _, err := store.Get("big"); err != ErrNotFound {
+		t.Fatalf("expected oversized value not to be stored, got %v", err)
+	}
+	for _, key := range []string{"small", "edge"} {
+		if _, err := store.Get(key); err != nil {
+			t.Fatalf("expected %q to survive, got %v", key, err)
+		}
+	}
+
+	// Unmeasured types are always accepted
+	if err := store.Set("struct", &struct{ A [64]byte }{}, 0); err != nil {
+		t.Fatalf("unexpected error for non-byte value: %v", err)
+	}
+}
+
+// recordEvictions installs an OnEvict hook that appends every call to a slice.
+func recordEvictions(store *InMemoryStorage) *[]evictRecord {
+	var mu sync.Mutex
+	got := &[]evictRecord{}
+	store.SetOnEvict(func(key string, value any, reason EvictReason) {
+		mu.Lock()
+		defer mu.Unlock()
+		*got = append(*got, evictRecord{key, value, reason})
+	})
+	return got
+}
+
+// TestOnEvict_Reasons verifies the hook fires with the right reason for LRU
+// eviction, replacement, Delete, and expiry detected on Get.
+func TestOnEvict_Reasons(t *testing.T) {
+	clk := newFakeClock()
+	store := newInMemoryStorageWithClock(2, time.Minute, clk)
+	defer store.Stop()
+	got := recordEvictions(store)
+
+	_ = store.Set("a", 1, 0)
+	_ = store.Set("b", 2, 0)
+	_ = store.Set("c", 3, 0) // evicts "a"
+	_ = store.Set("b", 20, 0)
+	_ = store.Delete("c")
+	_ = store.Set("d", 4, time.Second)
+	clk.Advance(2 * time.Second)
+	_, _ = store.Get("d")
+
+	want := []evictRecord{
+		{"a", 1, EvictLRU},
+		{"b", 2, EvictReplaced},
+		{"c", 3, EvictManual},
+		{"d", 4, EvictExpired},
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Fatalf("evictions = %+v, want %+v", *got, want)
+	}
+}
+
+// TestOnEvict_ExpiredByCleanup verifies the background cleanup reports expiries.
+func TestOnEvict_ExpiredByCleanup(t *testing.T) {
+	clk := newFakeClock()
+	store := newInMemoryStorageWithClock(10, time.Minute, clk)
+	defer store.Stop()
+	got := recordEvictions(store)
+
+	_ = store.Set("short", "v", time.Millisecond)
+	_ = store.Set("forever", "v", 0)
+	clk.Advance(time.Second)
+	clk.Tick()
+
+	want := []evictRecord{{"short", "v", EvictExpired}}
+	if !reflect.DeepEqual(*got, want) {
+		t.Fatalf("evictions = %+v, want %+v", *got, want)
+	}
+}
+
+// TestOnEvict_CallbackMayUseStore verifies the hook runs outside the cache lock.
+func TestOnEvict_CallbackMayUseStore(t *testing.T) {
+	store := NewInMemoryStorage(1, time.Minute)
+	defer store.Stop()
+
+	done := make(chan struct{})
+	store.SetOnEvict(func(key string, value any, reason EvictReason) {
+		// Re-entering the store must not deadlock
+		_, _ = store.Get(key)
+		close(done)
+	})
+
+	_ = store.Set("a", 1, 0)
+	_ = store.Set("b", 2, 0)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("OnEvict callback did not run")
+	}
+}
+
+func TestEvictReason_String(t *testing.T) {
+	cases := map[EvictReason]string{
+		EvictLRU:        "lru",
+		EvictExpired:    "expired",
+		EvictManual:     "manual",
+		EvictReplaced:   "replaced",
+		EvictReason(42): "unknown",
+	}
+	for r, want := range cases {
+		if got := r.String(); got != want {
+			t.Errorf("%d.String() = %q, want %q", r, got, want)
+		}
+	}
+}
+
 func BenchmarkSet(b *testing.B) {
 	// Large storage to avoid evictions during benchmark
 	store := NewInMemoryStorage(10*1024*1024, 100*time.Millisecond)
@@ -418,3 +613,196 @@ func BenchmarkConcurrentSetGet(b *testing.B) {
 		}
 	})
 }
+
+// TestInMemoryStorage_FinalizerStopsCleanup verifies that dropping the last
+// reference without calling Stop lets the storage be collected and stops the
+// background cleanup goroutine.
+func TestInMemoryStorage_FinalizerStopsCleanup(t *testing.T) {
+	clk := newFakeClock()
+	func() {
+		store := newInMemoryStorageWithClock(10, time.Minute, clk)
+		_ = store.Set("k", "v", 0)
+	}()
+
+	clk.mu.Lock()
+	tk := clk.tickers[0]
+	clk.mu.Unlock()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		runtime.GC()
+		select {
+		case <-tk.stopped:
+			return
+		case <-deadline:
+			t.Fatal("cleanup goroutine did not exit after the storage became unreachable")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// TestInMemoryStorage_StopIdempotent verifies Stop and Close can both be called.
+func TestInMemoryStorage_StopIdempotent(t *testing.T) {
+	store := NewInMemoryStorage(10, time.Minute)
+	store.Stop()
+	store.Close()
+}
+
+// TestKeysAndRange verifies enumeration order, TTL reporting, and that
+// iteration neither returns expired entries nor changes LRU order.
+func TestKeysAndRange(t *testing.T) {
+	clk := newFakeClock()
+	store := newInMemoryStorageWithClock(10, time.Minute, clk)
+	defer store.Stop()
+
+	_ = store.Set("a", 1, time.Minute)
+	_ = store.Set("b", 2, 0)
+	_ = store.Set("c", 3, time.Second)
+	_ = store.Set("gone", 4, time.Millisecond)
+	clk.Advance(10 * time.Millisecond)
+
+	if got, want := store.Keys(), []string{"c", "b", "a"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Keys() = %v, want %v", got, want)
+	}
+
+	ttls := map[string]time.Duration{}
+	values := map[string]any{}
+	store.Range(func(key string, value any, ttl time.Duration) bool {
+		ttls[key] = ttl
+		values[key] = value
+		return true
+	})
+	if len(ttls) != 3 {
+		t.Fatalf("expected 3 live entries, got %v", ttls)
+	}
+	if ttls["a"] != time.Minute-10*time.Millisecond || ttls["c"] != time.Second-10*time.Millisecond {
+		t.Fatalf("unexpected remaining TTLs: %v", ttls)
+	}
+	if ttls["b"] != 0 {
+		t.Fatalf("expected zero TTL for non-expiring entry, got %v", ttls["b"])
+	}
+	if values["a"] != 1 || values["b"] != 2 || values["c"] != 3 {
+		t.Fatalf("unexpected values: %v", values)
+	}
+
+	// Early stop
+	n := 0
+	store.Range(func(string, any, time.Duration) bool { n++; return false })
+	if n != 1 {
+		t.Fatalf("expected Range to stop after the first entry, got %d calls", n)
+	}
+
+	// Enumeration must not have promoted anything: "a" is still least recent
+	if got := store.Keys(); got[len(got)-1] != "a" {
+		t.Fatalf("expected LRU order to be unchanged, got %v", got)
+	}
+}
+
+func TestInMemoryStorage_GetRawReturnsCopy(t *testing.T) {
+	store := NewInMemoryStorage(10, time.Minute)
+	defer store.Stop()
+
+	_ = store.Set("k", []byte("value"), time.Minute)
+
+	raw, err := store.GetRaw("k")
+	if err != nil {
+		t.Fatalf("GetRaw: %v", err)
+	}
+	raw[0] = 'X'
+	_ = append(raw[:1], "oops"...)
+
+	val, _ := store.Get("k")
+	val.([]byte)[1] = 'Y'
+
+	again, err := store.GetRaw("k")
+	if err != nil || string(again) != "value" {
+		t.Fatalf("stored bytes were modified: %q, %v", again, err)
+	}
+
+	if _, err := store.GetRaw("missing"); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	_ = store.Set("s", "text", time.Minute)
+	if _, err := store.GetRaw("s"); err != ErrNotBytes {
+		t.Fatalf("expected ErrNotBytes, got %v", err)
+	}
+}
+
+func TestInMemoryStorage_GetRawUnsafeSharesBuffer(t *testing.T) {
+	store := NewInMemoryStorage(10, time.Minute)
+	defer store.Stop()
+
+	stored := []byte("value")
+	_ = store.Set("k", stored, time.Minute)
+
+	raw, err := store.GetRawUnsafe("k")
+	if err != nil {
+		t.Fatalf("GetRawUnsafe: %v", err)
+	}
+	if &raw[0] != &stored[0] {
+		t.Fatal("expected GetRawUnsafe to return the stored slice")
+	}
+}
+
+// BenchmarkGetRaw compares copying reads with the read-only opt-out.
+func BenchmarkGetRaw(b *testing.B) {
+	store := NewInMemoryStorage(10, time.Minute)
+	defer store.Stop()
+	_ = store.Set("k", make([]byte, 4096), time.Minute)
+
+	b.Run("copy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = store.GetRaw("k")
+		}
+	})
+	b.Run("unsafe", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = store.GetRawUnsafe("k")
+		}
+	})
+}
+
+// TestNoExpirationSurvivesCleanup verifies that zero-TTL entries outlive any
+// number of cleanup passes but are still evicted as least recently used.
+func TestNoExpirationSurvivesCleanup(t *testing.T) {
+	clk := newFakeClock()
+	store := newInMemoryStorageWithClock(2, time.Minute, clk)
+	defer store.Stop()
+
+	_ = store.Set("permanent", "a", NoExpiration)
+	_ = store.Set("ttl", "b", time.Minute)
+
+	for i := 0; i < 3; i++ {
+		clk.Advance(time.Hour)
+		clk.Tick()
+	}
+
+	store.mu.Lock()
+	_, permanent := store.items["permanent"]
+	_, ttl := store.items["ttl"]
+	store.mu.Unlock()
+	if !permanent {
+		t.Fatal("Expected zero-TTL entry to survive cleanup")
+	}
+	if ttl {
+		t.Fatal("Expected TTL entry to be removed by cleanup")
+	}
+
+	// Two newer entries push the permanent one out as least recently used
+	var reasons []EvictReason
+	store.SetOnEvict(func(key string, _ any, reason EvictReason) {
+		if key == "permanent" {
+			reasons = append(reasons, reason)
+		}
+	})
+	_ = store.Set("x", "c", NoExpiration)
+	_ = store.Set("y", "d", NoExpiration)
+	if _, err := store.Get("permanent"); err != ErrNotFound {
+		t.Fatalf("Expected zero-TTL entry to be LRU-evicted, got %v", err)
+	}
+	if len(reasons) != 1 || reasons[0] != EvictLRU {
+		t.Fatalf("Expected one LRU eviction, got %v", reasons)
+	}
+}
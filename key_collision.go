@@ -0,0 +1,72 @@
+package mysql
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"log/slog"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// fingerprintHeader starts external cache values written with
+// DetectKeyCollisions. Like metadataHeader it begins with 0xC1, which the
+// bundled codecs never emit; the trailing byte is the format version.
+var fingerprintHeader = []byte{0xC1, 'k', 'f', 1}
+
+// errKeyCollision reports a cached entry whose fingerprint does not match
+// the statement that read it.
+var errKeyCollision = errors.New("mysql: cache key collision")
+
+// fingerprint identifies the statement behind params: the database, query
+// text, and arguments exactly as CreateKey sees them, before any KeyHasher.
+// It is zero when DetectKeyCollisions is off or params name no statement.
+func (c *MySQL) fingerprint(params Params) uint64 {
+	if !c.detectKeyCollisions || !params.hasStatement() {
+		return 0
+	}
+	fp := xxhash.Sum64(buildKey(params, c, false))
+	if fp == 0 {
+		fp = 1 // Zero means "no fingerprint"
+	}
+	return fp
+}
+
+// wrapFingerprint prefixes data with fp. A zero fp leaves data unchanged.
+func wrapFingerprint(fp uint64, data []byte) []byte {
+	if fp == 0 {
+		return data
+	}
+	out := make([]byte, 0, len(fingerprintHeader)+8+len(data))
+	out = append(out, fingerprintHeader...)
+	out = binary.LittleEndian.AppendUint64(out, fp)
+	return append(out, data...)
+}
+
+// unwrapFingerprint splits a fingerprinted value. Values without the header
+// are returned as-is with a zero fingerprint.
+func unwrapFingerprint(data []byte) (uint64, []byte, error) {
+	if !bytes.HasPrefix(data, fingerprintHeader) {
+		return 0, data, nil
+	}
+	rest := data[len(fingerprintHeader):]
+	if len(rest) < 8 {
+		return 0, nil, errBadMetadata
+	}
+	return binary.LittleEndian.Uint64(rest), rest[8:], nil
+}
+
+// checkFingerprint compares the fingerprint stored with an entry against the
+// one expected by the reader. Entries or readers without one always pass.
+func checkFingerprint(stored, expected uint64) error {
+	if stored != 0 && expected != 0 && stored != expected {
+		return errKeyCollision
+	}
+	return nil
+}
+
+// keyCollision logs a detected collision and returns ErrKeyCollision.
+func (c *MySQL) keyCollision(key string) *MySQLError {
+	c.log().Error("mysql: cache key collision", slog.String("key", key))
+	return ErrKeyCollision
+}
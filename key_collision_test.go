@@ -0,0 +1,73 @@
+package mysql
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQuery_DetectKeyCollisions(t *testing.T) {
+	db := NewMockDB()
+	db.WithStmt("SELECT name FROM users", &MockStmt{Factory: func() Rows {
+		return &MockRows{data: [][]any{{"alice"}}}
+	}})
+	db.WithStmt("SELECT name FROM admins", &MockStmt{Factory: func() Rows {
+		return &MockRows{data: [][]any{{"root"}}}
+	}})
+	cache := newFakeCache()
+	client, cleanup := newExternalClient(db, cache)
+	defer cleanup()
+	client.detectKeyCollisions = true
+
+	// Two different statements deliberately share a manual key
+	users := Params{Query: "SELECT name FROM users", Key: "shared", CacheDelay: time.Minute}
+	admins := Params{Query: "SELECT name FROM admins", Key: "shared", CacheDelay: time.Minute}
+
+	if _, err := Query(client, users, scanStrings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The same statement reads its own entry back
+	if res, err := Query(client, users, scanStrings); err != nil || (*res)[0] != "alice" {
+		t.Fatalf("expected cached users, got %v, %v", res, err)
+	}
+
+	res, err := Query(client, admins, scanStrings)
+	if !errors.Is(err, ErrKeyCollision) {
+		t.Fatalf("expected ErrKeyCollision, got %v, %v", res, err)
+	}
+
+	// Different arguments to the same query are a different statement too
+	byID := Params{Query: "SELECT name FROM users", Args: []any{1}, Key: "shared", CacheDelay: time.Minute}
+	if _, err := Query(client, byID, scanStrings); !errors.Is(err, ErrKeyCollision) {
+		t.Fatalf("expected ErrKeyCollision for different args, got %v", err)
+	}
+}
+
+func TestQuery_CollisionCheckSkipsUnfingerprintedEntries(t *testing.T) {
+	cache := newFakeCache()
+	client, cleanup := newExternalClient(NewMockDB(), cache)
+	defer cleanup()
+	client.detectKeyCollisions = true
+
+	data, _ := client.codec.Marshal([]string{"legacy"})
+	_ = cache.Set("k", data, time.Minute)
+
+	res, err := Query(client, Params{Query: "SELECT 1", Key: "k", CacheDelay: time.Minute}, scanStrings)
+	if err != nil || (*res)[0] != "legacy" {
+		t.Fatalf("expected legacy entry to be served, got %v, %v", res, err)
+	}
+}
+
+func TestFingerprint_RoundTrip(t *testing.T) {
+	payload := []byte("payload")
+	fp, rest, err := unwrapFingerprint(wrapFingerprint(42, payload))
+	if err != nil || fp != 42 || string(rest) != "payload" {
+		t.Fatalf("unexpected round trip: %d, %q, %v", fp, rest, err)
+	}
+	if got := wrapFingerprint(0, payload); string(got) != "payload" {
+		t.Fatalf("expected zero fingerprint to leave data unchanged, got %q", got)
+	}
+	if _, _, err := unwrapFingerprint(fingerprintHeader); err == nil {
+		t.Fatal("expected error for truncated fingerprint")
+	}
+}
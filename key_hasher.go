@@ -0,0 +1,44 @@
+package mysql
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// KeyHasher condenses generated cache keys into a fixed-length form, which
+// saves memory and bandwidth for caches with many or long keys. Set it via
+// Options.KeyHasher. Manual Params.Key values are never hashed.
+type KeyHasher interface {
+	// HashKey returns the cache key for the raw key bytes. The bytes are only
+	// valid for the duration of the call.
+	HashKey(raw []byte) string
+}
+
+// xxhashSecondSeed seeds the second hash of XXHashKeyHasher in Double mode.
+const xxhashSecondSeed = 0x9E3779B97F4A7C15
+
+// XXHashKeyHasher hashes keys with xxHash64 into 16 hex characters. With
+// Double, a second, independently seeded xxHash64 is appended (32 hex
+// characters), reducing the collision probability for very large key spaces.
+type XXHashKeyHasher struct {
+	Double bool
+}
+
+// HashKey implements KeyHasher.
+func (h XXHashKeyHasher) HashKey(raw []byte) string {
+	var sum [16]byte
+	n := 8
+	binary.BigEndian.PutUint64(sum[:8], xxhash.Sum64(raw))
+	if h.Double {
+		d := xxhash.NewWithSeed(xxhashSecondSeed)
+		_, _ = d.Write(raw)
+		binary.BigEndian.PutUint64(sum[8:], d.Sum64())
+		n = 16
+	}
+
+	var dst [32]byte
+	hex.Encode(dst[:], sum[:n])
+	return string(dst[:2*n])
+}
@@ -0,0 +1,79 @@
+package mysql
+
+import (
+	"testing"
+	"time"
+)
+
+func TestXXHashKeyHasher_Deterministic(t *testing.T) {
+	params := Params{Query: "SELECT * FROM users WHERE id = ?", Args: []any{42}}
+
+	for _, hasher := range []XXHashKeyHasher{{}, {Double: true}} {
+		c := &MySQL{dbName: "shop", keyHasher: hasher}
+		a, b := CreateKey(params, c), CreateKey(params, &MySQL{dbName: "shop", keyHasher: hasher})
+		if a != b {
+			t.Fatalf("expected deterministic keys, got %q and %q", a, b)
+		}
+		want := 16
+		if hasher.Double {
+			want = 32
+		}
+		if len(a) != want {
+			t.Fatalf("expected %d hex characters, got %q", want, a)
+		}
+	}
+}
+
+func TestXXHashKeyHasher_DistinctInputs(t *testing.T) {
+	c := &MySQL{dbName: "shop", keyHasher: XXHashKeyHasher{Double: true}}
+	inputs := []Params{
+		{Query: "SELECT * FROM users WHERE id = ?", Args: []any{42}},
+		{Query: "SELECT * FROM users WHERE id = ?", Args: []any{43}},
+		{Query: "SELECT * FROM users WHERE id = ?", Args: []any{"42"}, Database: "other"},
+		{Query: "SELECT * FROM users WHERE id = ? ", Args: []any{42}},
+		{Exec: "user_get", Args: []any{42}},
+		{Exec: "user_get", Args: []any{"4", "2"}},
+		{Exec: "user_get", Args: []any{time.Unix(0, 0).UTC()}},
+	}
+
+	seen := make(map[string]int)
+	for i, p := range inputs {
+		key := CreateKey(p, c)
+		if j, ok := seen[key]; ok {
+			t.Fatalf("inputs %d and %d share key %q", j, i, key)
+		}
+		seen[key] = i
+	}
+
+	// The cache version still partitions hashed keys
+	versioned := &MySQL{dbName: "shop", keyHasher: XXHashKeyHasher{}, cacheVersion: "v2"}
+	if CreateKey(inputs[0], versioned) == CreateKey(inputs[0], &MySQL{dbName: "shop", keyHasher: XXHashKeyHasher{}}) {
+		t.Fatalf("expected the cache version to change hashed keys")
+	}
+}
+
+func benchmarkKey(b *testing.B, c *MySQL) {
+	params := Params{
+		Query: "SELECT id, name, email, created_at FROM users WHERE tenant_id = ? AND status = ? ORDER BY created_at DESC LIMIT ?",
+		Args:  []any{746457348, "active", 50},
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	var key string
+	for i := 0; i < b.N; i++ {
+		key = CreateKey(params, c)
+	}
+	b.ReportMetric(float64(len(key)), "key-bytes")
+}
+
+func BenchmarkCreateKey_Raw(b *testing.B) {
+	benchmarkKey(b, &MySQL{dbName: "shop"})
+}
+
+func BenchmarkCreateKey_XXHash(b *testing.B) {
+	benchmarkKey(b, &MySQL{dbName: "shop", keyHasher: XXHashKeyHasher{}})
+}
+
+func BenchmarkCreateKey_XXHashDouble(b *testing.B) {
+	benchmarkKey(b, &MySQL{dbName: "shop", keyHasher: XXHashKeyHasher{Double: true}})
+}
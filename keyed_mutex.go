@@ -2,15 +2,26 @@ package mysql
 
 import (
 	"errors"
+	"log/slog"
 	"sync"
+	"time"
 )
 
+// ErrLockReclaimed is returned when releasing a lock that the TTL watchdog of
+// a KeyedMutex created by NewMutexWithTTL already force-released.
+var ErrLockReclaimed = errors.New("keyedmutex: lock was reclaimed after its TTL")
+
 // entry represents a reference-counted mutex for a specific key.
 // It tracks how many goroutines are currently waiting on or holding this mutex.
 // When refs reaches zero, the entry can be returned to the pool for reuse.
 type entry struct {
 	m    sync.Mutex // Underlying mutex for the key
 	refs int32      // Reference counter for concurrent lock holders
+
+	// Hold tracking, maintained only with a TTL (guarded by KeyedMutex.mu)
+	locked bool      // The mutex is currently held
+	since  time.Time // When the current hold was acquired
+	gen    uint64    // Incremented per hold and on reclaim; never reset
 }
 
 // KeyedMutex provides per-key mutual exclusion with automatic entry management.
@@ -22,6 +33,13 @@ type KeyedMutex struct {
 	mu   sync.Mutex        // Protects access to the map
 	m    map[string]*entry // Maps keys to their corresponding mutex entries
 	pool sync.Pool         // Pool of reusable entry objects
+
+	ttl       time.Duration // Holds older than this are reclaimed (0 = never)
+	clock     clock         // Time source for the watchdog
+	stop      chan struct{} // Closed by Close to end the watchdog
+	done      chan struct{} // Closed once the watchdog has exited
+	stopOnce  sync.Once
+	reclaimed int // Holds force-released so far
 }
 
 // NewMutex creates and initializes a new KeyedMutex instance.
@@ -37,6 +55,57 @@ func NewMutex() *KeyedMutex {
 	}
 }
 
+// NewMutexWithTTL creates a KeyedMutex whose locks are force-released once
+// held longer than ttl, so a holder that never unlocks (a deadlocked
+// callback, a panic before the deferred Unlock) cannot wedge every query on
+// its cache key. Each reclaim is logged. A background watchdog checks holds
+// every ttl/2; stop it with Close.
+//
+// Pick ttl well above the slowest legitimate query: a reclaimed holder that
+// is merely slow loses mutual exclusion. Locks taken by this package are
+// released through a per-hold handle, so their late release is ignored; a
+// late Unlock(key) from other callers cannot be told apart from the current
+// holder's and releases that hold instead.
+func NewMutexWithTTL(ttl time.Duration) *KeyedMutex {
+	return newMutexWithClock(ttl, realClock{})
+}
+
+// newMutexWithClock is NewMutexWithTTL with an injectable clock for tests.
+func newMutexWithClock(ttl time.Duration, clk clock) *KeyedMutex {
+	k := NewMutex()
+	if ttl <= 0 {
+		return k
+	}
+	k.ttl = ttl
+	k.clock = clk
+	k.stop = make(chan struct{})
+	k.done = make(chan struct{})
+
+	t := clk.NewTicker(max(ttl/2, time.Millisecond))
+	go func() {
+		defer close(k.done)
+		defer t.Stop()
+		for {
+			select {
+			case <-k.stop:
+				return
+			case <-t.C():
+				k.reclaimExpired()
+			}
+		}
+	}()
+	return k
+}
+
+// Close stops the TTL watchdog and waits for it to exit. Locks keep
+// working, without reclaiming. It is a no-op for mutexes created by NewMutex.
+func (k *KeyedMutex) Close() {
+	if k.stop != nil {
+		k.stopOnce.Do(func() { close(k.stop) })
+		<-k.done
+	}
+}
+
 // Lock acquires the mutex for the specified key.
 // If the mutex for this key is already locked by another goroutine,
 // Lock blocks until the mutex is available.
@@ -48,6 +117,12 @@ func NewMutex() *KeyedMutex {
 // Returns nil on successful lock acquisition. Errors are not expected
 // during normal operation but the signature allows for future extensions.
 func (k *KeyedMutex) Lock(key string) error {
+	k.lock(key)
+	return nil
+}
+
+// lock acquires key and returns the entry and hold generation.
+func (k *KeyedMutex) lock(key string) (*entry, uint64) {
 	k.mu.Lock()
 	e, exists := k.m[key]
 	if !exists {
@@ -63,7 +138,37 @@ func (k *KeyedMutex) Lock(key string) error {
 
 	// Acquire the actual mutex (may block here)
 	e.m.Lock()
-	return nil
+
+	if k.ttl <= 0 {
+		return e, 0
+	}
+	k.mu.Lock()
+	e.locked = true
+	e.since = k.clock.Now()
+	e.gen++
+	gen := e.gen
+	k.mu.Unlock()
+	return e, gen
+}
+
+// lockHold acquires key and returns a function releasing exactly this hold.
+// Unlike Unlock, the release is ignored (returning ErrLockReclaimed) when the
+// watchdog already reclaimed the hold, so it never releases a later holder.
+func (k *KeyedMutex) lockHold(key string) func() error {
+	e, gen := k.lock(key)
+	if k.ttl <= 0 {
+		return func() error { return k.Unlock(key) }
+	}
+	return func() error {
+		k.mu.Lock()
+		if !e.locked || e.gen != gen {
+			k.mu.Unlock()
+			return ErrLockReclaimed
+		}
+		k.release(key, e)
+		k.mu.Unlock()
+		return nil
+	}
 }
 
 // Unlock releases the mutex for the specified key.
@@ -77,11 +182,20 @@ func (k *KeyedMutex) Lock(key string) error {
 // and returned to the pool for reuse.
 func (k *KeyedMutex) Unlock(key string) error {
 	k.mu.Lock()
+	defer k.mu.Unlock()
+
 	e, exists := k.m[key]
-	if !exists {
-		k.mu.Unlock()
+	if !exists || (k.ttl > 0 && !e.locked) {
 		return errors.New("keyedmutex: unlock of unlocked key")
 	}
+	k.release(key, e)
+	return nil
+}
+
+// release unlocks e, held for key, and drops the holder's reference.
+// Must be called with k.mu held.
+func (k *KeyedMutex) release(key string, e *entry) {
+	e.locked = false
 
 	// Release the underlying mutex first
 	e.m.Unlock()
@@ -93,6 +207,37 @@ func (k *KeyedMutex) Unlock(key string) error {
 		e.refs = 0 // Reset for pool reuse
 		k.pool.Put(e)
 	}
-	k.mu.Unlock()
-	return nil
+}
+
+// reclaimExpired force-releases every hold older than the TTL.
+func (k *KeyedMutex) reclaimExpired() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := k.clock.Now()
+	for key, e := range k.m {
+		if !e.locked || now.Sub(e.since) <= k.ttl {
+			continue
+		}
+		held := now.Sub(e.since)
+		e.gen++ // Invalidate the abandoned holder's handle
+		k.release(key, e)
+		k.reclaimed++
+		slog.Default().Warn("mysql: reclaimed lock held past its TTL",
+			slog.String("key", key), slog.Duration("held", held), slog.Duration("ttl", k.ttl))
+	}
+}
+
+// lockKey takes the stampede-protection lock for key and returns its
+// release. A KeyedMutex is released per hold (see lockHold), so a release
+// that arrives after the TTL watchdog reclaimed the lock is ignored.
+func (c *MySQL) lockKey(key string) (func(), error) {
+	if km, ok := c.mutex.(*KeyedMutex); ok {
+		release := km.lockHold(key)
+		return func() { _ = release() }, nil
+	}
+	if err := c.mutex.Lock(key); err != nil {
+		return nil, err
+	}
+	return func() { _ = c.mutex.Unlock(key) }, nil
 }
@@ -1,6 +1,7 @@
 package mysql
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"sync"
@@ -107,6 +108,110 @@ func TestKeyedMutex_UnlockWithoutLockError(t *testing.T) {
 	}
 }
 
+// lockAsync locks key on a new goroutine and returns a channel closed once
+// the lock is held.
+func lockAsync(km *KeyedMutex, key string) <-chan struct{} {
+	acquired := make(chan struct{})
+	go func() {
+		_ = km.Lock(key)
+		close(acquired)
+	}()
+	return acquired
+}
+
+// A lock abandoned by its holder is reclaimed once held past the TTL,
+// letting the next waiter in.
+func TestKeyedMutex_TTLReclaimsAbandonedLock(t *testing.T) {
+	clk := newFakeClock()
+	km := newMutexWithClock(time.Minute, clk)
+	defer km.Close()
+
+	_ = km.Lock("k") // Never unlocked
+	waiter := lockAsync(km, "k")
+
+	// Still within the TTL: nothing is reclaimed
+	clk.Advance(30 * time.Second)
+	clk.Tick()
+	select {
+	case <-waiter:
+		t.Fatal("waiter acquired the lock before the TTL expired")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	clk.Advance(31 * time.Second)
+	clk.Tick()
+	select {
+	case <-waiter:
+	case <-time.After(time.Second):
+		t.Fatal("abandoned lock was not reclaimed")
+	}
+
+	km.mu.Lock()
+	reclaimed := km.reclaimed
+	km.mu.Unlock()
+	if reclaimed != 1 {
+		t.Fatalf("expected 1 reclaim, got %d", reclaimed)
+	}
+
+	// The waiter holds the lock now and releases it normally
+	if err := km.Unlock("k"); err != nil {
+		t.Fatalf("unexpected unlock error: %v", err)
+	}
+	if err := km.Unlock("k"); err == nil {
+		t.Fatal("expected an error unlocking a free key")
+	}
+}
+
+// The late release of a reclaimed hold must not release the next holder.
+func TestKeyedMutex_TTLIgnoresLateRelease(t *testing.T) {
+	clk := newFakeClock()
+	km := newMutexWithClock(time.Minute, clk)
+	defer km.Close()
+
+	slow := km.lockHold("k")
+	clk.Advance(2 * time.Minute)
+	clk.Tick()
+
+	next := km.lockHold("k")
+	if err := slow(); !errors.Is(err, ErrLockReclaimed) {
+		t.Fatalf("expected ErrLockReclaimed, got %v", err)
+	}
+
+	// The second hold is intact: a third locker must still wait for it
+	third := lockAsync(km, "k")
+	select {
+	case <-third:
+		t.Fatal("late release freed the current holder's lock")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if err := next(); err != nil {
+		t.Fatalf("unexpected release error: %v", err)
+	}
+	select {
+	case <-third:
+	case <-time.After(time.Second):
+		t.Fatal("third locker was not admitted")
+	}
+}
+
+func TestKeyedMutex_CloseStopsWatchdog(t *testing.T) {
+	clk := newFakeClock()
+	km := newMutexWithClock(time.Minute, clk)
+	km.Close()
+	km.Close() // Idempotent
+
+	_ = km.Lock("k")
+	clk.Advance(2 * time.Minute)
+	clk.Tick() // The stopped ticker is skipped
+
+	km.mu.Lock()
+	defer km.mu.Unlock()
+	if km.reclaimed != 0 || !km.m["k"].locked {
+		t.Fatal("expected no reclaim after Close")
+	}
+}
+
 // --------- Benchmarks ----------
 
 // BenchmarkKeyedMutex_SameKey benchmarks performance under high contention:
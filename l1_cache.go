@@ -0,0 +1,29 @@
+package mysql
+
+import "time"
+
+// L1Cache is the in-process cache holding decoded query results (L1).
+// Unlike Storage, values are live Go objects (the *T built by a Query
+// callback), so implementations must not serialize them. InMemoryStorage is
+// the default implementation; supply another one with Options.L1, e.g. an
+// alternative LRU or a counting fake in tests.
+type L1Cache interface {
+	// Get returns the value stored under key, or ErrNotFound when it is
+	// missing or expired.
+	Get(key string) (any, error)
+
+	// Set stores val under key for exp (0 = until evicted).
+	Set(key string, val any, exp time.Duration) error
+
+	// Delete removes key. The client ignores the result, so a missing key
+	// may report ErrNotFound or nil.
+	Delete(key string) error
+}
+
+// l1Sizer is implemented by L1 caches that can report their size for Status.
+type l1Sizer interface {
+	Len() int
+	Bytes() int
+}
+
+var _ L1Cache = (*InMemoryStorage)(nil)
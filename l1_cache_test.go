@@ -0,0 +1,100 @@
+package mysql
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeL1 is a map-backed L1Cache that counts calls and reports no size.
+type fakeL1 struct {
+	mu     sync.Mutex
+	items  map[string]any
+	gets   int
+	hits   int
+	sets   int
+	delete int
+}
+
+func newFakeL1() *fakeL1 {
+	return &fakeL1{items: make(map[string]any)}
+}
+
+func (f *fakeL1) Get(key string) (any, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.gets++
+	v, ok := f.items[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	f.hits++
+	return v, nil
+}
+
+func (f *fakeL1) Set(key string, val any, _ time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sets++
+	f.items[key] = val
+	return nil
+}
+
+func (f *fakeL1) Delete(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.delete++
+	delete(f.items, key)
+	return nil
+}
+
+func TestL1Cache_CustomImplementationWarmsAndHits(t *testing.T) {
+	queries := 0
+	db := NewMockDB()
+	db.WithStmt("SELECT name FROM users", &MockStmt{Factory: func() Rows {
+		queries++
+		return &MockRows{data: [][]any{{"alice"}}}
+	}})
+
+	l1 := newFakeL1()
+	client := newClient(nil, defaultOptions(Options{L1: l1}))
+	client.DB = db
+	defer client.Close()
+
+	params := Params{Query: "SELECT name FROM users", CacheDelay: time.Minute}
+	for i := 0; i < 2; i++ {
+		res, err := Query(client, params, scanStrings)
+		if err != nil {
+			t.Fatalf("Query #%d: %v", i+1, err)
+		}
+		if len(*res) != 1 || (*res)[0] != "alice" {
+			t.Fatalf("Query #%d: unexpected result %v", i+1, *res)
+		}
+	}
+
+	if queries != 1 {
+		t.Fatalf("expected the second query to be served from L1, got %d DB queries", queries)
+	}
+	if l1.sets != 1 || l1.hits != 1 {
+		t.Fatalf("expected one warm and one hit, got %d sets and %d hits", l1.sets, l1.hits)
+	}
+	if s := client.Status(); s.CacheL1Hits != 1 || s.L1Entries != 0 || s.L1Bytes != 0 {
+		t.Fatalf("unexpected status for an unsized L1: %+v", s)
+	}
+}
+
+func TestL1Cache_WriteThroughInvalidatesCustomImplementation(t *testing.T) {
+	client, cleanup := newInternalClient(&execRecordingDB{})
+	defer cleanup()
+	l1 := newFakeL1()
+	client.inMemory = l1
+
+	_ = l1.Set("users:1", &[]string{"alice"}, time.Minute)
+	params := Params{Query: "UPDATE users SET name = ?", Args: []any{"bob"}, Key: "users:1"}
+	if _, err := ExecWriteThrough(client, params, &[]string{"bob"}); err != nil {
+		t.Fatalf("ExecWriteThrough: %v", err)
+	}
+	if _, ok := l1.items["users:1"]; ok || l1.delete != 1 {
+		t.Fatalf("expected the key to be deleted from the custom L1, got %d deletes", l1.delete)
+	}
+}
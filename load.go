@@ -0,0 +1,94 @@
+package mysql
+
+import "time"
+
+// Load runs the cache-aside flow used by Query around an arbitrary loader
+// instead of a SQL statement, so expensive computations or external API
+// calls can share the same L1/L2 caches, codec, and stampede protection.
+//
+// On a miss, concurrent callers for the same key coalesce on the keyed mutex:
+// one runs loader and the others read its cached result. Successful results
+// are cached for ttl at every configured level (L2 and L1 when an external
+// cache is set, unless DisableL1; L1 otherwise). key is used verbatim; CacheVersion does not
+// apply. With a non-positive ttl or an empty key, loader runs uncached.
+func Load[T any](c *MySQL, key string, ttl time.Duration, loader func() (*T, *MySQLError)) (*T, *MySQLError) {
+	if !c.beginQuery() {
+		return nil, ErrClosed
+	}
+	defer c.endQuery()
+
+	if key == "" || ttl <= 0 {
+		return loader()
+	}
+	if c.cache == nil {
+		return loadInternal(c, key, ttl, loader)
+	}
+	if !c.CacheEnabled() {
+		return loader()
+	}
+
+	if !c.disableL1 {
+		if res, ok := getL1[T](c, key); ok {
+			return res, nil
+		}
+	}
+
+	res, unlock, merr := lookupExternalCache[T](c, nil, key, false, nil, 0)
+	if unlock != nil {
+		defer unlock()
+	}
+	if merr != nil {
+		return nil, merr
+	}
+	if res != nil {
+		// L2 hit - warm up L1
+		if !c.disableL1 {
+			c.setL1(key, res, ttl)
+		}
+		return res, nil
+	}
+
+	res, merr = loader()
+	if merr == nil && res != nil {
+		if _, serr := c.storeExternal(key, Params{Key: key, CacheDelay: ttl, NodeCacheDelay: ttl}, 0, res); serr != nil {
+			return res, serr
+		}
+	}
+	return res, merr
+}
+
+// loadInternal is the L1-only variant of Load. Callers still coalesce on the
+// keyed mutex, so a single loader call fills the cache.
+func loadInternal[T any](c *MySQL, key string, ttl time.Duration, loader func() (*T, *MySQLError)) (*T, *MySQLError) {
+	get := func() *T {
+		if res, ok := getL1[T](c, key); ok {
+			return res
+		}
+		return nil
+	}
+	if res := get(); res != nil {
+		return res, nil
+	}
+
+	if c.mutex != nil {
+		mutexKey := "mutex_" + key
+		if unlock, err := c.lockKey(mutexKey); err != nil {
+			if merr := c.cacheFailure("mutex lock", key, err, ErrLockFailed); merr != nil {
+				return nil, merr
+			}
+		} else {
+			defer unlock()
+			// Another caller may have filled the cache while we waited
+			if res := get(); res != nil {
+				return res, nil
+			}
+		}
+	}
+
+	c.cacheStats.misses.Add(1)
+	res, merr := loader()
+	if merr == nil && res != nil && fitsCache(c, res) {
+		c.setL1(key, res, ttl)
+	}
+	return res, merr
+}
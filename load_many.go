@@ -0,0 +1,168 @@
+package mysql
+
+import (
+	"errors"
+	"time"
+)
+
+// LoadMany is the batched form of Load for loaders that fetch many entities
+// at once, such as a "WHERE id IN (...)" query. It returns the cached or
+// loaded value for each key; keys the loader did not return are absent.
+//
+// Keys missing from L1 are read from the external cache in one
+// BatchStorage.MultiGet call when Options.Cache supports it (one Get per key
+// otherwise), and loader is called once with the keys still missing. Its
+// results are cached for ttl at the same levels as Load, with a single
+// MultiSet to the external cache. Unlike Load, concurrent callers do not
+// coalesce on the keyed mutex. Keys are used verbatim; with a non-positive
+// ttl or caching disabled, loader runs for every key.
+func LoadMany[T any](c *MySQL, keys []string, ttl time.Duration, loader func(missing []string) (map[string]*T, *MySQLError)) (map[string]*T, *MySQLError) {
+	if !c.beginQuery() {
+		return nil, ErrClosed
+	}
+	defer c.endQuery()
+
+	keys = uniqueKeys(keys)
+	if len(keys) == 0 {
+		return map[string]*T{}, nil
+	}
+	if ttl <= 0 || (c.cache != nil && !c.CacheEnabled()) {
+		return loader(keys)
+	}
+
+	res := make(map[string]*T, len(keys))
+	useL1 := c.cache == nil || !c.disableL1
+
+	// L1 first; what is left goes to L2 as one batch
+	missing := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if useL1 {
+			if v, ok := getL1[T](c, key); ok {
+				res[key] = v
+				continue
+			}
+		}
+		missing = append(missing, key)
+	}
+	if c.cache != nil && len(missing) > 0 {
+		var merr *MySQLError
+		if missing, merr = loadManyExternal(c, missing, ttl, res); merr != nil {
+			return nil, merr
+		}
+	}
+	if len(missing) == 0 {
+		return res, nil
+	}
+
+	c.cacheStats.misses.Add(uint64(len(missing)))
+	loaded, merr := loader(missing)
+	if merr != nil {
+		return nil, merr
+	}
+
+	params := Params{CacheDelay: ttl, NodeCacheDelay: ttl}
+	items := make(map[string]CacheItem, len(missing))
+	var serr *MySQLError
+	for _, key := range missing {
+		v, ok := loaded[key]
+		if !ok || v == nil {
+			continue
+		}
+		res[key] = v
+
+		if c.cache == nil {
+			if fitsCache(c, v) {
+				c.setL1(key, v, ttl)
+			}
+			continue
+		}
+		data, err := c.marshalCacheValue(nil, v)
+		if err != nil {
+			serr = ErrSerialize
+			continue
+		}
+		if c.maxValueBytes > 0 && len(data) > c.maxValueBytes {
+			continue
+		}
+		params.Key = key
+		if data, err = c.externalValue(params, 0, data); err == nil {
+			items[key] = CacheItem{Value: data, TTL: ttl}
+		}
+		if useL1 {
+			c.setL1(key, v, ttl)
+		}
+	}
+	c.storeMany(items)
+	return res, serr
+}
+
+// loadManyExternal reads keys from the external cache in one batch, adding
+// decoded hits to res (and L1) and returning the keys that still missed.
+// Backend failures are handled like in Query: the key degrades to the
+// loader, or the batch fails when DegradeOnCacheError is false.
+func loadManyExternal[T any](c *MySQL, keys []string, ttl time.Duration, res map[string]*T) ([]string, *MySQLError) {
+	vals, errs := multiGet(c.cache, keys)
+
+	missing := keys[:0:0]
+	for i, key := range keys {
+		if err := errs[i]; err != nil {
+			if !errors.Is(err, ErrNotFound) {
+				if merr := c.cacheFailure("cache get", key, err, ErrCacheUnavailable); merr != nil {
+					return nil, merr
+				}
+			}
+			missing = append(missing, key)
+			continue
+		}
+
+		payload, err := c.externalPayload(key, vals[i], 0)
+		if payload == nil || err != nil {
+			missing = append(missing, key)
+			continue
+		}
+		var obj T
+		if err := c.unmarshalCacheValue(nil, payload, &obj); err != nil {
+			c.decodeFailed(key, err)
+			missing = append(missing, key)
+			continue
+		}
+
+		c.cacheStats.l2Hits.Add(1)
+		res[key] = &obj
+		if !c.disableL1 {
+			c.setL1(key, &obj, ttl)
+		}
+	}
+	return missing, nil
+}
+
+// storeMany writes items to the external cache, in one batch unless
+// AsyncCacheWrites queues them individually. Like storeExternalData it is
+// best-effort and ignores write errors.
+func (c *MySQL) storeMany(items map[string]CacheItem) {
+	if len(items) == 0 {
+		return
+	}
+	if c.cacheWriter != nil {
+		for key, item := range items {
+			c.setExternalCache(nil, key, item.Value, item.TTL)
+		}
+		return
+	}
+	_ = multiSet(c.cache, items)
+}
+
+// uniqueKeys returns keys without empty strings and duplicates, keeping
+// the first occurrence of each.
+func uniqueKeys(keys []string) []string {
+	seen := make(map[string]struct{}, len(keys))
+	out := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if _, dup := seen[key]; key == "" || dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, key)
+	}
+	return out
+}
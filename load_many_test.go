@@ -0,0 +1,148 @@
+package mysql
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// loadUsers returns a LoadMany loader that records the keys it is asked for
+// and loads every key except "ghost".
+func loadUsers(calls *[][]string) func([]string) (map[string]*string, *MySQLError) {
+	return func(missing []string) (map[string]*string, *MySQLError) {
+		*calls = append(*calls, append([]string(nil), missing...))
+		res := make(map[string]*string, len(missing))
+		for _, key := range missing {
+			if key != "ghost" {
+				v := "user " + key
+				res[key] = &v
+			}
+		}
+		return res, nil
+	}
+}
+
+func TestLoadMany_BatchStorage(t *testing.T) {
+	cache := &batchCache{fakeCache: newFakeCache()}
+	client, cleanup := newExternalClient(nil, cache)
+	defer cleanup()
+
+	var calls [][]string
+	res, err := LoadMany(client, []string{"1", "2", "2", "ghost"}, time.Minute, loadUsers(&calls))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res) != 2 || *res["1"] != "user 1" || *res["2"] != "user 2" {
+		t.Fatalf("unexpected result: %v", res)
+	}
+	if len(calls) != 1 || !reflect.DeepEqual(calls[0], []string{"1", "2", "ghost"}) {
+		t.Fatalf("expected one loader call with the deduplicated keys, got %v", calls)
+	}
+	if cache.multiGets != 1 || cache.multiSets != 1 || cache.gets != 0 || cache.setCalls != 2 {
+		t.Fatalf("expected one MultiGet and one MultiSet of 2 items, got %d, %d (%d Get, %d items)",
+			cache.multiGets, cache.multiSets, cache.gets, cache.setCalls)
+	}
+
+	// L2 serves the stored keys once L1 is gone; only the new key is loaded
+	client.inMemory.(*InMemoryStorage).Reset()
+	calls = nil
+	res, err = LoadMany(client, []string{"1", "2", "3"}, time.Minute, loadUsers(&calls))
+	if err != nil || len(res) != 3 || *res["2"] != "user 2" {
+		t.Fatalf("unexpected result: %v, %v", res, err)
+	}
+	if len(calls) != 1 || !reflect.DeepEqual(calls[0], []string{"3"}) {
+		t.Fatalf("expected only the uncached key to be loaded, got %v", calls)
+	}
+	if cache.multiGets != 2 {
+		t.Fatalf("expected a second MultiGet, got %d", cache.multiGets)
+	}
+
+	// Everything is in L1 now: no cache or loader calls at all
+	calls = nil
+	if _, err := LoadMany(client, []string{"1", "3"}, time.Minute, loadUsers(&calls)); err != nil || len(calls) != 0 || cache.multiGets != 2 {
+		t.Fatalf("expected L1 hits, got %v loader calls, %d MultiGet, %v", calls, cache.multiGets, err)
+	}
+}
+
+func TestLoadMany_FallbackStorage(t *testing.T) {
+	cache := &countingCache{fakeCache: newFakeCache()}
+	client, cleanup := newExternalClient(nil, cache)
+	defer cleanup()
+	client.disableL1 = true
+
+	var calls [][]string
+	if _, err := LoadMany(client, []string{"1", "2"}, time.Minute, loadUsers(&calls)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cache.gets != 2 || cache.setCalls != 2 {
+		t.Fatalf("expected one Get and Set per key, got %d and %d", cache.gets, cache.setCalls)
+	}
+
+	res, err := LoadMany(client, []string{"2", "1"}, time.Minute, loadUsers(&calls))
+	if err != nil || len(calls) != 1 || *res["1"] != "user 1" {
+		t.Fatalf("expected L2 hits through Get, got %v, %v calls, %v", res, calls, err)
+	}
+	keys := make([]string, 0, len(res))
+	for k := range res {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if !reflect.DeepEqual(keys, []string{"1", "2"}) {
+		t.Fatalf("unexpected keys: %v", keys)
+	}
+}
+
+func TestLoadMany_Internal(t *testing.T) {
+	client, cleanup := newInternalClient(nil)
+	defer cleanup()
+
+	var calls [][]string
+	_, _ = LoadMany(client, []string{"1"}, time.Minute, loadUsers(&calls))
+	res, err := LoadMany(client, []string{"1", "2"}, time.Minute, loadUsers(&calls))
+	if err != nil || len(res) != 2 {
+		t.Fatalf("unexpected result: %v, %v", res, err)
+	}
+	if len(calls) != 2 || !reflect.DeepEqual(calls[1], []string{"2"}) {
+		t.Fatalf("expected the second call to load only the L1 miss, got %v", calls)
+	}
+}
+
+func TestLoadMany_Errors(t *testing.T) {
+	cache := &batchCache{fakeCache: newFakeCache()}
+	client, cleanup := newExternalClient(nil, cache)
+	defer cleanup()
+	client.logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	loadErr := NewError(errors.New("db down"))
+	_, err := LoadMany(client, []string{"1"}, time.Minute, func([]string) (map[string]*string, *MySQLError) {
+		return nil, loadErr
+	})
+	if err != loadErr || cache.multiSets != 0 {
+		t.Fatalf("expected the loader error and nothing cached, got %v, %d MultiSet", err, cache.multiSets)
+	}
+
+	// A failing backend degrades to the loader by default...
+	cache.getErr = errors.New("cache down")
+	var calls [][]string
+	if res, err := LoadMany(client, []string{"1"}, time.Minute, loadUsers(&calls)); err != nil || len(res) != 1 {
+		t.Fatalf("expected degradation to the loader, got %v, %v", res, err)
+	}
+
+	// ...and fails the batch when degradation is disabled
+	client.inMemory.(*InMemoryStorage).Reset()
+	client.failOnCacheError = true
+	if _, err := LoadMany(client, []string{"1"}, time.Minute, loadUsers(&calls)); !errors.Is(err, ErrCacheUnavailable) {
+		t.Fatalf("expected ErrCacheUnavailable, got %v", err)
+	}
+
+	// Uncached: ttl <= 0 always calls the loader with every key
+	calls = nil
+	_, _ = LoadMany(client, []string{"1", "2"}, 0, loadUsers(&calls))
+	if len(calls) != 1 || len(calls[0]) != 2 {
+		t.Fatalf("expected an uncached load of both keys, got %v", calls)
+	}
+}
@@ -0,0 +1,97 @@
+package mysql
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingLoader returns a slow loader that records how often it ran.
+func countingLoader(calls *atomic.Int32) func() (*string, *MySQLError) {
+	return func() (*string, *MySQLError) {
+		calls.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		v := "computed"
+		return &v, nil
+	}
+}
+
+func loadConcurrently(t *testing.T, c *MySQL, key string, loader func() (*string, *MySQLError)) {
+	t.Helper()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := Load(c, key, time.Minute, loader)
+			if err != nil || *res != "computed" {
+				t.Errorf("unexpected result %v (%v)", res, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestLoad_ExternalSingleFlight(t *testing.T) {
+	cache := newFakeCache()
+	client, cleanup := newExternalClient(&countingDB{}, cache)
+	defer cleanup()
+
+	var calls atomic.Int32
+	loadConcurrently(t, client, "report:daily", countingLoader(&calls))
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected a single loader call, got %d", n)
+	}
+	if _, err := cache.Get("report:daily"); err != nil {
+		t.Fatalf("expected the result in L2, got %v", err)
+	}
+	if _, err := client.inMemory.Get("report:daily"); err != nil {
+		t.Fatalf("expected the result in L1, got %v", err)
+	}
+}
+
+func TestLoad_InternalSingleFlight(t *testing.T) {
+	client, cleanup := newInternalClient(&countingDB{})
+	defer cleanup()
+	client.mutex = NewMutex()
+
+	var calls atomic.Int32
+	loadConcurrently(t, client, "report:daily", countingLoader(&calls))
+	loadConcurrently(t, client, "report:daily", countingLoader(&calls))
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected a single loader call, got %d", n)
+	}
+}
+
+func TestLoad_ErrorsAreNotCached(t *testing.T) {
+	client, cleanup := newExternalClient(&countingDB{}, newFakeCache())
+	defer cleanup()
+
+	calls := 0
+	failing := func() (*string, *MySQLError) {
+		calls++
+		return nil, ErrTimeout
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := Load(client, "k", time.Minute, failing); err != ErrTimeout {
+			t.Fatalf("expected loader error, got %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected failed loads to be retried, got %d calls", calls)
+	}
+}
+
+func TestLoad_Uncached(t *testing.T) {
+	client, cleanup := newExternalClient(&countingDB{}, newFakeCache())
+	defer cleanup()
+
+	var calls atomic.Int32
+	loader := countingLoader(&calls)
+	_, _ = Load(client, "k", 0, loader)
+	_, _ = Load(client, "", time.Minute, loader)
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected uncached loads to always run, got %d", n)
+	}
+}
@@ -0,0 +1,11 @@
+package mysql
+
+import "log/slog"
+
+// log returns the configured logger, falling back to slog.Default.
+func (c *MySQL) log() *slog.Logger {
+	if c.logger != nil {
+		return c.logger
+	}
+	return slog.Default()
+}
@@ -3,6 +3,9 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
 	"time"
 )
 
@@ -18,6 +21,14 @@ type Rows interface {
 	// The number of destinations must match the number of columns in the result.
 	Scan(dest ...any) error
 
+	// Columns returns the column names of the current result set.
+	Columns() ([]string, error)
+
+	// NextResultSet prepares the next result set for reading, e.g. when a
+	// stored procedure returns several SELECT results. It reports whether
+	// there is a further result set; iteration then continues with Next.
+	NextResultSet() bool
+
 	// Close closes the Rows iterator, preventing further enumeration.
 	// It should be called after iteration is complete to free resources.
 	Close() error
@@ -27,11 +38,17 @@ type Rows interface {
 // Used by mocks to generate Rows with specific test data for each query execution.
 type RowsFactory func() Rows
 
+// ResultFactory is a function type that creates the sql.Result of a write.
+// Used by MockStmt to report specific RowsAffected and LastInsertId values.
+type ResultFactory func() sql.Result
+
 // MockRows implements the Rows interface with in-memory data for testing.
 // It allows simulating database query results without an actual database connection.
 type MockRows struct {
-	data [][]any // Two-dimensional slice containing mock data rows and columns
-	idx  int     // Current row index (0 before first row, 1 after first Next(), etc.)
+	cols []string  // Column names reported by Columns
+	data [][]any   // Two-dimensional slice containing mock data rows and columns
+	idx  int       // Current row index (0 before first row, 1 after first Next(), etc.)
+	sets [][][]any // Additional result sets made current by NextResultSet
 }
 
 // Next advances to the next row of mock data.
@@ -43,22 +60,95 @@ func (r *MockRows) Next() bool {
 }
 
 // Scan copies values from the current mock row into the provided destinations.
-// Currently supports scanning into *int and *string pointers only.
+// Supports *int, *string, and *any pointers, pointers to named or unnamed
+// string-, int-, uint- and float-kind types (e.g. `type Status string`),
+// where numeric strings such as DECIMAL values are parsed, plus any destination implementing
+// sql.Scanner (including the standard sql.Null* types, where a nil mock value
+// yields Valid == false). Mock values implementing driver.Valuer are converted
+// through Value first.
 // The number of destinations must match the number of columns in the current row.
 func (r *MockRows) Scan(dest ...any) error {
 	row := r.data[r.idx-1] // Get current row data (idx is 1-indexed after Next())
 	for i := range dest {
+		v := row[i]
+		if valuer, ok := v.(driver.Valuer); ok {
+			var err error
+			if v, err = valuer.Value(); err != nil {
+				return fmt.Errorf("mock: Value error on column index %d: %w", i, err)
+			}
+		}
+
 		switch d := dest[i].(type) {
+		case sql.Scanner:
+			if err := d.Scan(v); err != nil {
+				return fmt.Errorf("mock: Scan error on column index %d: %w", i, err)
+			}
 		case *int:
-			*d = row[i].(int) // Type assertion for integer columns
+			if n, ok := v.(int); ok {
+				*d = n // Fast path for integer columns
+			} else if err := setNumeric(reflect.ValueOf(d).Elem(), v); err != nil {
+				return fmt.Errorf("mock: Scan error on column index %d: %w", i, err)
+			}
+		case *any:
+			*d = v // Untyped destinations receive the raw mock value
 		case *string:
-			*d = row[i].(string) // Type assertion for string columns
-			// Additional type cases should be added as needed for other column types
+			*d = v.(string) // Type assertion for string columns
+		default:
+			// Named types such as `type Status string` or `type Level int`
+			if err := assignKind(dest[i], v); err != nil {
+				return fmt.Errorf("mock: Scan error on column index %d: %w", i, err)
+			}
 		}
 	}
 	return nil
 }
 
+// assignKind stores v into dest when dest points to a string-, int-, uint-
+// or float-kind type, converting between named and underlying types and
+// parsing numeric strings (see ScanNumeric). Other destinations are left
+// untouched, as before.
+func assignKind(dest, v any) error {
+	ptr := reflect.ValueOf(dest)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() {
+		return nil
+	}
+	elem := ptr.Elem()
+	src := reflect.ValueOf(v)
+
+	switch elem.Kind() {
+	case reflect.String:
+		switch {
+		case src.Kind() == reflect.String:
+			elem.SetString(src.String())
+		case src.Kind() == reflect.Slice && src.Type().Elem().Kind() == reflect.Uint8:
+			elem.SetString(string(src.Bytes()))
+		default:
+			return fmt.Errorf("cannot assign %T to %s", v, elem.Type())
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return setNumeric(elem, v)
+	}
+	return nil
+}
+
+// Columns returns the configured mock column names.
+func (r *MockRows) Columns() ([]string, error) {
+	return r.cols, nil
+}
+
+// NextResultSet switches to the next queued mock result set.
+// Returns false when no further result sets are available.
+func (r *MockRows) NextResultSet() bool {
+	if len(r.sets) == 0 {
+		return false
+	}
+	r.data, r.sets = r.sets[0], r.sets[1:]
+	r.idx = 0
+	return true
+}
+
 // Close implements the Rows interface for MockRows.
 // Since MockRows uses only in-memory data, no cleanup is required.
 func (r *MockRows) Close() error { return nil }
@@ -66,9 +156,10 @@ func (r *MockRows) Close() error { return nil }
 // MockStmt implements a mock prepared statement for testing database interactions.
 // It can simulate delays, errors, and produce configurable result sets.
 type MockStmt struct {
-	Factory RowsFactory   // Function to generate Rows with test data for each query
-	Err     error         // Error to return from QueryContext (nil for successful execution)
-	Delay   time.Duration // Artificial delay to simulate slow database responses
+	Factory       RowsFactory   // Function to generate Rows with test data for each query
+	ResultFactory ResultFactory // Function to generate the result of each ExecContext (nil = MockResult{})
+	Err           error         // Error to return from QueryContext and ExecContext (nil for successful execution)
+	Delay         time.Duration // Artificial delay to simulate slow database responses
 }
 
 // QueryContext executes the mock prepared statement with optional delay and context support.
@@ -92,10 +183,49 @@ func (s *MockStmt) QueryContext(ctx context.Context, args ...any) (Rows, error)
 	return s.Factory(), nil
 }
 
+// QueryRowContext runs QueryContext and reads the first row of the result,
+// so Factory, Err and Delay apply as for QueryContext. An empty result
+// makes Scan return sql.ErrNoRows.
+func (s *MockStmt) QueryRowContext(ctx context.Context, args ...any) Row {
+	return rowFromRows(s.QueryContext(ctx, args...))
+}
+
+// ExecContext executes the mock prepared statement as a write.
+// It honors Delay and Err like QueryContext and reports the result of
+// ResultFactory, or an empty MockResult when it is nil.
+func (s *MockStmt) ExecContext(ctx context.Context, args ...any) (sql.Result, error) {
+	if s.Delay > 0 {
+		select {
+		case <-time.After(s.Delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	if s.ResultFactory != nil {
+		return s.ResultFactory(), nil
+	}
+	return MockResult{}, nil
+}
+
 // Close implements the Stmt interface for MockStmt.
 // No cleanup needed for mock statement.
 func (s *MockStmt) Close() error { return nil }
 
+// MockResult implements sql.Result with fixed values for testing write paths.
+type MockResult struct {
+	LastID   int64 // Value returned by LastInsertId
+	Affected int64 // Value returned by RowsAffected
+}
+
+// LastInsertId returns the configured last insert ID.
+func (r MockResult) LastInsertId() (int64, error) { return r.LastID, nil }
+
+// RowsAffected returns the configured affected row count.
+func (r MockResult) RowsAffected() (int64, error) { return r.Affected, nil }
+
 // MockDB implements a mock database for testing database-dependent code.
 // It maps SQL queries to predefined MockStmt responses, allowing comprehensive
 // testing without a real database connection.
@@ -103,6 +233,19 @@ type MockDB struct {
 	Stmts    map[string]*MockStmt // Query-to-statement mapping for different SQL queries
 	Closed   bool                 // Whether the mock database has been closed
 	Prepares int                  // Counter for PrepareContext calls (useful for assertions)
+	Queries  int                  // Counter for unprepared QueryContext calls
+
+	Execs      []MockExec // Unprepared ExecContext calls, in order
+	ExecResult sql.Result // Result returned by ExecContext (nil = MockResult{})
+	ExecErr    error      // Error returned by ExecContext
+
+	Sessions []*MockSession // Connections pinned with Session, in order
+}
+
+// MockExec records one unprepared MockDB.ExecContext call.
+type MockExec struct {
+	Query string
+	Args  []any
 }
 
 // NewMockDB creates and initializes a new MockDB instance.
@@ -117,10 +260,16 @@ func (m *MockDB) WithStmt(query string, stmt *MockStmt) {
 	m.Stmts[query] = stmt
 }
 
+// errNoMockStmt reports a query without a registered MockStmt. It is kept
+// apart from sql.ErrNoRows so a missing mock never looks like "not found".
+func errNoMockStmt(query string) error {
+	return fmt.Errorf("mysql: no mock statement registered for %q", query)
+}
+
 // PrepareContext simulates preparing a SQL statement in the mock database.
 // If the database is closed, returns context.Canceled error.
-// If no mock statement is registered for the query, returns sql.ErrNoRows.
-// If a registered statement has an error and no factory, returns the error immediately.
+// If no mock statement is registered for the query, returns an error naming it.
+// If a registered statement has an error and neither factory, returns the error immediately.
 func (m *MockDB) PrepareContext(ctx context.Context, query string) (Stmt, error) {
 	if m.Closed {
 		return nil, context.Canceled
@@ -129,10 +278,10 @@ func (m *MockDB) PrepareContext(ctx context.Context, query string) (Stmt, error)
 
 	stmt, ok := m.Stmts[query]
 	if !ok {
-		return nil, sql.ErrNoRows
+		return nil, errNoMockStmt(query)
 	}
 
-	if stmt.Err != nil && stmt.Factory == nil {
+	if stmt.Err != nil && stmt.Factory == nil && stmt.ResultFactory == nil {
 		// Special case: error-only statement (no result rows expected)
 		return nil, stmt.Err
 	}
@@ -140,6 +289,101 @@ func (m *MockDB) PrepareContext(ctx context.Context, query string) (Stmt, error)
 	return stmt, nil
 }
 
+// QueryContext simulates running query without preparing it, using the
+// MockStmt registered for the query text. Errors mirror PrepareContext.
+func (m *MockDB) QueryContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	if m.Closed {
+		return nil, context.Canceled
+	}
+	m.Queries++
+
+	stmt, ok := m.Stmts[query]
+	if !ok {
+		return nil, errNoMockStmt(query)
+	}
+	return stmt.QueryContext(ctx, args...)
+}
+
+// ExecContext records the call and returns ExecErr or ExecResult. When both
+// are unset, the MockStmt registered for the query runs its ExecContext;
+// otherwise MockResult{} is returned. If the database is closed, returns
+// context.Canceled.
+func (m *MockDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if m.Closed {
+		return nil, context.Canceled
+	}
+	m.Execs = append(m.Execs, MockExec{Query: query, Args: args})
+
+	if m.ExecErr != nil {
+		return nil, m.ExecErr
+	}
+	if m.ExecResult != nil {
+		return m.ExecResult, nil
+	}
+	if stmt, ok := m.Stmts[query]; ok {
+		return stmt.ExecContext(ctx, args...)
+	}
+	return MockResult{}, nil
+}
+
+// Session pins a new mock connection (see Params.SessionVars) and records it
+// in Sessions. If the database is closed, returns context.Canceled.
+func (m *MockDB) Session(ctx context.Context) (Session, error) {
+	if m.Closed {
+		return nil, context.Canceled
+	}
+	sess := &MockSession{db: m}
+	m.Sessions = append(m.Sessions, sess)
+	return sess, nil
+}
+
+// MockSession is a connection pinned by MockDB.Session. It records every
+// statement run on it; queries are answered by the MockStmt registered on
+// the MockDB for the query text, and writes return ExecErr or ExecResult.
+type MockSession struct {
+	db         *MockDB
+	Statements []string // Query and Exec texts run on this session, in order
+	Args       [][]any  // Arguments of each statement, parallel to Statements
+	Closed     bool     // Returned to the pool with Close
+	Discarded  bool     // Closed with Discard
+}
+
+// QueryContext records query and runs the MockStmt registered for it.
+func (s *MockSession) QueryContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	s.Statements = append(s.Statements, query)
+	s.Args = append(s.Args, args)
+	stmt, ok := s.db.Stmts[query]
+	if !ok {
+		return nil, errNoMockStmt(query)
+	}
+	return stmt.QueryContext(ctx, args...)
+}
+
+// ExecContext records query and returns the MockDB's ExecErr or ExecResult.
+func (s *MockSession) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	s.Statements = append(s.Statements, query)
+	s.Args = append(s.Args, args)
+	if s.db.ExecErr != nil {
+		return nil, s.db.ExecErr
+	}
+	if s.db.ExecResult != nil {
+		return s.db.ExecResult, nil
+	}
+	return MockResult{}, nil
+}
+
+// Close marks the session as returned to the pool.
+func (s *MockSession) Close() error {
+	s.Closed = true
+	return nil
+}
+
+// Discard marks the session as closed without reuse.
+func (s *MockSession) Discard() error {
+	s.Discarded = true
+	return nil
+}
+
 // Close marks the mock database as closed, preventing further operations.
 // Subsequent PrepareContext calls will return context.Canceled.
 func (m *MockDB) Close() error {
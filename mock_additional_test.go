@@ -3,7 +3,9 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -40,6 +42,57 @@ func TestMockStmt_ReturnsError(t *testing.T) {
 	}
 }
 
+func TestMockStmt_QueryRowContext(t *testing.T) {
+	stmt := &MockStmt{Factory: func() Rows {
+		return &MockRows{data: [][]any{{1, "first"}, {2, "second"}}}
+	}}
+	var (
+		id   int
+		name string
+	)
+	if err := stmt.QueryRowContext(context.Background()).Scan(&id, &name); err != nil || id != 1 || name != "first" {
+		t.Fatalf("expected the first row, got %d %q (%v)", id, name, err)
+	}
+
+	stmt.Factory = func() Rows { return &MockRows{} }
+	if err := stmt.QueryRowContext(context.Background()).Scan(&id); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for an empty result, got %v", err)
+	}
+
+	// Query errors are deferred to Scan
+	stmt.Err = errors.New("boom")
+	if err := stmt.QueryRowContext(context.Background()).Scan(&id); !errors.Is(err, stmt.Err) {
+		t.Fatalf("expected the query error from Scan, got %v", err)
+	}
+}
+
+func TestMockStmt_ExecContext(t *testing.T) {
+	stmt := &MockStmt{ResultFactory: func() sql.Result { return MockResult{LastID: 7, Affected: 2} }}
+	res, err := stmt.ExecContext(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id, _ := res.LastInsertId(); id != 7 {
+		t.Fatalf("expected LastInsertId 7, got %d", id)
+	}
+	if n, _ := res.RowsAffected(); n != 2 {
+		t.Fatalf("expected RowsAffected 2, got %d", n)
+	}
+
+	stmt.Err = errors.New("duplicate entry")
+	if _, err := stmt.ExecContext(context.Background()); !errors.Is(err, stmt.Err) {
+		t.Fatalf("expected the configured error, got %v", err)
+	}
+
+	// Delay is honored like QueryContext
+	stmt = &MockStmt{Delay: time.Second}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := stmt.ExecContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
 func TestMockStmt_Close(t *testing.T) {
 	stmt := &MockStmt{}
 	if err := stmt.Close(); err != nil {
@@ -60,8 +113,8 @@ func TestMockDB_PrepareContext_Closed(t *testing.T) {
 func TestMockDB_PrepareContext_NoStmt(t *testing.T) {
 	db := NewMockDB()
 	_, err := db.PrepareContext(context.Background(), "SELECT 1")
-	if !errors.Is(err, sql.ErrNoRows) {
-		t.Fatalf("expected sql.ErrNoRows, got %v", err)
+	if err == nil || errors.Is(err, sql.ErrNoRows) || !strings.Contains(err.Error(), `"SELECT 1"`) {
+		t.Fatalf("expected an error naming the unregistered statement, got %v", err)
 	}
 }
 
@@ -85,3 +138,190 @@ func TestMockDB_Close(t *testing.T) {
 		t.Fatalf("expected Closed to be true")
 	}
 }
+
+func TestMockRows_NextResultSet(t *testing.T) {
+	rows := &MockRows{
+		data: [][]any{{1}},
+		sets: [][][]any{{{2}, {3}}},
+	}
+
+	var first []int
+	for rows.Next() {
+		var v int
+		_ = rows.Scan(&v)
+		first = append(first, v)
+	}
+	if !rows.NextResultSet() {
+		t.Fatalf("expected a second result set")
+	}
+	var second []int
+	for rows.Next() {
+		var v int
+		_ = rows.Scan(&v)
+		second = append(second, v)
+	}
+	if rows.NextResultSet() {
+		t.Fatalf("expected no further result sets")
+	}
+
+	if len(first) != 1 || first[0] != 1 {
+		t.Fatalf("unexpected first result set: %v", first)
+	}
+	if len(second) != 2 || second[0] != 2 || second[1] != 3 {
+		t.Fatalf("unexpected second result set: %v", second)
+	}
+}
+
+func TestMockRows_ScanNullTypes(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := &MockRows{data: [][]any{
+		{"s", int64(1), int32(2), int16(3), byte(4), 1.5, true, ts},
+		{nil, nil, nil, nil, nil, nil, nil, nil},
+	}}
+
+	var (
+		ns   sql.NullString
+		ni64 sql.NullInt64
+		ni32 sql.NullInt32
+		ni16 sql.NullInt16
+		nb   sql.NullByte
+		nf   sql.NullFloat64
+		nbo  sql.NullBool
+		nt   sql.NullTime
+	)
+	scan := func() {
+		t.Helper()
+		if !rows.Next() {
+			t.Fatalf("expected a row")
+		}
+		if err := rows.Scan(&ns, &ni64, &ni32, &ni16, &nb, &nf, &nbo, &nt); err != nil {
+			t.Fatalf("unexpected scan error: %v", err)
+		}
+	}
+
+	scan()
+	if !ns.Valid || ns.String != "s" ||
+		!ni64.Valid || ni64.Int64 != 1 ||
+		!ni32.Valid || ni32.Int32 != 2 ||
+		!ni16.Valid || ni16.Int16 != 3 ||
+		!nb.Valid || nb.Byte != 4 ||
+		!nf.Valid || nf.Float64 != 1.5 ||
+		!nbo.Valid || !nbo.Bool ||
+		!nt.Valid || !nt.Time.Equal(ts) {
+		t.Fatalf("unexpected non-null values: %+v %+v %+v %+v %+v %+v %+v %+v", ns, ni64, ni32, ni16, nb, nf, nbo, nt)
+	}
+
+	scan()
+	if ns.Valid || ni64.Valid || ni32.Valid || ni16.Valid || nb.Valid || nf.Valid || nbo.Valid || nt.Valid {
+		t.Fatalf("expected all values to be null")
+	}
+}
+
+// upperValuer is a driver.Valuer used to verify mock values are converted.
+type upperValuer string
+
+func (v upperValuer) Value() (driver.Value, error) { return strings.ToUpper(string(v)), nil }
+
+// failingScanner is an sql.Scanner that always rejects the value.
+type failingScanner struct{}
+
+func (failingScanner) Scan(any) error { return errors.New("boom") }
+
+func TestMockRows_ScanValuerAndScanner(t *testing.T) {
+	rows := &MockRows{data: [][]any{{upperValuer("abc"), "x"}}}
+	rows.Next()
+
+	var s sql.NullString
+	var fs failingScanner
+	if err := rows.Scan(&s, &sql.NullString{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !s.Valid || s.String != "ABC" {
+		t.Fatalf("expected valuer to be converted, got %+v", s)
+	}
+
+	if err := rows.Scan(&sql.NullString{}, &fs); err == nil || !strings.Contains(err.Error(), "column index 1") {
+		t.Fatalf("expected scanner error for column 1, got %v", err)
+	}
+}
+
+type mockStatus string
+
+type mockLevel int8
+
+type mockFlags uint16
+
+func TestMockRows_ScanNamedKinds(t *testing.T) {
+	rows := &MockRows{data: [][]any{{"active", int64(3), 7, []byte("raw")}}}
+	rows.Next()
+
+	var status mockStatus
+	var level mockLevel
+	var flags mockFlags
+	var raw mockStatus
+	if err := rows.Scan(&status, &level, &flags, &raw); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status != "active" || level != 3 || flags != 7 || raw != "raw" {
+		t.Fatalf("unexpected values: %q %d %d %q", status, level, flags, raw)
+	}
+}
+
+func TestMockRows_ScanNamedKindsErrors(t *testing.T) {
+	rows := &MockRows{data: [][]any{{300, -1, 1.5}}}
+	rows.Next()
+
+	var level mockLevel
+	if err := rows.Scan(&level); err == nil || !strings.Contains(err.Error(), "overflows") {
+		t.Fatalf("expected overflow error, got %v", err)
+	}
+
+	var flags mockFlags
+	if err := rows.Scan(new(any), &flags); err == nil || !strings.Contains(err.Error(), "column index 1") {
+		t.Fatalf("expected negative-to-uint error for column 1, got %v", err)
+	}
+
+	var status mockStatus
+	if err := rows.Scan(new(any), new(any), &status); err == nil {
+		t.Fatal("expected float-to-string assignment to fail")
+	}
+}
+
+func TestMockDB_ExecContextRecordsCalls(t *testing.T) {
+	db := NewMockDB()
+	db.ExecResult = MockResult{LastID: 7, Affected: 2}
+
+	res, err := db.ExecContext(context.Background(), "UPDATE users SET name = ? WHERE id = ?", "bob", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n, _ := res.RowsAffected(); n != 2 {
+		t.Fatalf("expected 2 rows affected, got %d", n)
+	}
+	if len(db.Execs) != 1 || db.Execs[0].Query != "UPDATE users SET name = ? WHERE id = ?" || len(db.Execs[0].Args) != 2 {
+		t.Fatalf("unexpected recorded calls: %+v", db.Execs)
+	}
+
+	db.ExecErr = errors.New("boom")
+	if _, err := db.ExecContext(context.Background(), "DELETE FROM users"); err == nil || err.Error() != "boom" {
+		t.Fatalf("expected configured error, got %v", err)
+	}
+	if len(db.Execs) != 2 {
+		t.Fatalf("expected failed call to be recorded, got %d", len(db.Execs))
+	}
+
+	_ = db.Close()
+	if _, err := db.ExecContext(context.Background(), "DELETE FROM users"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled after Close, got %v", err)
+	}
+}
+
+func TestMockDB_ExecContextDefaultResult(t *testing.T) {
+	res, err := NewMockDB().ExecContext(context.Background(), "DELETE FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := res.(MockResult); !ok {
+		t.Fatalf("expected MockResult, got %T", res)
+	}
+}
@@ -1,25 +1,77 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // MySQL manages a DB connection along with caches, codecs, and prepared statements.
 // It is safe for concurrent use.
 type MySQL struct {
-	DB           DB // Underlying SQL database connection.
-	db           *sql.DB
-	dbName       string           // Default database name.
-	prepare      map[string]Stmt  // Cached prepared statements.
-	stop         chan struct{}    // Shutdown signal channel.
-	mx           sync.RWMutex     // Guards internal state.
-	cache        Storage          // External cache for L2 results.
-	inMemory     *InMemoryStorage // In-memory cache for L1 results.
-	mutex        Mutex            // Keyed mutex for cache stampede protection.
-	codec        Codec            // Codec used for cache serialization.
-	CacheEnabled bool             // Whether caching is enabled.
+	DB                  DB // Underlying SQL database connection.
+	db                  *sql.DB
+	sharedDB            bool            // db is owned by the caller and left open on Close.
+	dbName              string          // Default database name.
+	prepare             map[string]Stmt // Cached prepared statements.
+	prepareLRU          stmtLRU         // Recency order of prepared statements (when capped).
+	maxPrepared         int             // Maximum cached prepared statements (0 = unlimited).
+	prepareTimeout      time.Duration   // Deadline for preparing statements (0 = query timeout).
+	stop                chan struct{}   // Shutdown signal channel.
+	lifecycle           sync.RWMutex    // Orders query registration against shutdown.
+	closed              atomic.Bool     // Set once Close or Shutdown begins.
+	inflight            sync.WaitGroup  // Queries currently executing.
+	querySlots          chan struct{}   // Semaphore bounding concurrent statements (nil = unbounded).
+	activeQueries       atomic.Int64    // Statements currently running against the database.
+	unhealthy           atomic.Bool     // Last background health check failed.
+	healthCheck         *healthChecker  // Background ping loop (nil when disabled).
+	cacheStats          cacheCounters   // Cache hit and miss counters.
+	mx                  sync.RWMutex    // Guards internal state.
+	cache               Storage         // External cache for L2 results.
+	cacheWriter         *cacheWriter    // Background L2 writer (nil when writes are synchronous).
+	inMemory            L1Cache         // In-memory cache for L1 results (Options.L1 or an InMemoryStorage).
+	mutex               Mutex           // Keyed mutex for cache stampede protection.
+	codec               Codec           // Codec used for cache serialization.
+	tagCodec            bool            // Tag client-codec values with the codec name.
+	legacyCodecs        []Codec         // Fallback decoders for entries written by previous codecs.
+	cacheVersion        string          // Prefix for generated cache keys.
+	keySeparator        byte            // Separator between query and arguments in generated keys (0 = DefaultKeySeparator).
+	keyHasher           KeyHasher       // Condenses generated cache keys (nil = raw keys).
+	maxKeyBytes         int             // Cap on generated cache key length (0 = unlimited).
+	detectKeyCollisions bool            // Fingerprint L2 entries and verify them on read.
+	compressOver        int             // Gzip L2 values above this size (0 = never).
+	storeMetadata       bool            // Wrap L2 values in a metadata envelope.
+	disableL1           bool            // Skip L1 when an external cache is configured.
+	copyOnRead          bool            // Deep-copy values crossing the L1 boundary.
+	hashMetadataQuery   bool            // Hash the query recorded in the envelope.
+	maxValueBytes       int             // Skip caching results larger than this when serialized (0 = unlimited).
+	errorMapper         ErrorMapper     // Converts driver errors; nil uses DefaultErrorMapper.
+	recoverCallback     bool            // Turn callback panics into ErrPanic.
+	keepResultOnError   bool            // Pass a callback's result through alongside its error.
+	failOnCacheError    bool            // Return cache/mutex backend errors instead of degrading to the DB.
+	logger              *slog.Logger    // Operational log output; nil uses slog.Default().
+	normalizeQueries    bool            // Collapse whitespace before prepared statement lookup.
+	strictArgs          bool            // Check placeholder count against len(Args) before executing.
+	disablePrepare      bool            // Run queries with DB.QueryContext instead of preparing them.
+	onTableWrite        func(string)    // Invoked after write helpers modify a table.
+	cacheEnabled        atomic.Bool     // Whether caching is enabled; toggled at runtime by SetCacheEnabled.
+
+	onCacheDecodeError func(key string, err error) // Invoked when a cached entry cannot be decoded.
+}
+
+// newL1Storage creates the in-memory L1 cache bounded by sizeMB megabytes of
+// decoded values rather than by item count, so large result objects cannot
+// exhaust memory even when their serialized form is small.
+func newL1Storage(sizeMB int, ttlCheck time.Duration) *InMemoryStorage {
+	return NewInMemoryStorageWithConfig(StorageConfig{
+		CleanupInterval: ttlCheck,
+		MaxBytes:        sizeMB << 20,
+	})
 }
 
 // sqlOpen is a test seam that defaults to sql.Open.
@@ -27,36 +79,121 @@ var sqlOpen = sql.Open
 
 // New creates a MySQL client using the provided options.
 // It validates connectivity via Ping and configures the connection pool.
+// The ping is bounded by Options.Timeout, so an unreachable host fails
+// startup instead of hanging until the OS gives up on the connection.
 func New(opts ...Options) (*MySQL, error) {
+	return NewContext(context.Background(), opts...)
+}
 
+// NewContext is like New but also abandons the initial ping when ctx is
+// cancelled or its deadline passes, whichever comes before Options.Timeout.
+func NewContext(ctx context.Context, opts ...Options) (*MySQL, error) {
+	if err := validatePool(opts...); err != nil {
+		return nil, err
+	}
 	opt := defaultOptions(opts...)
 
 	// Open a connection to the MySQL database.
 	db, err := sqlOpen("mysql", opt.ConnectionString)
 	if err != nil {
-		return nil, err // Return error if opening the connection fails.
+		// Return error if opening the connection fails; never leak the password.
+		return nil, fmt.Errorf("mysql: open %s: %w", RedactDSN(opt.ConnectionString), err)
 	}
 
 	// Configure connection pool settings.
-	db.SetMaxOpenConns(opt.MaxConnections) // Set max open connections.
-	db.SetMaxIdleConns(opt.MaxConnections) // Set max idle connections.
-	db.SetConnMaxLifetime(time.Minute * 5) // Set connection max lifetime.
+	db.SetMaxOpenConns(opt.MaxConnections)     // Set max open connections.
+	db.SetMaxIdleConns(opt.MaxConnections)     // Set max idle connections.
+	db.SetConnMaxLifetime(opt.ConnMaxLifetime) // Set connection max lifetime.
+	db.SetConnMaxIdleTime(opt.ConnMaxIdleTime) // Set connection max idle time.
 
-	// Verify the database connection.
-	err = db.Ping()
-	if err != nil {
-		return nil, err // Return error if connection verification fails.
+	// Verify the database connection within the connect timeout.
+	if err := pingDB(ctx, db, time.Duration(opt.Timeout)*time.Second); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("mysql: ping %s: %w", RedactDSN(opt.ConnectionString), err)
+	}
+
+	return newClient(db, opt), nil
+}
+
+// pingDB pings db, giving up after timeout (0 = only when ctx ends).
+func pingDB(parent context.Context, db *sql.DB, timeout time.Duration) error {
+	ctx := parent
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(parent, timeout)
+		defer cancel()
+	}
+	err := db.PingContext(ctx)
+	// Name the bound when it was ours rather than the caller's that expired
+	if err != nil && parent.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("no response within %s: %w", timeout, ctx.Err())
+	}
+	return err
+}
+
+// NewWithDB creates a MySQL client on top of a *sql.DB managed by the caller,
+// for applications that already own a connection pool (migrations, other
+// queries). It does not open, ping, or reconfigure db; connection options in
+// opts are ignored while cache, codec, mutex, and other client options apply.
+// Options.Database should name the default database used in cache keys.
+//
+// Close releases the client's prepared statements but leaves db open, unless
+// Options.OwnsDB is set, in which case db is closed as well.
+func NewWithDB(db *sql.DB, opts ...Options) (*MySQL, error) {
+	if db == nil {
+		return nil, errors.New("mysql: NewWithDB requires a non-nil *sql.DB")
 	}
 
-	// Initialize MySQL client state.
+	opt := defaultOptions(opts...)
+	core := newClient(db, opt)
+	core.sharedDB = !opt.OwnsDB
+	return core, nil
+}
+
+// newClient initializes client state around an open database.
+func newClient(db *sql.DB, opt Options) *MySQL {
 	core := &MySQL{
-		DB:           &sqlDB{db: db},
-		db:           db,
-		dbName:       opt.Database,
-		inMemory:     NewInMemoryStorage(opt.CacheSize, opt.CacheTTLCheck),
-		prepare:      make(map[string]Stmt), // Initialize map for prepared statements.
-		CacheEnabled: opt.CacheEnabled,      // Enable caching based on option.
-		stop:         make(chan struct{}, 1),
+		DB:                  &sqlDB{db: db},
+		db:                  db,
+		dbName:              opt.Database,
+		inMemory:            opt.L1,
+		prepare:             make(map[string]Stmt), // Initialize map for prepared statements.
+		errorMapper:         opt.ErrorMapper,
+		recoverCallback:     opt.RecoverCallback,
+		tagCodec:            opt.TagCacheCodec,
+		legacyCodecs:        opt.LegacyCodecs,
+		keepResultOnError:   opt.KeepResultOnCallbackError,
+		failOnCacheError:    opt.DegradeOnCacheError != nil && !*opt.DegradeOnCacheError,
+		logger:              opt.Logger,
+		normalizeQueries:    opt.NormalizeQueries,
+		strictArgs:          opt.StrictArgs,
+		disablePrepare:      opt.DisablePrepare,
+		maxPrepared:         opt.MaxPreparedStatements,
+		prepareTimeout:      opt.PrepareTimeout,
+		onTableWrite:        opt.OnTableWrite,
+		onCacheDecodeError:  opt.OnCacheDecodeError,
+		compressOver:        opt.CompressOverBytes,
+		storeMetadata:       opt.StoreMetadata,
+		disableL1:           opt.DisableL1,
+		copyOnRead:          opt.CopyOnRead == nil || *opt.CopyOnRead,
+		hashMetadataQuery:   opt.HashMetadataQuery,
+		cacheVersion:        opt.CacheVersion,
+		keySeparator:        opt.CacheKeySeparator,
+		keyHasher:           opt.KeyHasher,
+		maxKeyBytes:         opt.MaxKeyBytes,
+		detectKeyCollisions: opt.DetectKeyCollisions,
+		maxValueBytes:       opt.MaxValueBytes,
+		stop:                make(chan struct{}, 1),
+	}
+
+	core.cacheEnabled.Store(opt.CacheEnabled)
+
+	if opt.MaxConcurrentQueries > 0 {
+		core.querySlots = make(chan struct{}, opt.MaxConcurrentQueries)
+	}
+
+	if core.inMemory == nil {
+		core.inMemory = newL1Storage(opt.CacheSize, opt.CacheTTLCheck)
 	}
 
 	if opt.Codec != nil {
@@ -76,19 +213,47 @@ func New(opts ...Options) (*MySQL, error) {
 	// Assign the provided cache or a new in-memory storage if none is provided.
 	if opt.Cache != nil {
 		core.cache = opt.Cache
+		if opt.CacheBreakerThreshold > 0 {
+			// Stop paying backend timeouts while the cache is down
+			core.cache = newBreakerStorage(opt.Cache, opt.CacheBreakerThreshold, opt.CacheBreakerCooldown, realClock{})
+		}
+		if opt.AsyncCacheWrites {
+			core.cacheWriter = newCacheWriter(core.cache)
+		}
 	}
 
-	return core, nil
+	if opt.HealthCheckInterval > 0 && db != nil {
+		core.healthCheck = core.startHealthCheck(opt.HealthCheckInterval, realClock{})
+	}
 
+	return core
+}
+
+// CacheEnabled reports whether query results are cached.
+func (c *MySQL) CacheEnabled() bool {
+	return c.cacheEnabled.Load()
+}
+
+// SetCacheEnabled turns result caching on or off at runtime, e.g. to bypass
+// a misbehaving cache during an incident. It is safe to call while queries
+// run; entries already cached are kept and served again once re-enabled.
+// Like Options.CacheEnabled it governs clients with an external Cache;
+// the in-memory cache of clients without one follows Params alone.
+func (c *MySQL) SetCacheEnabled(enabled bool) {
+	c.cacheEnabled.Store(enabled)
 }
 
 func (c *MySQL) GetDB() *sql.DB {
 	return c.db
 }
 
-// Close releases prepared statements and closes the underlying database.
-// It is safe to call multiple times.
+// Close releases prepared statements and closes the underlying database
+// (a *sql.DB passed to NewWithDB is left open unless Options.OwnsDB is set).
+// Queries issued afterwards fail with ErrClosed; queries already running are
+// aborted (use Shutdown to let them finish). It is safe to call multiple times.
 func (c *MySQL) Close() {
+	c.markClosed()
+
 	select {
 	case <-c.stop:
 	default:
@@ -98,12 +263,23 @@ func (c *MySQL) Close() {
 		}
 	}
 
+	if c.healthCheck != nil {
+		c.healthCheck.stop()
+	}
+
+	// Let pending asynchronous cache writes land before releasing resources
+	if c.cacheWriter != nil {
+		c.cacheWriter.close()
+	}
+
+	c.mx.Lock()
 	for _, stmt := range c.prepare {
 		if stmt != nil {
 			_ = stmt.Close()
 		}
 	}
-	if c.DB != nil {
+	c.mx.Unlock()
+	if c.DB != nil && !c.sharedDB {
 		_ = c.DB.Close()
 	}
 }
@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -16,12 +19,21 @@ func (s *closeStmt) QueryContext(ctx context.Context, args ...any) (Rows, error)
 	return nil, nil
 }
 
+func (s *closeStmt) QueryRowContext(ctx context.Context, args ...any) Row {
+	return rowFromRows(s.QueryContext(ctx, args...))
+}
+
+func (s *closeStmt) ExecContext(ctx context.Context, args ...any) (sql.Result, error) {
+	return nil, nil
+}
+
 func (s *closeStmt) Close() error {
 	s.closed = true
 	return nil
 }
 
 type closeDB struct {
+	noDirectStatements
 	closed bool
 }
 
@@ -60,6 +72,57 @@ func TestNew_PingError(t *testing.T) {
 	}
 }
 
+func TestNew_PingTimeout(t *testing.T) {
+	var db *sql.DB
+	origOpen := sqlOpen
+	sqlOpen = func(driverName, dataSourceName string) (*sql.DB, error) {
+		db = sql.OpenDB(&testConnector{pingDelay: time.Minute})
+		return db, nil
+	}
+	t.Cleanup(func() { sqlOpen = origOpen })
+
+	start := time.Now()
+	_, err := New(Options{Username: "u", Password: "p", Database: "db", Timeout: 1})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected New to give up after the 1s timeout, took %s", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "no response within 1s") {
+		t.Fatalf("expected a ping timeout error, got %v", err)
+	}
+	if err := db.Ping(); err == nil {
+		t.Fatal("expected the pool to be closed after the failed ping")
+	}
+}
+
+func TestNewContext_CancelledPing(t *testing.T) {
+	origOpen := sqlOpen
+	sqlOpen = func(driverName, dataSourceName string) (*sql.DB, error) {
+		return sql.OpenDB(&testConnector{pingDelay: time.Minute}), nil
+	}
+	t.Cleanup(func() { sqlOpen = origOpen })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := NewContext(ctx, Options{Username: "u", Password: "p", Database: "db"})
+	if !errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "no response within") {
+		t.Fatalf("expected the caller's deadline to end the ping, got %v", err)
+	}
+}
+
+func TestNew_InvalidPoolLifetimes(t *testing.T) {
+	origOpen := sqlOpen
+	sqlOpen = func(driverName, dataSourceName string) (*sql.DB, error) {
+		t.Fatal("expected validation to fail before opening the database")
+		return nil, nil
+	}
+	t.Cleanup(func() { sqlOpen = origOpen })
+
+	_, err := New(Options{Database: "db", ConnMaxLifetime: time.Minute, ConnMaxIdleTime: time.Hour})
+	if err == nil || !strings.Contains(err.Error(), "exceeds ConnMaxLifetime") {
+		t.Fatalf("expected lifetime validation error, got %v", err)
+	}
+}
+
 func TestNew_Success(t *testing.T) {
 	origOpen := sqlOpen
 	sqlOpen = func(driverName, dataSourceName string) (*sql.DB, error) {
@@ -83,7 +146,7 @@ func TestNew_Success(t *testing.T) {
 	if client.DB == nil || client.inMemory == nil {
 		t.Fatalf("expected DB and in-memory cache to be initialized")
 	}
-	if !client.CacheEnabled {
+	if !client.CacheEnabled() {
 		t.Fatalf("expected CacheEnabled to be true")
 	}
 	if client.dbName != "db" {
@@ -143,3 +206,183 @@ func TestMySQL_Close(t *testing.T) {
 		t.Fatalf("expected DB to be closed")
 	}
 }
+
+func TestNew_DegradeOnCacheError(t *testing.T) {
+	origOpen := sqlOpen
+	sqlOpen = func(driverName, dataSourceName string) (*sql.DB, error) {
+		return newTestSQLDB(nil), nil
+	}
+	t.Cleanup(func() { sqlOpen = origOpen })
+
+	disabled := false
+	for _, tc := range []struct {
+		opt  *bool
+		fail bool
+	}{{nil, false}, {&disabled, true}} {
+		client, err := New(Options{Username: "u", Password: "p", Database: "db", DegradeOnCacheError: tc.opt})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if client.failOnCacheError != tc.fail {
+			t.Fatalf("DegradeOnCacheError=%v: failOnCacheError = %v, want %v", tc.opt, client.failOnCacheError, tc.fail)
+		}
+		client.Close()
+	}
+}
+
+func TestNew_CopyOnReadDefaultsOn(t *testing.T) {
+	origOpen := sqlOpen
+	sqlOpen = func(driverName, dataSourceName string) (*sql.DB, error) {
+		return newTestSQLDB(nil), nil
+	}
+	t.Cleanup(func() { sqlOpen = origOpen })
+
+	disabled := false
+	for _, tc := range []struct {
+		opt  *bool
+		copy bool
+	}{{nil, true}, {&disabled, false}} {
+		client, err := New(Options{Username: "u", Password: "p", Database: "db", CopyOnRead: tc.opt})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if client.copyOnRead != tc.copy {
+			t.Fatalf("CopyOnRead=%v: copyOnRead = %v, want %v", tc.opt, client.copyOnRead, tc.copy)
+		}
+		client.Close()
+	}
+}
+
+func TestNewWithDB_SharedLifecycle(t *testing.T) {
+	origOpen := sqlOpen
+	sqlOpen = func(driverName, dataSourceName string) (*sql.DB, error) {
+		t.Fatal("NewWithDB must not open a new pool")
+		return nil, nil
+	}
+	t.Cleanup(func() { sqlOpen = origOpen })
+
+	shared := newTestSQLDB(nil)
+	defer shared.Close()
+
+	client, err := NewWithDB(shared, Options{Database: "db", CacheEnabled: true, Codec: stubCodec{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.GetDB() != shared || client.dbName != "db" || !client.CacheEnabled() {
+		t.Fatalf("expected client to wrap the shared DB with options applied")
+	}
+	if _, ok := client.codec.(stubCodec); !ok {
+		t.Fatalf("expected custom codec to be used")
+	}
+
+	res, qerr := Query(client, Params{Query: "SELECT value"}, func(rows Rows) (*string, *MySQLError) {
+		var v string
+		rows.Next()
+		_ = rows.Scan(&v)
+		return &v, nil
+	})
+	if qerr != nil || *res != "ok" {
+		t.Fatalf("expected query through shared DB, got %v %+v", res, qerr)
+	}
+
+	client.Close()
+	if err := shared.Ping(); err != nil {
+		t.Fatalf("expected shared DB to stay open after Close, got %v", err)
+	}
+
+	// A second client over the same pool keeps working
+	other, _ := NewWithDB(shared)
+	defer other.Close()
+	if err := other.GetDB().Ping(); err != nil {
+		t.Fatalf("expected shared DB to be usable, got %v", err)
+	}
+}
+
+func TestNewWithDB_OwnsDB(t *testing.T) {
+	db := newTestSQLDB(nil)
+	client, err := NewWithDB(db, Options{OwnsDB: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.Close()
+	if err := db.Ping(); err == nil {
+		t.Fatalf("expected owned DB to be closed with the client")
+	}
+
+	if _, err := NewWithDB(nil); err == nil {
+		t.Fatalf("expected error for nil DB")
+	}
+}
+
+func TestPrepareTimeout_FiresIndependently(t *testing.T) {
+	scan := func(rows Rows) (*string, *MySQLError) {
+		var v string
+		rows.Next()
+		_ = rows.Scan(&v)
+		return &v, nil
+	}
+
+	// A prepare slower than PrepareTimeout fails even with a generous query timeout
+	slow := newTestSQLDBWithPrepareDelay(time.Second)
+	client, _ := NewWithDB(slow, Options{PrepareTimeout: 20 * time.Millisecond, OwnsDB: true})
+	start := time.Now()
+	_, qerr := Query(client, Params{Query: "SELECT value", Timeout: 10 * time.Second}, scan)
+	if !errors.Is(qerr, ErrTimeout) {
+		t.Fatalf("expected ErrTimeout from the prepare deadline, got %+v", qerr)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected the prepare deadline to fire early, took %v", elapsed)
+	}
+	client.Close()
+
+	// A prepare longer than the query timeout does not consume the query budget
+	delayed := newTestSQLDBWithPrepareDelay(60 * time.Millisecond)
+	client, _ = NewWithDB(delayed, Options{PrepareTimeout: time.Second, OwnsDB: true})
+	defer client.Close()
+	res, qerr := Query(client, Params{Query: "SELECT value", Timeout: 40 * time.Millisecond}, scan)
+	if qerr != nil || *res != "ok" {
+		t.Fatalf("expected query to succeed after a slow prepare, got %v %+v", res, qerr)
+	}
+}
+
+func TestSetCacheEnabled_ConcurrentToggle(t *testing.T) {
+	var calls atomic.Int64
+	db := NewMockDB()
+	db.WithStmt("SELECT name FROM users", &MockStmt{Factory: func() Rows {
+		calls.Add(1)
+		return &MockRows{data: [][]any{{"alice"}}}
+	}})
+	client, cleanup := newExternalClient(db, newFakeCache())
+	defer cleanup()
+
+	params := Params{Query: "SELECT name FROM users", CacheDelay: time.Minute, NodeCacheDelay: time.Minute}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if res, err := Query(client, params, scanStrings); err != nil || (*res)[0] != "alice" {
+					t.Errorf("unexpected result %v, %v", res, err)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		client.SetCacheEnabled(i%2 == 0)
+	}
+	wg.Wait()
+
+	client.SetCacheEnabled(false)
+	if client.CacheEnabled() {
+		t.Fatal("expected caching to be disabled")
+	}
+	before := calls.Load()
+	if _, err := Query(client, params, scanStrings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls.Load() != before+1 {
+		t.Fatal("expected a disabled cache to query the database")
+	}
+}
@@ -2,6 +2,8 @@ package mysql
 
 import (
 	"fmt"
+	"log/slog"
+	"net/url"
 	"time"
 )
 
@@ -9,6 +11,8 @@ import (
 // Implementations can be used for caching, persistence, or other storage needs.
 type Storage interface {
 	// Get retrieves a value by its key. Returns an error if key doesn't exist or has expired.
+	// Misses should be reported as ErrNotFound (or an error wrapping it); any other
+	// error is treated as a backend failure (see Options.DegradeOnCacheError).
 	Get(key string) ([]byte, error)
 
 	// Set stores a key-value pair with optional expiration.
@@ -52,31 +56,96 @@ type Options struct {
 	Port     int    // TCP port number (default: 3306)
 
 	// Connection pooling
-	MaxConnections int // Maximum number of open connections (0 = driver default)
+	MaxConnections       int           // Maximum number of open connections (0 = driver default)
+	MaxConcurrentQueries int           // Maximum statements running at once; further cache misses queue until their deadline (0 = unlimited)
+	ConnMaxLifetime      time.Duration // Maximum time a connection is reused (default: 5 minutes; negative = no limit)
+	ConnMaxIdleTime      time.Duration // Maximum time a connection stays idle in the pool (0 = no limit); must not exceed ConnMaxLifetime when both are set
+
+	// Health checking
+	HealthCheckInterval time.Duration // Ping the database in the background this often and track the result in Healthy and Status (0 = disabled)
+
+	// Prepared statements
+	NormalizeQueries      bool          // Collapse whitespace in query text before prepared statement caching
+	MaxPreparedStatements int           // Maximum cached prepared statements, LRU-evicted (0 = unlimited)
+	PrepareTimeout        time.Duration // Deadline for preparing a statement, separate from the query timeout (0 = same as the query timeout)
+	StrictArgs            bool          // Verify that len(Args) matches the '?' placeholders of Params.Query before executing
+	DisablePrepare        bool          // Send queries unprepared with client-side interpolation (DSN "interpolateParams=true", also added to a ConnectionString), for proxies without prepared statement support
+	MultiStatements       bool          // Allow several ';'-separated statements in one ExecRaw call (DSN "multiStatements=true")
 
 	// Character set configuration
 	Charset   string // Connection charset (default: "utf8mb4")
 	Collation string // Connection collation (default: "utf8mb4_unicode_ci")
 
+	// Time handling
+	Location *time.Location // Zone for DATETIME/TIMESTAMP values parsed by the driver (DSN "loc"; nil = driver default, UTC)
+
 	// Timeout settings (in seconds)
-	Timeout      int // Connection timeout (default: 30)
+	Timeout      int // Connection timeout, also bounding the initial ping in New (default: 30)
 	ReadTimeout  int // Read operation timeout (default: 30)
 	WriteTimeout int // Write operation timeout (default: 30)
 
 	// Cache configuration
 	Cache         Storage       // Custom cache implementation (nil uses default in-memory cache)
+	L1            L1Cache       // Custom in-process cache for decoded results (nil = InMemoryStorage bounded by CacheSize)
 	CacheEnabled  bool          // Enable query caching (default: false)
 	CacheSize     int           // Maximum cache size in megabytes (default: 10)
 	CacheTTLCheck time.Duration // Interval for cache cleanup (default: 5 minutes)
 
+	// Cache writes
+	AsyncCacheWrites bool // Write L2 cache entries from a background worker pool instead of the query path
+
+	// Cache failure handling
+	DegradeOnCacheError   *bool         // Query the database directly when the cache or mutex backend fails (nil = true)
+	CacheBreakerThreshold int           // Consecutive cache errors that open the circuit breaker (0 = disabled)
+	CacheBreakerCooldown  time.Duration // How long the breaker stays open before probing the cache again (default: 30s)
+
+	// Cache key versioning
+	CacheVersion      string    // Prefix for generated cache keys; change it to invalidate all cached entries at once
+	CacheKeySeparator byte      // Byte between the query and each argument in generated keys (0 = DefaultKeySeparator, 0x1F)
+	KeyHasher         KeyHasher // Condenses generated keys to a fixed length, e.g. XXHashKeyHasher{} (nil = raw keys)
+	MaxKeyBytes       int       // Longer generated keys keep a prefix and end in a hash of the whole key, e.g. 250 for memcached (0 = unlimited)
+
+	// Cache key debugging
+	DetectKeyCollisions bool // Store a fingerprint of the statement with each external cache entry and fail reads by a different statement with ErrKeyCollision (for tests)
+
+	// Cache value limits
+	MaxValueBytes int // Results whose serialized size exceeds this are not cached (0 = unlimited)
+
+	// Cache compression
+	CompressOverBytes int // Gzip external cache values larger than this many bytes (0 = never compress)
+
+	// Cache levels
+	DisableL1  bool  // With an external Cache, skip the in-memory L1 entirely and serve results from the external cache only
+	CopyOnRead *bool // Deep-copy values stored in and returned from L1 so callers never share cached memory (nil = true)
+
+	// Cache metadata
+	StoreMetadata     bool // Wrap external cache values in an envelope recording the query, write time, and codec
+	HashMetadataQuery bool // Record a SHA-256 of the interpolated query instead of its text (with StoreMetadata)
+
 	// Concurrency control
 	Mutex Mutex // Custom mutex implementation for distributed locking
 
 	// Serialization
-	Codec Codec // Custom codec for data serialization (nil uses default MessagePack)
+	Codec         Codec   // Custom codec for data serialization (nil uses default MessagePack)
+	TagCacheCodec bool    // Record the codec name with every external cache value, so entries from another codec are detected as mismatches
+	LegacyCodecs  []Codec // Previous codecs still accepted when reading external cache entries, for a migration window after changing Codec
+
+	// Error handling
+	ErrorMapper     ErrorMapper // Custom driver error conversion (nil uses DefaultErrorMapper)
+	RecoverCallback bool        // Convert panics in Query callbacks into ErrPanic instead of crashing the caller
+
+	KeepResultOnCallbackError bool // Return a callback's partial result alongside its error instead of nil
+
+	// Logging
+	Logger *slog.Logger // Destination for operational warnings (nil uses slog.Default())
+
+	// Hooks
+	OnTableWrite       func(table string)          // Called after write helpers (e.g. BulkInsert) modify a table; use it to invalidate cache keys
+	OnCacheDecodeError func(key string, err error) // Called for each external cache read that finds an undecodable entry; the query then falls through to the database
 
 	// Advanced
 	ConnectionString string // Pre-built DSN; if set, overrides individual connection fields
+	OwnsDB           bool   // With NewWithDB, close the supplied *sql.DB when the client is closed
 }
 
 // defaultOptions creates and returns Options with sensible defaults.
@@ -89,17 +158,18 @@ type Options struct {
 func defaultOptions(opts ...Options) Options {
 	// Initialize with defaults
 	options := Options{
-		Host:           "localhost",
-		Port:           3306,
-		Charset:        "utf8mb4",
-		Collation:      "utf8mb4_unicode_ci",
-		Timeout:        30,
-		ReadTimeout:    30,
-		WriteTimeout:   30,
-		CacheSize:      10,              // 10 MB default cache size
-		CacheTTLCheck:  5 * time.Minute, // Check every 5 minutes
-		CacheEnabled:   false,           // Cache disabled by default
-		MaxConnections: 0,               // Use driver's default pool size
+		Host:            "localhost",
+		Port:            3306,
+		Charset:         "utf8mb4",
+		Collation:       "utf8mb4_unicode_ci",
+		Timeout:         30,
+		ReadTimeout:     30,
+		WriteTimeout:    30,
+		CacheSize:       10,              // 10 MB default cache size
+		CacheTTLCheck:   5 * time.Minute, // Check every 5 minutes
+		CacheEnabled:    false,           // Cache disabled by default
+		MaxConnections:  0,               // Use driver's default pool size
+		ConnMaxLifetime: 5 * time.Minute, // Recycle connections every 5 minutes
 	}
 
 	// Merge user-provided options if any
@@ -127,6 +197,27 @@ func defaultOptions(opts ...Options) Options {
 		if userOpts.MaxConnections > 0 {
 			options.MaxConnections = userOpts.MaxConnections
 		}
+		if userOpts.HealthCheckInterval > 0 {
+			options.HealthCheckInterval = userOpts.HealthCheckInterval
+		}
+		if userOpts.MaxConcurrentQueries > 0 {
+			options.MaxConcurrentQueries = userOpts.MaxConcurrentQueries
+		}
+		// Negative durations are clamped to 0, which the pool treats as no limit
+		if userOpts.ConnMaxLifetime != 0 {
+			options.ConnMaxLifetime = max(userOpts.ConnMaxLifetime, 0)
+		}
+		if userOpts.ConnMaxIdleTime != 0 {
+			options.ConnMaxIdleTime = max(userOpts.ConnMaxIdleTime, 0)
+		}
+
+		// Prepared statement cache
+		if userOpts.MaxPreparedStatements > 0 {
+			options.MaxPreparedStatements = userOpts.MaxPreparedStatements
+		}
+		if userOpts.PrepareTimeout > 0 {
+			options.PrepareTimeout = userOpts.PrepareTimeout
+		}
 
 		// Character set configuration
 		if userOpts.Charset != "" {
@@ -154,13 +245,60 @@ func defaultOptions(opts ...Options) Options {
 		if userOpts.CacheTTLCheck > 0 {
 			options.CacheTTLCheck = userOpts.CacheTTLCheck
 		}
+		if userOpts.CacheBreakerThreshold > 0 {
+			options.CacheBreakerThreshold = userOpts.CacheBreakerThreshold
+		}
+		if userOpts.CacheBreakerCooldown > 0 {
+			options.CacheBreakerCooldown = userOpts.CacheBreakerCooldown
+		}
+		if userOpts.MaxKeyBytes > 0 {
+			options.MaxKeyBytes = userOpts.MaxKeyBytes
+		}
+		if userOpts.MaxValueBytes > 0 {
+			options.MaxValueBytes = userOpts.MaxValueBytes
+		}
+		if userOpts.CompressOverBytes > 0 {
+			options.CompressOverBytes = userOpts.CompressOverBytes
+		}
 
 		// Direct assignment for interface and boolean fields
 		options.Cache = userOpts.Cache
+		options.L1 = userOpts.L1
+		options.Location = userOpts.Location
+		options.CacheVersion = userOpts.CacheVersion
+		options.CacheKeySeparator = userOpts.CacheKeySeparator
+		options.KeyHasher = userOpts.KeyHasher
 		options.CacheEnabled = userOpts.CacheEnabled
+		options.AsyncCacheWrites = userOpts.AsyncCacheWrites
+		options.StoreMetadata = userOpts.StoreMetadata
+		options.DisableL1 = userOpts.DisableL1
+		options.DetectKeyCollisions = userOpts.DetectKeyCollisions
+		options.CopyOnRead = userOpts.CopyOnRead
+		options.HashMetadataQuery = userOpts.HashMetadataQuery
+		options.DegradeOnCacheError = userOpts.DegradeOnCacheError
+		options.Logger = userOpts.Logger
 		options.Mutex = userOpts.Mutex
 		options.Codec = userOpts.Codec
+		options.TagCacheCodec = userOpts.TagCacheCodec
+		options.LegacyCodecs = userOpts.LegacyCodecs
+		options.ErrorMapper = userOpts.ErrorMapper
+		options.RecoverCallback = userOpts.RecoverCallback
+		options.KeepResultOnCallbackError = userOpts.KeepResultOnCallbackError
+		options.NormalizeQueries = userOpts.NormalizeQueries
+		options.StrictArgs = userOpts.StrictArgs
+		options.DisablePrepare = userOpts.DisablePrepare
+		options.MultiStatements = userOpts.MultiStatements
+		options.OnTableWrite = userOpts.OnTableWrite
+		options.OnCacheDecodeError = userOpts.OnCacheDecodeError
 		options.ConnectionString = userOpts.ConnectionString
+		options.OwnsDB = userOpts.OwnsDB
+	}
+
+	// A pre-built DSN still names the default database used in cache keys
+	if options.ConnectionString != "" && options.Database == "" {
+		if parsed, err := ParseDSN(options.ConnectionString); err == nil {
+			options.Database = parsed.Database
+		}
 	}
 
 	// Generate connection string if not provided
@@ -179,6 +317,21 @@ func defaultOptions(opts ...Options) Options {
 			options.ConnectionString += "&collation=" + options.Collation
 		}
 
+		// Add time zone for parsed DATETIME/TIMESTAMP values
+		if options.Location != nil {
+			options.ConnectionString += "&loc=" + url.QueryEscape(options.Location.String())
+		}
+
+		// Interpolate args client-side instead of preparing statements
+		if options.DisablePrepare {
+			options.ConnectionString += "&interpolateParams=true"
+		}
+
+		// Accept multi-statement scripts (used by ExecRaw)
+		if options.MultiStatements {
+			options.ConnectionString += "&multiStatements=true"
+		}
+
 		// Add timeout configurations
 		if options.Timeout > 0 {
 			options.ConnectionString += fmt.Sprintf("&timeout=%ds", options.Timeout)
@@ -189,7 +342,28 @@ func defaultOptions(opts ...Options) Options {
 		if options.WriteTimeout > 0 {
 			options.ConnectionString += fmt.Sprintf("&writeTimeout=%ds", options.WriteTimeout)
 		}
+	} else if options.DisablePrepare {
+		// A pre-built DSN needs client-side interpolation too, or the driver
+		// still prepares every statement on the server
+		options.ConnectionString = withInterpolateParams(options.ConnectionString)
 	}
 
 	return options
 }
+
+// validatePool rejects pool settings that cannot be what the caller meant.
+// An idle timeout longer than the lifetime never takes effect, because the
+// connection is retired at its lifetime first. It checks the options as
+// passed to New, before defaults apply: only a lifetime the caller set
+// explicitly is compared, so an idle timeout above the default lifetime is
+// accepted.
+func validatePool(opts ...Options) error {
+	if len(opts) == 0 {
+		return nil
+	}
+	opt := opts[0]
+	if opt.ConnMaxLifetime > 0 && opt.ConnMaxIdleTime > opt.ConnMaxLifetime {
+		return fmt.Errorf("mysql: ConnMaxIdleTime (%s) exceeds ConnMaxLifetime (%s)", opt.ConnMaxIdleTime, opt.ConnMaxLifetime)
+	}
+	return nil
+}
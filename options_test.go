@@ -168,8 +168,69 @@ func TestDefaultOptions_DSNAndAssignments(t *testing.T) {
 	}
 }
 
+func TestDefaultOptions_Location(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	opts := defaultOptions(Options{Location: loc})
+	if !strings.Contains(opts.ConnectionString, "&loc=America%2FNew_York") {
+		t.Fatalf("expected escaped loc in DSN, got %q", opts.ConnectionString)
+	}
+
+	opts = defaultOptions()
+	if strings.Contains(opts.ConnectionString, "loc=") {
+		t.Fatalf("expected no loc without Location, got %q", opts.ConnectionString)
+	}
+}
+
 // BenchmarkDefaultOptions measures the performance of the defaultOptions function
 // under different usage patterns to ensure it doesn't become a bottleneck.
+func TestDefaultOptions_ConnLifetimes(t *testing.T) {
+	opts := defaultOptions()
+	if opts.ConnMaxLifetime != 5*time.Minute || opts.ConnMaxIdleTime != 0 {
+		t.Fatalf("unexpected defaults: lifetime %s, idle %s", opts.ConnMaxLifetime, opts.ConnMaxIdleTime)
+	}
+
+	opts = defaultOptions(Options{ConnMaxLifetime: time.Hour, ConnMaxIdleTime: time.Minute})
+	if opts.ConnMaxLifetime != time.Hour || opts.ConnMaxIdleTime != time.Minute {
+		t.Fatalf("expected overrides, got lifetime %s, idle %s", opts.ConnMaxLifetime, opts.ConnMaxIdleTime)
+	}
+
+	opts = defaultOptions(Options{ConnMaxLifetime: -time.Second, ConnMaxIdleTime: -time.Second})
+	if opts.ConnMaxLifetime != 0 || opts.ConnMaxIdleTime != 0 {
+		t.Fatalf("expected negative durations clamped to 0, got lifetime %s, idle %s", opts.ConnMaxLifetime, opts.ConnMaxIdleTime)
+	}
+}
+
+func TestValidatePool(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"defaults", Options{}, false},
+		{"idle below lifetime", Options{ConnMaxLifetime: time.Hour, ConnMaxIdleTime: time.Minute}, false},
+		{"idle equals lifetime", Options{ConnMaxLifetime: time.Minute, ConnMaxIdleTime: time.Minute}, false},
+		{"idle above lifetime", Options{ConnMaxLifetime: time.Minute, ConnMaxIdleTime: time.Hour}, true},
+		{"idle above default lifetime", Options{ConnMaxIdleTime: time.Hour}, false},
+		{"unlimited lifetime", Options{ConnMaxLifetime: -1, ConnMaxIdleTime: time.Hour}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePool(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validatePool() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "ConnMaxIdleTime (1h0m0s) exceeds ConnMaxLifetime") {
+				t.Fatalf("unexpected message: %v", err)
+			}
+		})
+	}
+}
+
 func BenchmarkDefaultOptions(b *testing.B) {
 	// Benchmark: creating options with no arguments (fast path)
 	b.Run("empty options", func(b *testing.B) {
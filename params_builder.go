@@ -0,0 +1,50 @@
+package mysql
+
+import "time"
+
+// ParamsBuilder assembles Params fluently:
+//
+//	params := mysql.NewParams("SELECT * FROM users WHERE id = ?", id).
+//		WithTimeout(2 * time.Second).
+//		WithCache(time.Minute, 10*time.Second).
+//		Build()
+//
+// Fields that are not set keep their zero values, which Query treats as its
+// defaults (default timeout, auto-generated key, no caching).
+type ParamsBuilder struct {
+	p Params
+}
+
+// NewParams starts a builder for a direct SQL query with its arguments.
+func NewParams(query string, args ...any) *ParamsBuilder {
+	return &ParamsBuilder{p: Params{Query: query, Args: args}}
+}
+
+// WithTimeout sets the query execution timeout.
+func (b *ParamsBuilder) WithTimeout(d time.Duration) *ParamsBuilder {
+	b.p.Timeout = d
+	return b
+}
+
+// WithCache sets the external (L2) and local (L1) cache TTLs.
+func (b *ParamsBuilder) WithCache(delay, nodeDelay time.Duration) *ParamsBuilder {
+	b.p.CacheDelay = delay
+	b.p.NodeCacheDelay = nodeDelay
+	return b
+}
+
+// WithKey sets a manual cache key instead of the generated one.
+func (b *ParamsBuilder) WithKey(key string) *ParamsBuilder {
+	b.p.Key = key
+	return b
+}
+
+// Build returns the assembled Params. The builder may be reused afterwards;
+// later changes do not affect Params already built.
+func (b *ParamsBuilder) Build() Params {
+	p := b.p
+	if p.Args != nil {
+		p.Args = append([]any(nil), p.Args...)
+	}
+	return p
+}
@@ -0,0 +1,56 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParamsBuilder_SetsFields(t *testing.T) {
+	got := NewParams("SELECT * FROM users WHERE id = ?", 7).
+		WithTimeout(2*time.Second).
+		WithCache(time.Minute, 10*time.Second).
+		WithKey("user:7").
+		Build()
+
+	want := Params{
+		Key:            "user:7",
+		Query:          "SELECT * FROM users WHERE id = ?",
+		Args:           []any{7},
+		Timeout:        2 * time.Second,
+		CacheDelay:     time.Minute,
+		NodeCacheDelay: 10 * time.Second,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Build() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParamsBuilder_Defaults(t *testing.T) {
+	got := NewParams("SELECT 1").Build()
+
+	if got.Query != "SELECT 1" || got.Args != nil {
+		t.Fatalf("unexpected query/args: %+v", got)
+	}
+	if got.Key != "" || got.Timeout != 0 || got.CacheDelay != 0 || got.NodeCacheDelay != 0 {
+		t.Fatalf("expected zero-value defaults, got %+v", got)
+	}
+	if !got.hasStatement() {
+		t.Fatal("expected built params to have a statement")
+	}
+}
+
+func TestParamsBuilder_BuildIsolated(t *testing.T) {
+	b := NewParams("SELECT ?", 1)
+	first := b.Build()
+	b.WithKey("other")
+	first.Args[0] = 2
+
+	second := b.Build()
+	if first.Key != "" {
+		t.Fatalf("earlier Params changed: %+v", first)
+	}
+	if second.Args[0] != 1 {
+		t.Fatalf("builder args shared with built Params: %+v", second)
+	}
+}
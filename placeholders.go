@@ -0,0 +1,92 @@
+package mysql
+
+import "fmt"
+
+// countPlaceholders returns the number of '?' parameter markers in query.
+// Markers inside quoted strings ('...', "..."), quoted identifiers (`...`),
+// and comments (-- ..., # ..., /* ... */) are not counted.
+func countPlaceholders(query string) int {
+	n := 0
+	forEachPlaceholder(query, func(int) { n++ })
+	return n
+}
+
+// forEachPlaceholder calls fn with the byte offset of every '?' parameter
+// marker in query, skipping quoted text and comments like countPlaceholders.
+func forEachPlaceholder(query string, fn func(pos int)) {
+	for i := 0; i < len(query); i++ {
+		switch ch := query[i]; ch {
+		case '\'', '"', '`':
+			i = skipQuoted(query, i, ch)
+		case '#':
+			i = skipLine(query, i)
+		case '-':
+			if startsDashComment(query, i) {
+				i = skipLine(query, i)
+			}
+		case '/':
+			if i+1 < len(query) && query[i+1] == '*' {
+				i = skipBlockComment(query, i)
+			}
+		case '?':
+			fn(i)
+		}
+	}
+}
+
+// startsDashComment reports whether a "-- " comment starts at i. Two dashes
+// start a comment only when followed by whitespace or end of input.
+func startsDashComment(query string, i int) bool {
+	return query[i] == '-' && i+1 < len(query) && query[i+1] == '-' && (i+2 == len(query) || isSpace(query[i+2]))
+}
+
+// skipQuoted returns the index of the quote closing the literal opened at i,
+// or the last index when the literal is unterminated.
+func skipQuoted(query string, i int, quote byte) int {
+	for i++; i < len(query); i++ {
+		switch {
+		case query[i] == '\\' && quote != '`':
+			i++ // Skip the escaped character
+		case query[i] == quote:
+			return i
+		}
+	}
+	return len(query) - 1
+}
+
+// skipLine returns the index of the newline ending the comment started at i.
+func skipLine(query string, i int) int {
+	for ; i < len(query) && query[i] != '\n'; i++ {
+	}
+	return i
+}
+
+// skipBlockComment returns the index of the '/' closing the comment opened at i.
+func skipBlockComment(query string, i int) int {
+	for i += 2; i+1 < len(query); i++ {
+		if query[i] == '*' && query[i+1] == '/' {
+			return i + 1
+		}
+	}
+	return len(query) - 1
+}
+
+// validateStatement checks params before a statement is prepared on a cache
+// miss. It requires a Query or Exec and, with StrictArgs, that a direct Query
+// has exactly one argument per placeholder. Exec calls are generated with a
+// matching placeholder count and are not checked.
+func (c *MySQL) validateStatement(params Params) *MySQLError {
+	if !params.hasStatement() {
+		return ErrEmptyQuery
+	}
+	if c.strictArgs && params.Query != "" {
+		if want := countPlaceholders(params.Query); want != len(params.Args) {
+			return &MySQLError{
+				Number:   ErrArgCount.Number,
+				SQLState: ErrArgCount.SQLState,
+				Message:  fmt.Sprintf("query has %d placeholders but %d arguments were given", want, len(params.Args)),
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package mysql
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCountPlaceholders(t *testing.T) {
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"SELECT 1", 0},
+		{"SELECT * FROM t WHERE a = ? AND b = ?", 2},
+		{"SELECT '?', \"?\", `?` FROM t WHERE a = ?", 1},
+		{`SELECT 'it\'s ?' FROM t WHERE a = ?`, 1},
+		{"SELECT 'a''?' FROM t WHERE a = ?", 1},
+		{"SELECT ? -- trailing ?\nFROM t WHERE b = ?", 2},
+		{"SELECT ? # hash ?\n, ?", 2},
+		{"SELECT ? /* block ? */ + ?", 2},
+		{"SELECT 5--?", 1}, // "--" without a following space is arithmetic
+		{"SELECT ? /* unterminated ?", 1},
+		{"SELECT 'unterminated ?", 0},
+	}
+	for _, tt := range tests {
+		if got := countPlaceholders(tt.query); got != tt.want {
+			t.Errorf("countPlaceholders(%q) = %d, want %d", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestQuery_StrictArgs(t *testing.T) {
+	db := NewMockDB()
+	db.WithStmt("SELECT * FROM t WHERE a = ? AND b = ?", &MockStmt{Factory: func() Rows {
+		return &MockRows{data: [][]any{{1}}}
+	}})
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+	client.strictArgs = true
+
+	callback := func(rows Rows) (*[]int, *MySQLError) {
+		out := []int{}
+		return &out, nil
+	}
+
+	// Matched
+	if _, err := Query(client, Params{Query: "SELECT * FROM t WHERE a = ? AND b = ?", Args: []any{1, 2}}, callback); err != nil {
+		t.Fatalf("unexpected error for matched args: %v", err)
+	}
+
+	// Mismatched
+	_, err := Query(client, Params{Query: "SELECT * FROM t WHERE a = ? AND b = ?", Args: []any{1}}, callback)
+	if !errors.Is(err, ErrArgCount) {
+		t.Fatalf("expected ErrArgCount, got %+v", err)
+	}
+	if !strings.Contains(err.Message, "2 placeholders but 1 arguments") {
+		t.Fatalf("expected descriptive message, got %q", err.Message)
+	}
+	if db.Prepares != 1 {
+		t.Fatalf("expected the mismatched query not to be prepared, got %d prepares", db.Prepares)
+	}
+
+	// Stored procedure calls are generated with matching placeholders
+	db.WithStmt("CALL db.proc(?, ?)", &MockStmt{Factory: func() Rows { return &MockRows{} }})
+	if _, err := Query(client, Params{Exec: "proc", Database: "db", Args: []any{1, 2}}, callback); err != nil {
+		t.Fatalf("unexpected error for Exec params: %v", err)
+	}
+
+	// Without StrictArgs the check is skipped and the driver decides
+	client.strictArgs = false
+	if err := client.validateStatement(Params{Query: "SELECT ?", Args: nil}); err != nil {
+		t.Fatalf("expected no validation without StrictArgs, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"testing"
 )
@@ -9,9 +10,16 @@ import (
 type stubStmt struct{}
 
 func (s *stubStmt) QueryContext(ctx context.Context, args ...any) (Rows, error) { return nil, nil }
-func (s *stubStmt) Close() error                                                { return nil }
+func (s *stubStmt) QueryRowContext(ctx context.Context, args ...any) Row {
+	return rowFromRows(s.QueryContext(ctx, args...))
+}
+func (s *stubStmt) ExecContext(ctx context.Context, args ...any) (sql.Result, error) {
+	return nil, nil
+}
+func (s *stubStmt) Close() error { return nil }
 
 type stubDB struct {
+	noDirectStatements
 	prepareCalls int
 	stmt         Stmt
 	err          error
@@ -82,3 +90,73 @@ func TestGetPreparedStatement_PrepareError(t *testing.T) {
 		t.Fatalf("expected PrepareContext to be called once")
 	}
 }
+
+type recordingStmt struct {
+	closed bool
+}
+
+func (s *recordingStmt) QueryContext(ctx context.Context, args ...any) (Rows, error) { return nil, nil }
+func (s *recordingStmt) QueryRowContext(ctx context.Context, args ...any) Row {
+	return rowFromRows(s.QueryContext(ctx, args...))
+}
+func (s *recordingStmt) ExecContext(ctx context.Context, args ...any) (sql.Result, error) {
+	return nil, nil
+}
+func (s *recordingStmt) Close() error { s.closed = true; return nil }
+
+type recordingDB struct {
+	noDirectStatements
+	stmts map[string]*recordingStmt
+}
+
+func (d *recordingDB) PrepareContext(ctx context.Context, query string) (Stmt, error) {
+	stmt := &recordingStmt{}
+	d.stmts[query] = stmt
+	return stmt, nil
+}
+
+func (d *recordingDB) Close() error { return nil }
+
+func TestGetPreparedStatement_LRUEviction(t *testing.T) {
+	db := &recordingDB{stmts: make(map[string]*recordingStmt)}
+	client := &MySQL{
+		DB:          db,
+		prepare:     make(map[string]Stmt),
+		maxPrepared: 2,
+	}
+	ctx := context.Background()
+
+	_, _ = client.getPreparedStatement(ctx, "q1")
+	_, _ = client.getPreparedStatement(ctx, "q2")
+	// Touch q1 so q2 becomes the least recently used statement
+	_, _ = client.getPreparedStatement(ctx, "q1")
+	_, _ = client.getPreparedStatement(ctx, "q3")
+
+	if len(client.prepare) != 2 {
+		t.Fatalf("expected cache to be capped at 2, got %d", len(client.prepare))
+	}
+	if _, ok := client.prepare["q2"]; ok {
+		t.Fatalf("expected least recently used statement to be removed")
+	}
+	if !db.stmts["q2"].closed {
+		t.Fatalf("expected evicted statement to be closed")
+	}
+	if db.stmts["q1"].closed || db.stmts["q3"].closed {
+		t.Fatalf("expected retained statements to stay open")
+	}
+}
+
+func TestGetPreparedStatement_Unbounded(t *testing.T) {
+	db := &recordingDB{stmts: make(map[string]*recordingStmt)}
+	client := &MySQL{
+		DB:      db,
+		prepare: make(map[string]Stmt),
+	}
+
+	for _, q := range []string{"q1", "q2", "q3"} {
+		_, _ = client.getPreparedStatement(context.Background(), q)
+	}
+	if len(client.prepare) != 3 {
+		t.Fatalf("expected all statements to be cached without a cap")
+	}
+}
@@ -2,33 +2,79 @@ package mysql
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
 	"time"
-
-	"github.com/go-sql-driver/mysql"
 )
 
 // Params holds the inputs used by Query.
 type Params struct {
-	Key            string        // Cache key (if caching is enabled). If empty, will be auto-generated based on query and arguments.
-	Database       string        // Optional database name for qualifying stored procedure calls (e.g., "dbname.proc_name")
-	Query          string        // SQL query string. If provided, takes precedence over Exec field for direct SQL execution.
-	Exec           string        // Stored procedure name or SQL executable string. Used when Query is empty.
-	Args           []any         // Arguments for the SQL query. Bound to placeholders in the query/procedure call.
-	Timeout        time.Duration // Timeout for the query execution. Zero value uses default timeout (100 seconds).
-	CacheDelay     time.Duration // TTL for external/distributed cache (L2 cache). Zero means no external caching.
-	NodeCacheDelay time.Duration // TTL for local in-memory cache (L1 cache). Zero means no local caching.
+	Key            string            // Cache key (if caching is enabled). If empty, will be auto-generated based on query and arguments.
+	Database       string            // Optional database name for qualifying stored procedure calls (e.g., "dbname.proc_name")
+	Query          string            // SQL query string. If provided, takes precedence over Exec field for direct SQL execution.
+	Exec           string            // Stored procedure name or SQL executable string. Used when Query is empty.
+	Args           []any             // Arguments for the SQL query. Bound to placeholders in the query/procedure call.
+	Timeout        time.Duration     // Timeout for the query execution. Zero value uses default timeout (100 seconds).
+	CacheDelay     time.Duration     // TTL for external/distributed cache (L2 cache). Zero means no external caching.
+	NodeCacheDelay time.Duration     // TTL for local in-memory cache (L1 cache). Zero means no local caching.
+	Strict         bool              // Single-row helpers (QueryRow) fail with ErrTooManyRows when more than one row is returned.
+	Codec          Codec             // Optional codec for this query's L2 entries, overriding the client codec. Entries record it, so reads pick the right decoder.
+	ForceRefresh   bool              // Skip L1/L2 cache reads and hit the database, but still repopulate the cache with the fresh result.
+	Context        context.Context   // Optional request context. Cancelling it aborts the query and releases the cache-fill lock. Nil means context.Background().
+	ExpandSlices   bool              // Expand slice arguments into one placeholder per element (see ExpandIN), e.g. for "WHERE id IN (?)".
+	Metadata       map[string]any    // Optional values (tenant ID, trace ID, ...) attached to the contexts passed to the DB; read them with MetadataFromContext.
+	Comment        string            // Optional SQL comment prepended as "/* comment */" for slow-query-log correlation; not part of the cache key.
+	MaxRows        int               // Stop reading after this many rows and fail with ErrRowLimit instead of returning or caching the result (0 = unlimited).
+	SessionVars    map[string]string // Session variables (e.g. "group_concat_max_len": "1000000") set on a pinned connection for this query only and restored afterwards. Such queries bypass the cache.
+
+	singleRow bool // Run the statement with QueryRowContext; set by QueryScalar
+}
+
+// hasStatement reports whether params name something to execute:
+// a direct Query or a stored procedure in Exec. Params with only a manual Key
+// can still be answered from cache, so the check happens on a cache miss.
+func (p Params) hasStatement() bool {
+	return p.Query != "" || p.Exec != ""
+}
+
+// prepareStatement resolves the statement for query with a deadline of its
+// own, so a slow prepare does not consume the execution budget. The deadline
+// is Options.PrepareTimeout, or the query timeout when that is unset; both
+// are bound to params.Context and carry params.Metadata. With DisablePrepare
+// nothing is prepared and an unpreparedStmt is returned instead.
+func (c *MySQL) prepareStatement(params Params, query string) (Stmt, error) {
+	if c.disablePrepare {
+		return &unpreparedStmt{db: c.DB, query: query}, nil
+	}
+	timeout := c.prepareTimeout
+	if timeout <= 0 {
+		timeout = params.Timeout
+	}
+	ctx, cancel := createContextWithTimeout(params.requestContext(), timeout)
+	defer cancel()
+	return c.getPreparedStatement(ctx, query)
 }
 
 // getPreparedStatement retrieves a prepared SQL statement from the cache or prepares a new one
 // Uses a mutex-protected map to cache prepared statements by query text, reducing database server overhead
 // for frequently repeated queries. This is especially beneficial for parameterized queries and stored procedures.
+// When query normalization is enabled, whitespace-only variants of a query share one statement.
 func (c *MySQL) getPreparedStatement(ctx context.Context, query string) (Stmt, error) {
+	if c.normalizeQueries {
+		query = normalizeQuery(query)
+	}
+
 	c.mx.Lock()
 	defer c.mx.Unlock()
 
 	// Check cache first - cache hit avoids database roundtrip for statement preparation
 	if stmt, ok := c.prepare[query]; ok {
+		if c.maxPrepared > 0 {
+			c.prepareLRU.touch(query)
+		}
 		return stmt, nil
 	}
 
@@ -41,19 +87,58 @@ func (c *MySQL) getPreparedStatement(ctx context.Context, query string) (Stmt, e
 	// Store in cache for future reuse. Note: statement is not closed here;
 	// it remains cached for the lifetime of the MySQL connection or until cache eviction.
 	c.prepare[query] = stmt
+	if c.maxPrepared > 0 {
+		c.prepareLRU.touch(query)
+		c.evictPreparedStatements()
+	}
 	return stmt, nil
 }
 
+// evictPreparedStatements closes and removes least recently used statements
+// until the cache fits MaxPreparedStatements. Must be called with c.mx held.
+// database/sql defers the final close of a statement until queries already
+// running on it have finished, so in-flight callers are not interrupted.
+func (c *MySQL) evictPreparedStatements() {
+	for c.prepareLRU.len() > c.maxPrepared {
+		key, ok := c.prepareLRU.removeOldest()
+		if !ok {
+			return
+		}
+		if stmt := c.prepare[key]; stmt != nil {
+			_ = stmt.Close()
+		}
+		delete(c.prepare, key)
+	}
+}
+
 // Query executes a database query with optional multi-level caching support.
 // Generic type T represents the expected result type. The callback function processes
 // raw database rows and converts them to the desired type.
 // Automatically handles caching, prepared statement reuse, timeout, and error conversion.
+//
+// When the callback returns an error, Query returns that error with a nil
+// result, even if the callback also built one; set
+// Options.KeepResultOnCallbackError to receive the partial result instead.
+// Results returned with an error are never cached.
 func Query[T any](
 	c *MySQL,
 	params Params,
 	callback func(rows Rows) (*T, *MySQLError),
 ) (*T, *MySQLError) {
 
+	// Refuse new work once shutdown has started; track in-flight queries otherwise
+	if !c.beginQuery() {
+		return nil, ErrClosed
+	}
+	defer c.endQuery()
+
+	params = c.withDefaultDatabase(params.withExpandedSlices())
+
+	// Session state is not part of the cache key, so such queries skip the cache
+	if len(params.SessionVars) > 0 {
+		return sessionQuery(c, params, callback)
+	}
+
 	// Route to appropriate implementation based on whether external cache is configured
 	if c.cache == nil {
 		return internalQuery(c, params, callback)
@@ -77,7 +162,7 @@ func externalQuery[T any](
 	query := generateQuery(params)
 
 	// Determine cache key only when caching is enabled and used.
-	needKey := c.CacheEnabled && (params.NodeCacheDelay > 0 || params.CacheDelay > 0)
+	needKey := c.CacheEnabled() && (params.NodeCacheDelay > 0 || params.CacheDelay > 0)
 	var key string
 	if needKey {
 		if params.Key == "" {
@@ -86,119 +171,90 @@ func externalQuery[T any](
 			key = params.Key
 		}
 	}
+	fp := c.fingerprint(params)
 
 	// Check L1 cache (in-memory) if node-level caching is enabled and configured
 	// This is the fastest cache level but limited to current process memory
-	if params.NodeCacheDelay > 0 && c.CacheEnabled {
-		if val, err := c.inMemory.Get(key); err == nil {
-			if res, ok := val.(*T); ok {
-				// L1 cache hit - return immediately without database access
-				return res, nil
-			}
+	if c.nodeTTL(params) > 0 && c.CacheEnabled() && !params.ForceRefresh {
+		if res, ok := getL1[T](c, key); ok {
+			// L1 cache hit - return immediately without database access
+			return res, nil
 		}
 	}
 
 	// Check L2 cache (external/shared) if external caching is enabled
 	// This cache is shared across multiple application instances/nodes
-	if params.CacheDelay > 0 && c.CacheEnabled {
-		// First optimistic check - proceed if cache miss
-		if res := checkExternalCache[T](c, key); res != nil {
+	if params.CacheDelay > 0 && c.CacheEnabled() {
+		res, unlock, merr := lookupExternalCache[T](c, params.requestContext(), key, params.ForceRefresh, params.Codec, fp)
+		if unlock != nil {
+			defer unlock()
+		}
+		if merr != nil {
+			return nil, merr
+		}
+		if res != nil {
 			// L2 cache hit - warm up L1 cache for faster subsequent access
-			if params.NodeCacheDelay > 0 {
-				c.inMemory.Set(key, res, params.NodeCacheDelay)
+			if ttl := c.nodeTTL(params); ttl > 0 {
+				c.setL1(key, res, ttl)
 			}
 			return res, nil
 		}
+	}
 
-		// Cache miss - acquire distributed lock to prevent concurrent database queries
-		// for the same cache key (cache stampede protection)
-		mutexKey := "mutex_" + key
-		if err := c.mutex.Lock(mutexKey); err != nil {
-			// Lock acquisition failed - cannot safely proceed with cache population
-			// In production, consider logging this and proceeding without cache protection
-			return nil, nil
-		}
-		defer c.mutex.Unlock(mutexKey)
+	// Cache miss: make sure the params describe a valid statement
+	if merr := c.validateStatement(params); merr != nil {
+		return nil, merr
+	}
 
-		// Double-check cache after acquiring lock (other goroutine might have populated it)
-		if res := checkExternalCache[T](c, key); res != nil {
-			// Cache was populated while waiting for lock - warm up L1 and return
-			if params.NodeCacheDelay > 0 {
-				c.inMemory.Set(key, res, params.NodeCacheDelay)
-			}
-			return res, nil
-		}
+	// Get cached or newly prepared statement under its own deadline
+	prepare, err := c.prepareStatement(params, query)
+	if err != nil {
+		// Convert driver error to application error type
+		return nil, c.mapError(err)
 	}
 
-	// Create context with timeout for database operations
-	// Uses default timeout if params.Timeout is zero
-	ctx, cancel := createContextWithTimeout(params.Timeout)
+	// Create context with timeout for database operations, derived from the
+	// request context so a caller that gives up aborts the fill and releases
+	// the keyed mutex for waiters. Uses default timeout if params.Timeout is zero
+	ctx, cancel := createContextWithTimeout(params.requestContext(), params.Timeout)
 	defer cancel()
 
-	// Get cached or newly prepared statement
-	prepare, err := c.getPreparedStatement(ctx, query)
+	// Wait for a slot under MaxConcurrentQueries
+	release, err := c.acquireQuerySlot(ctx)
 	if err != nil {
-		// Convert MySQL driver error to application error type
-		if sqlErr, ok := err.(*mysql.MySQLError); ok {
-			return nil, &MySQLError{
-				Number:   sqlErr.Number,
-				SQLState: sqlErr.SQLState,
-				Message:  sqlErr.Message,
-			}
-		}
-		// Non-MySQL error (network, context cancelled, etc.)
-		return nil, &MySQLError{}
+		return nil, c.mapError(err)
 	}
+	defer release()
 
 	// Execute query with parameters
-	rows, err := prepare.QueryContext(ctx, params.Args...)
+	rows, err := c.queryStmt(ctx, params, query, prepare)
 	if err != nil {
-		// Handle specific MySQL error conditions with application-specific codes
-		if sqlErr, ok := err.(*mysql.MySQLError); ok && sqlErr.Number == 1213 {
-			// MySQL error 1213: Deadlock found when trying to get lock
-			return nil, &MySQLError{Number: 45000, Message: "DEADLOCK"}
-		}
-		if errors.Is(err, context.DeadlineExceeded) {
-			// Query exceeded timeout
-			return nil, &MySQLError{Number: 45000, Message: "TIMEOUT"}
-		}
-		if sqlErr, ok := err.(*mysql.MySQLError); ok {
-			// Other MySQL-specific errors
-			return nil, &MySQLError{
-				Number:   sqlErr.Number,
-				SQLState: sqlErr.SQLState,
-				Message:  sqlErr.Message,
-			}
-		}
-		// Generic error (network, driver, etc.)
-		return nil, &MySQLError{}
+		// Map driver errors (deadlock, timeout, ...) to application errors
+		return nil, c.mapError(err)
 	}
 	// Ensure rows are closed even if callback panics
 	defer rows.Close()
+	limited, limiter := limitRows(rows, params.MaxRows)
 
 	// Process query results through user-provided callback
 	// Callback is responsible for scanning rows and constructing result object
-	clbRes, clbErr := callback(rows)
+	clbRes, clbErr := runCallback(c, limited, callback)
 
-	// Cache successful results for future requests
-	if clbErr == nil && clbRes != nil {
+	// The callback saw a truncated result; never return or cache it
+	if limiter.Exceeded() {
+		return nil, ErrRowLimit
+	}
 
-		// Store in L2 cache (external/shared) if enabled
-		if params.CacheDelay > 0 && c.CacheEnabled {
-			// Serialize result using configured codec (e.g., MessagePack, JSON)
-			data, err := c.codec.Marshal(clbRes)
-			if err != nil {
-				// Serialization error - log but don't fail the query
-				// The result is still returned to caller, just not cached
-				return clbRes, &MySQLError{Number: 45000, Message: "SERIALIZE"}
-			}
-			// Store in external cache with TTL (best-effort, ignore Set errors)
-			_ = c.cache.Set(key, data, params.CacheDelay)
+	// A context cancelled mid-scan may leave a truncated result; never cache it
+	if clbErr == nil && ctx.Err() != nil {
+		return nil, c.mapError(ctx.Err())
+	}
 
-			// Also store in L1 cache for faster local access
-			if params.NodeCacheDelay > 0 {
-				c.inMemory.Set(key, clbRes, params.NodeCacheDelay)
-			}
+	// Cache successful results for future requests
+	if clbErr == nil && clbRes != nil {
+		if _, merr := c.storeExternal(key, params, fp, clbRes); merr != nil {
+			// The result is still returned to caller, just not cached
+			return clbRes, merr
 		}
 	}
 
@@ -208,6 +264,62 @@ func externalQuery[T any](
 
 }
 
+// storeExternal writes v to the L2 cache and, when NodeCacheDelay is set, to
+// L1 as well, following the TTLs in params. It reports whether v was handed
+// to the caches. Storage is best-effort: only a codec failure is reported
+// (as ErrSerialize); oversized values are skipped. A non-zero fp is stored
+// with the value for DetectKeyCollisions.
+func (c *MySQL) storeExternal(key string, params Params, fp uint64, v any) (bool, *MySQLError) {
+	if params.CacheDelay <= 0 || !c.CacheEnabled() {
+		return false, nil
+	}
+
+	// Serialize result using configured codec (e.g., MessagePack, JSON)
+	data, err := c.marshalCacheValue(params.Codec, v)
+	if err != nil {
+		return false, ErrSerialize
+	}
+	// Oversized results are not cached at either level
+	if !c.storeExternalData(key, params, fp, data) {
+		return false, nil
+	}
+
+	// Also store in L1 cache for faster local access
+	if ttl := c.nodeTTL(params); ttl > 0 {
+		c.setL1(key, v, ttl)
+	}
+	return true, nil
+}
+
+// storeExternalData writes an already serialized payload to the L2 cache
+// with the fingerprint, metadata, and compression layers applied. It returns
+// false, storing nothing, when data exceeds MaxValueBytes.
+func (c *MySQL) storeExternalData(key string, params Params, fp uint64, data []byte) bool {
+	if c.maxValueBytes > 0 && len(data) > c.maxValueBytes {
+		return false
+	}
+	// Store in external cache with TTL (best-effort, ignore Set and compression errors)
+	if data, err := c.externalValue(params, fp, data); err == nil {
+		c.setExternalCache(params.requestContext(), key, data, params.CacheDelay)
+	}
+	return true
+}
+
+// externalValue applies the fingerprint, metadata, and compression layers
+// to a serialized payload, producing the bytes stored in the L2 cache.
+func (c *MySQL) externalValue(params Params, fp uint64, data []byte) ([]byte, error) {
+	return c.encodeCacheValue(c.wrapMetadata(params, wrapFingerprint(fp, data)))
+}
+
+// nodeTTL returns the L1 TTL for params in external-cache mode: zero when
+// Options.DisableL1 is set, so results live only in the external cache.
+func (c *MySQL) nodeTTL(params Params) time.Duration {
+	if c.disableL1 {
+		return 0
+	}
+	return params.NodeCacheDelay
+}
+
 // internalQuery handles queries when only in-memory (L1) cache is available.
 // Simplified version without external cache or distributed locking.
 func internalQuery[T any](
@@ -226,58 +338,61 @@ func internalQuery[T any](
 		} else {
 			key = params.Key
 		}
-		if val, err := c.inMemory.Get(key); err == nil {
-			if res, ok := val.(*T); ok {
+		if !params.ForceRefresh {
+			if res, ok := getL1[T](c, key); ok {
 				// Cache hit - return immediately
 				return res, nil
 			}
 		}
+		c.cacheStats.misses.Add(1)
 	}
 
-	// Create execution context with timeout
-	ctx, cancel := createContextWithTimeout(params.Timeout)
-	defer cancel()
+	// Cache miss: make sure the params describe a valid statement
+	if merr := c.validateStatement(params); merr != nil {
+		return nil, merr
+	}
 
 	// Get prepared statement (cached or new)
-	prepare, err := c.getPreparedStatement(ctx, query)
+	prepare, err := c.prepareStatement(params, query)
 	if err != nil {
 		// Error handling identical to externalQuery
-		if sqlErr, ok := err.(*mysql.MySQLError); ok {
-			return nil, &MySQLError{
-				Number:   sqlErr.Number,
-				SQLState: sqlErr.SQLState,
-				Message:  sqlErr.Message,
-			}
-		}
-		return nil, &MySQLError{}
+		return nil, c.mapError(err)
+	}
+
+	// Create execution context with timeout, bound to the request context
+	ctx, cancel := createContextWithTimeout(params.requestContext(), params.Timeout)
+	defer cancel()
+
+	release, err := c.acquireQuerySlot(ctx)
+	if err != nil {
+		return nil, c.mapError(err)
 	}
+	defer release()
 
 	// Execute query
-	rows, err := prepare.QueryContext(ctx, params.Args...)
+	rows, err := c.queryStmt(ctx, params, query, prepare)
 	if err != nil {
 		// Error handling identical to externalQuery
-		if sqlErr, ok := err.(*mysql.MySQLError); ok && sqlErr.Number == 1213 {
-			return nil, &MySQLError{Number: 45000, Message: "DEADLOCK"}
-		}
-		if errors.Is(err, context.DeadlineExceeded) {
-			return nil, &MySQLError{Number: 45000, Message: "TIMEOUT"}
-		}
-		if sqlErr, ok := err.(*mysql.MySQLError); ok {
-			return nil, &MySQLError{
-				Number:   sqlErr.Number,
-				SQLState: sqlErr.SQLState,
-				Message:  sqlErr.Message,
-			}
-		}
-		return nil, &MySQLError{}
+		return nil, c.mapError(err)
 	}
 	defer rows.Close()
+	limited, limiter := limitRows(rows, params.MaxRows)
 
 	// Process results via callback
-	clbRes, clbErr := callback(rows)
+	clbRes, clbErr := runCallback(c, limited, callback)
+
+	// Same row limit guard as externalQuery
+	if limiter.Exceeded() {
+		return nil, ErrRowLimit
+	}
 
-	// Cache result in L1 if successful and caching enabled
-	if clbErr == nil && clbRes != nil && params.CacheDelay > 0 {
+	// Same truncation guard as externalQuery
+	if clbErr == nil && ctx.Err() != nil {
+		return nil, c.mapError(ctx.Err())
+	}
+
+	// Cache result in L1 if successful, caching enabled, and within the size limit
+	if clbErr == nil && clbRes != nil && params.CacheDelay > 0 && fitsCache(c, clbRes) {
 		if key == "" {
 			if params.Key == "" {
 				key = CreateKey(params, c)
@@ -285,38 +400,236 @@ func internalQuery[T any](
 				key = params.Key
 			}
 		}
-		c.inMemory.Set(key, clbRes, params.CacheDelay)
+		c.setL1(key, clbRes, params.CacheDelay)
 	}
 
 	return clbRes, clbErr
 }
 
-// createContextWithTimeout creates a context with timeout for query execution.
+// runCallback invokes callback on rows. With Options.RecoverCallback a panic
+// in the callback is logged and returned as ErrPanic carrying the recovered
+// value; the caller's deferred rows.Close still runs either way.
+// An error wrapping sql.ErrNoRows is returned as ErrNoRows.
+// A result returned together with an error is dropped unless
+// Options.KeepResultOnCallbackError is set; it is never cached.
+func runCallback[T any](c *MySQL, rows Rows, callback func(rows Rows) (*T, *MySQLError)) (res *T, merr *MySQLError) {
+	if c.recoverCallback {
+		defer func() {
+			if r := recover(); r != nil {
+				cause, ok := r.(error)
+				if !ok {
+					cause = fmt.Errorf("%v", r)
+				}
+				c.log().Error("mysql: query callback panicked", "panic", r, "stack", string(debug.Stack()))
+				res, merr = nil, ErrPanic.WithCause(cause)
+			}
+		}()
+	}
+	res, merr = callback(rows)
+	// A callback reading a missing row usually reports sql.ErrNoRows wrapped
+	// by NewError; expose it as ErrNoRows so one errors.Is check suffices
+	if merr != nil && !errors.Is(merr, ErrNoRows) && errors.Is(merr, sql.ErrNoRows) {
+		merr = ErrNoRows.WithCause(merr)
+	}
+	if merr != nil && !c.keepResultOnError {
+		res = nil
+	}
+	return res, merr
+}
+
+// createContextWithTimeout creates a context with timeout for query execution,
+// derived from parent (context.Background() when nil).
 // If timeout is zero or not specified, uses a conservative default of 100 seconds
 // to prevent queries from hanging indefinitely while allowing long-running operations.
-func createContextWithTimeout(timeout time.Duration) (context.Context, context.CancelFunc) {
+func createContextWithTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if parent == nil {
+		parent = context.Background()
+	}
 	if timeout == 0 {
 		timeout = 100 * time.Second
 	}
-	return context.WithTimeout(context.Background(), timeout)
+	return context.WithTimeout(parent, timeout)
 }
 
-// checkExternalCache retrieves and deserializes an item from external cache.
-// Returns nil on cache miss, deserialization error, or if cache is not configured.
-// Performs type-safe deserialization using the configured codec.
-func checkExternalCache[T any](c *MySQL, key string) *T {
-	// Get raw bytes from external cache
-	data, err := c.cache.Get(key)
+// fitsCache reports whether a result is small enough to cache under
+// MaxValueBytes. L1 holds live objects, so the size is measured by encoding
+// the result with the configured codec; this cost is only paid when a limit is set.
+func fitsCache(c *MySQL, v any) bool {
+	if c.maxValueBytes <= 0 || c.codec == nil {
+		return true
+	}
+	data, err := c.codec.Marshal(v)
+	return err == nil && len(data) <= c.maxValueBytes
+}
+
+// lookupExternalCache reads key from the L2 cache under stampede protection.
+// On a miss it acquires the keyed mutex and re-checks the cache, returning an
+// unlock function the caller must defer so the lock is held while the result
+// is computed and stored. A forced refresh skips both reads but still locks.
+// codec is the per-query codec override, or nil; fp is the reader's
+// fingerprint for DetectKeyCollisions (0 = unchecked). ctx is the request
+// context handed to a ContextStorage (nil = none).
+//
+// When the cache or mutex backend fails, the failure is logged and the query
+// degrades to a direct database read (nil result, no lock), unless
+// Options.DegradeOnCacheError is false, in which case the error is returned.
+func lookupExternalCache[T any](c *MySQL, ctx context.Context, key string, forceRefresh bool, codec Codec, fp uint64) (*T, func(), *MySQLError) {
+	return lookupExternal(c, key, forceRefresh, func() (*T, error) {
+		return checkExternalCache[T](c, ctx, key, codec, fp)
+	})
+}
+
+// lookupExternal implements lookupExternalCache around check, which reads
+// and decodes the entry for key (nil on a miss).
+func lookupExternal[R any](c *MySQL, key string, forceRefresh bool, check func() (*R, error)) (*R, func(), *MySQLError) {
+	// First optimistic check - proceed if cache miss (skipped on forced refresh)
+	if !forceRefresh {
+		res, err := check()
+		if errors.Is(err, errKeyCollision) {
+			return nil, nil, c.keyCollision(key)
+		}
+		if isContextError(err) {
+			// The request gave up; querying the database would fail too
+			return nil, nil, c.mapError(err)
+		}
+		if err != nil {
+			return nil, nil, c.cacheFailure("cache get", key, err, ErrCacheUnavailable)
+		}
+		if res != nil {
+			c.cacheStats.l2Hits.Add(1)
+			return res, nil, nil
+		}
+	}
+
+	// Cache miss - acquire distributed lock to prevent concurrent database queries
+	// for the same cache key (cache stampede protection)
+	mutexKey := "mutex_" + key
+	unlock, err := c.lockKey(mutexKey)
 	if err != nil {
-		// Cache miss or cache error
+		return nil, nil, c.cacheFailure("mutex lock", key, err, ErrLockFailed)
+	}
+
+	// Double-check cache after acquiring lock (other goroutine might have populated it).
+	// A forced refresh must reach the database, so it ignores what is cached.
+	if !forceRefresh {
+		res, err := check()
+		if errors.Is(err, errKeyCollision) {
+			return nil, unlock, c.keyCollision(key)
+		}
+		if isContextError(err) {
+			return nil, unlock, c.mapError(err)
+		}
+		if err != nil {
+			// The lock is held; keep it so concurrent callers still coalesce
+			return nil, unlock, c.cacheFailure("cache get", key, err, ErrCacheUnavailable)
+		}
+		if res != nil {
+			c.cacheStats.l2Hits.Add(1)
+			return res, unlock, nil
+		}
+	}
+	c.cacheStats.misses.Add(1)
+	return nil, unlock, nil
+}
+
+// cacheFailure handles an infrastructure error from the cache or mutex backend.
+// The cause is always logged. It returns nil (degrade to the database), or
+// the given sentinel when degradation is disabled, so the failure is never
+// mistaken for an empty result.
+func (c *MySQL) cacheFailure(op, key string, err error, sentinel *MySQLError) *MySQLError {
+	if errors.Is(err, ErrCacheCircuitOpen) {
+		// The failures that tripped the breaker were already logged; skip quietly
+		if c.failOnCacheError {
+			return sentinel
+		}
 		return nil
 	}
+	if c.failOnCacheError {
+		c.log().Error("mysql: cache backend failure",
+			slog.String("op", op), slog.String("key", key), slog.Any("error", err))
+		return sentinel
+	}
+	c.log().Warn("mysql: cache backend failure, querying database directly",
+		slog.String("op", op), slog.String("key", key), slog.Any("error", err))
+	return nil
+}
+
+// checkExternalCache retrieves and deserializes an item from external cache.
+// Returns a nil result on cache miss or on a corrupted entry, and an error only
+// when the cache backend itself fails (anything other than ErrNotFound) or the
+// entry's fingerprint does not match fp (errKeyCollision).
+// Performs type-safe deserialization using the configured codec.
+func checkExternalCache[T any](c *MySQL, ctx context.Context, key string, codec Codec, fp uint64) (*T, error) {
+	data, err := c.readExternalPayload(ctx, key, fp)
+	if data == nil || err != nil {
+		return nil, err
+	}
 
 	// Deserialize bytes into typed object
 	var obj T
-	if err := c.codec.Unmarshal(data, &obj); err != nil {
+	if err := c.unmarshalCacheValue(codec, data, &obj); err != nil {
 		// Deserialization error - corrupted cache entry or schema mismatch
-		return nil
+		c.decodeFailed(key, err)
+		return nil, nil
+	}
+	return &obj, nil
+}
+
+// readExternalPayload reads key from the external cache and strips the
+// compression, metadata, and fingerprint layers, leaving the (possibly
+// codec-tagged) payload. It follows checkExternalCache: nil data on a miss
+// or corrupted entry, an error on backend failure or key collision. ctx is
+// passed to a ContextStorage.
+func (c *MySQL) readExternalPayload(ctx context.Context, key string, fp uint64) ([]byte, error) {
+	// Get raw bytes from external cache
+	data, err := cacheGet(ctx, c.cache, key)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.externalPayload(key, data, fp)
+}
+
+// externalPayload strips the layers added by externalValue from data read
+// under key. Like readExternalPayload it returns nil for a corrupted entry
+// and errKeyCollision when the fingerprint does not match fp.
+func (c *MySQL) externalPayload(key string, data []byte, fp uint64) ([]byte, error) {
+	// Strip the compression flag and decompress if needed
+	data, err := c.decodeCacheValue(data)
+	if err != nil {
+		c.decodeFailed(key, err)
+		return nil, nil
+	}
+
+	// Drop the metadata envelope, if any; legacy entries pass through
+	if _, data, err = unwrapMetadata(data); err != nil {
+		c.decodeFailed(key, err)
+		return nil, nil
+	}
+
+	// Verify the entry was written by the same statement (DetectKeyCollisions)
+	stored, data, err := unwrapFingerprint(data)
+	if err != nil {
+		c.decodeFailed(key, err)
+		return nil, nil
+	}
+	if err := checkFingerprint(stored, fp); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// decodeFailed records a cached entry that could not be decoded and reports
+// it to Options.OnCacheDecodeError. The caller treats the entry as a miss, so
+// widespread failures (e.g. after a format change) are otherwise invisible.
+func (c *MySQL) decodeFailed(key string, err error) {
+	c.cacheStats.decodeErrors.Add(1)
+	if errors.Is(err, errUnknownCodec) {
+		c.cacheStats.codecMismatches.Add(1)
+	}
+	if c.onCacheDecodeError != nil {
+		c.onCacheDecodeError(key, err)
 	}
-	return &obj
 }
@@ -0,0 +1,111 @@
+package mysql
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// closeTrackingRows records whether Close was called.
+type closeTrackingRows struct {
+	*MockRows
+	closed bool
+}
+
+func (r *closeTrackingRows) Close() error {
+	r.closed = true
+	return nil
+}
+
+func panicDB(rows *closeTrackingRows) *MockDB {
+	db := NewMockDB()
+	db.WithStmt("SELECT v", &MockStmt{Factory: func() Rows { return rows }})
+	return db
+}
+
+func panickingScan(rows Rows) (*int, *MySQLError) {
+	rows.Next()
+	var s string
+	_ = rows.Scan(&s) // the mock asserts string and panics on an int column
+	return nil, nil
+}
+
+func TestQuery_RecoverCallback(t *testing.T) {
+	for _, external := range []bool{false, true} {
+		rows := &closeTrackingRows{MockRows: &MockRows{data: [][]any{{1}}}}
+		var client *MySQL
+		var cleanup func()
+		if external {
+			client, cleanup = newExternalClient(panicDB(rows), newFakeCache())
+		} else {
+			client, cleanup = newInternalClient(panicDB(rows))
+		}
+		client.recoverCallback = true
+		client.logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+		res, err := Query(client, Params{Query: "SELECT v", CacheDelay: 1}, panickingScan)
+		cleanup()
+		if res != nil || !errors.Is(err, ErrPanic) {
+			t.Fatalf("external=%v: expected ErrPanic, got %v, %v", external, res, err)
+		}
+		if cause := errors.Unwrap(err); cause == nil || !strings.Contains(cause.Error(), "interface conversion") {
+			t.Fatalf("external=%v: expected the recovered runtime error as cause, got %v", external, cause)
+		}
+		if !rows.closed {
+			t.Fatalf("external=%v: expected rows to be closed", external)
+		}
+	}
+}
+
+func TestQuery_CallbackPanicPropagatesByDefault(t *testing.T) {
+	rows := &closeTrackingRows{MockRows: &MockRows{data: [][]any{{1}}}}
+	client, cleanup := newInternalClient(panicDB(rows))
+	defer cleanup()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected the panic to propagate without RecoverCallback")
+		}
+		if !rows.closed {
+			t.Fatal("expected rows to be closed while panicking")
+		}
+	}()
+	_, _ = Query(client, Params{Query: "SELECT v"}, panickingScan)
+}
+
+func partialScan(rows Rows) (*[]string, *MySQLError) {
+	res, _ := scanStrings(rows)
+	return res, NewError(errors.New("stopped early"))
+}
+
+func TestQuery_CallbackErrorDropsResult(t *testing.T) {
+	for _, keep := range []bool{false, true} {
+		db := NewMockDB()
+		db.WithStmt("SELECT name", &MockStmt{Factory: func() Rows {
+			return &MockRows{data: [][]any{{"alice"}}}
+		}})
+		client, cleanup := newInternalClient(db)
+		client.keepResultOnError = keep
+
+		params := Params{Query: "SELECT name", CacheDelay: time.Minute}
+		res, err := Query(client, params, partialScan)
+		if err == nil || err.Message != "stopped early" {
+			t.Fatalf("keep=%v: expected the callback error, got %v", keep, err)
+		}
+		if keep && (res == nil || (*res)[0] != "alice") {
+			t.Fatalf("expected the partial result to pass through, got %v", res)
+		}
+		if !keep && res != nil {
+			t.Fatalf("expected a nil result in strict mode, got %v", *res)
+		}
+
+		// Neither mode caches the partial result
+		if _, ok := getL1[[]string](client, CreateKey(params, client)); ok {
+			t.Fatalf("keep=%v: expected the partial result not to be cached", keep)
+		}
+		cleanup()
+	}
+}
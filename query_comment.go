@@ -0,0 +1,38 @@
+package mysql
+
+import "strings"
+
+// prependComment returns query prefixed with comment as "/* comment */ ".
+// The comment is sanitized (see sanitizeComment), so application-supplied
+// text such as request IDs or handler names is safe to pass. Each distinct
+// comment yields distinct query text and therefore its own prepared statement.
+func prependComment(comment, query string) string {
+	return "/* " + sanitizeComment(comment) + " */ " + query
+}
+
+// sanitizeComment makes comment safe to place between "/*" and "*/".
+// A space is inserted between every adjacent '*' and '/', so the text can
+// neither close the comment early nor open a (possibly executable "/*!")
+// comment of its own. '?' and NUL are dropped: client-side interpolation
+// does not skip comments when counting placeholders.
+func sanitizeComment(comment string) string {
+	if !strings.ContainsAny(comment, "*/?\x00") {
+		return comment
+	}
+
+	var b strings.Builder
+	b.Grow(len(comment) + 4)
+	var prev byte
+	for i := 0; i < len(comment); i++ {
+		ch := comment[i]
+		switch {
+		case ch == '?' || ch == 0:
+			continue
+		case (prev == '*' && ch == '/') || (prev == '/' && ch == '*'):
+			b.WriteByte(' ')
+		}
+		b.WriteByte(ch)
+		prev = ch
+	}
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package mysql
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSanitizeComment(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"handler=GetUser", "handler=GetUser"},
+		{"x */ DROP TABLE users; /*", "x * / DROP TABLE users; / *"},
+		{"/*/", "/ * /"},
+		{"*/*/", "* / * /"},
+		{"/*!50000 SELECT 1", "/ *!50000 SELECT 1"},
+		{"id=? and\x00more", "id= andmore"},
+	}
+
+	for _, tt := range tests {
+		got := sanitizeComment(tt.in)
+		if got != tt.want {
+			t.Fatalf("sanitizeComment(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if strings.Contains(got, "*/") || strings.Contains(got, "/*") {
+			t.Fatalf("sanitizeComment(%q) = %q still contains a delimiter", tt.in, got)
+		}
+	}
+}
+
+func TestGenerateQuery_Comment(t *testing.T) {
+	got := generateQuery(Params{Query: "SELECT 1", Comment: "req=42"})
+	if got != "/* req=42 */ SELECT 1" {
+		t.Fatalf("unexpected query: %q", got)
+	}
+
+	got = generateQuery(Params{Exec: "user_get", Args: []any{1}, Comment: "req=42"})
+	if got != "/* req=42 */ CALL user_get(?)" {
+		t.Fatalf("unexpected procedure call: %q", got)
+	}
+}
+
+func TestQuery_CommentInPreparedTextNotInKey(t *testing.T) {
+	calls := 0
+	db := NewMockDB()
+	db.WithStmt("/* handler=a */ SELECT name FROM users", &MockStmt{Factory: func() Rows {
+		calls++
+		return &MockRows{data: [][]any{{"alice"}}}
+	}})
+	db.WithStmt("/* handler=b */ SELECT name FROM users", &MockStmt{Factory: func() Rows {
+		calls++
+		return &MockRows{data: [][]any{{"alice"}}}
+	}})
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	params := Params{Query: "SELECT name FROM users", Comment: "handler=a", CacheDelay: time.Minute}
+	if res, err := Query(client, params, scanStrings); err != nil || (*res)[0] != "alice" {
+		t.Fatalf("unexpected result %v, %v", res, err)
+	}
+	if _, ok := client.prepare["/* handler=a */ SELECT name FROM users"]; !ok {
+		t.Fatalf("expected the commented query to be prepared, got %v", client.prepare)
+	}
+
+	// A different comment shares the cached result of the same statement
+	params.Comment = "handler=b"
+	if res, err := Query(client, params, scanStrings); err != nil || (*res)[0] != "alice" {
+		t.Fatalf("unexpected result %v, %v", res, err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the comment not to affect the cache key, got %d database calls", calls)
+	}
+	if CreateKey(params, client) != CreateKey(Params{Query: "SELECT name FROM users"}, client) {
+		t.Fatal("expected the comment to be excluded from the cache key")
+	}
+}
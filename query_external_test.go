@@ -1,9 +1,14 @@
 package mysql
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
 	"errors"
+	"log/slog"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -70,6 +75,7 @@ func (m *fakeMutex) Lock(key string) error   { return m.lockErr }
 func (m *fakeMutex) Unlock(key string) error { return nil }
 
 type countingDB struct {
+	noDirectStatements
 	prepares int
 }
 
@@ -86,6 +92,7 @@ func (f failingCodec) Marshal(v any) ([]byte, error)      { return nil, errors.N
 func (f failingCodec) Unmarshal(data []byte, v any) error { return nil }
 
 type errDB struct {
+	noDirectStatements
 	err error
 }
 
@@ -119,15 +126,15 @@ func (c *flipCache) Close() error                                        { retur
 func newExternalClient(db DB, cache Storage) (*MySQL, func()) {
 	inMemory := NewInMemoryStorage(10, time.Second)
 	client := &MySQL{
-		DB:           db,
-		dbName:       "db",
-		prepare:      make(map[string]Stmt),
-		cache:        cache,
-		inMemory:     inMemory,
-		mutex:        NewMutex(),
-		codec:        MsgpackCodec{},
-		CacheEnabled: true,
-	}
+		DB:       db,
+		dbName:   "db",
+		prepare:  make(map[string]Stmt),
+		cache:    cache,
+		inMemory: inMemory,
+		mutex:    NewMutex(),
+		codec:    MsgpackCodec{},
+	}
+	client.cacheEnabled.Store(true)
 	return client, func() { inMemory.Stop() }
 }
 
@@ -143,15 +150,15 @@ func TestQuery_ExternalCacheHit(t *testing.T) {
 
 	db := &countingDB{}
 	client := &MySQL{
-		DB:           db,
-		dbName:       "db",
-		prepare:      make(map[string]Stmt),
-		cache:        cache,
-		inMemory:     inMemory,
-		mutex:        NewMutex(),
-		codec:        MsgpackCodec{},
-		CacheEnabled: true,
+		DB:       db,
+		dbName:   "db",
+		prepare:  make(map[string]Stmt),
+		cache:    cache,
+		inMemory: inMemory,
+		mutex:    NewMutex(),
+		codec:    MsgpackCodec{},
 	}
+	client.cacheEnabled.Store(true)
 
 	params := Params{
 		Query:          "SELECT * FROM table",
@@ -210,15 +217,15 @@ func TestQuery_ExternalCacheMissStores(t *testing.T) {
 	db.WithStmt("SELECT * FROM table", stmt)
 
 	client := &MySQL{
-		DB:           db,
-		dbName:       "db",
-		prepare:      make(map[string]Stmt),
-		cache:        cache,
-		inMemory:     inMemory,
-		mutex:        NewMutex(),
-		codec:        MsgpackCodec{},
-		CacheEnabled: true,
+		DB:       db,
+		dbName:   "db",
+		prepare:  make(map[string]Stmt),
+		cache:    cache,
+		inMemory: inMemory,
+		mutex:    NewMutex(),
+		codec:    MsgpackCodec{},
 	}
+	client.cacheEnabled.Store(true)
 
 	params := Params{
 		Query:          "SELECT * FROM table",
@@ -272,15 +279,15 @@ func TestQuery_ExternalCacheCorruptEntry(t *testing.T) {
 	db.WithStmt("SELECT * FROM table", stmt)
 
 	client := &MySQL{
-		DB:           db,
-		dbName:       "db",
-		prepare:      make(map[string]Stmt),
-		cache:        cache,
-		inMemory:     inMemory,
-		mutex:        NewMutex(),
-		codec:        MsgpackCodec{},
-		CacheEnabled: true,
+		DB:       db,
+		dbName:   "db",
+		prepare:  make(map[string]Stmt),
+		cache:    cache,
+		inMemory: inMemory,
+		mutex:    NewMutex(),
+		codec:    MsgpackCodec{},
 	}
+	client.cacheEnabled.Store(true)
 
 	params := Params{
 		Query:          "SELECT * FROM table",
@@ -326,15 +333,15 @@ func TestQuery_ExternalCacheSerializeError(t *testing.T) {
 	db.WithStmt("SELECT * FROM table", stmt)
 
 	client := &MySQL{
-		DB:           db,
-		dbName:       "db",
-		prepare:      make(map[string]Stmt),
-		cache:        cache,
-		inMemory:     inMemory,
-		mutex:        NewMutex(),
-		codec:        failingCodec{},
-		CacheEnabled: true,
+		DB:       db,
+		dbName:   "db",
+		prepare:  make(map[string]Stmt),
+		cache:    cache,
+		inMemory: inMemory,
+		mutex:    NewMutex(),
+		codec:    failingCodec{},
 	}
+	client.cacheEnabled.Store(true)
 
 	params := Params{
 		Query:          "SELECT * FROM table",
@@ -356,31 +363,97 @@ func TestQuery_ExternalCacheSerializeError(t *testing.T) {
 	}
 }
 
+// newDegradeClient builds an external-cache client backed by a single-row
+// MockDB whose warnings are captured in the returned buffer.
+func newDegradeClient(cache Storage, mutex Mutex) (*MySQL, *bytes.Buffer, *int, func()) {
+	calls := 0
+	db := NewMockDB()
+	db.WithStmt("SELECT * FROM table", &MockStmt{Factory: func() Rows {
+		calls++
+		return &MockRows{data: [][]any{{"fresh"}}}
+	}})
+
+	client, cleanup := newExternalClient(db, cache)
+	client.mutex = mutex
+	var logs bytes.Buffer
+	client.logger = slog.New(slog.NewTextHandler(&logs, nil))
+	return client, &logs, &calls, cleanup
+}
+
+func scanStrings(rows Rows) (*[]string, *MySQLError) {
+	var out []string
+	for rows.Next() {
+		var v string
+		_ = rows.Scan(&v)
+		out = append(out, v)
+	}
+	return &out, nil
+}
+
 func TestQuery_ExternalCacheLockError(t *testing.T) {
+	client, logs, calls, cleanup := newDegradeClient(newFakeCache(), &fakeMutex{lockErr: errors.New("lock failed")})
+	defer cleanup()
+
+	params := Params{Query: "SELECT * FROM table", CacheDelay: time.Minute}
+	res, err := Query(client, params, scanStrings)
+	if err != nil || res == nil || (*res)[0] != "fresh" {
+		t.Fatalf("expected lock failure to degrade to a DB read, got %v %+v", res, err)
+	}
+	if *calls != 1 {
+		t.Fatalf("expected the database to be queried once, got %d", *calls)
+	}
+	if !strings.Contains(logs.String(), "lock failed") {
+		t.Fatalf("expected the lock failure to be logged, got %q", logs.String())
+	}
+}
+
+func TestQuery_ExternalCacheGetError(t *testing.T) {
 	cache := newFakeCache()
-	client := &MySQL{
-		DB:           &countingDB{},
-		dbName:       "db",
-		prepare:      make(map[string]Stmt),
-		cache:        cache,
-		inMemory:     NewInMemoryStorage(10, time.Second),
-		mutex:        &fakeMutex{lockErr: errors.New("lock failed")},
-		codec:        MsgpackCodec{},
-		CacheEnabled: true,
+	cache.getErr = errors.New("connection refused")
+	client, logs, calls, cleanup := newDegradeClient(cache, NewMutex())
+	defer cleanup()
+
+	params := Params{Query: "SELECT * FROM table", CacheDelay: time.Minute}
+	res, err := Query(client, params, scanStrings)
+	if err != nil || res == nil || (*res)[0] != "fresh" {
+		t.Fatalf("expected cache failure to degrade to a DB read, got %v %+v", res, err)
+	}
+	if *calls != 1 {
+		t.Fatalf("expected the database to be queried once, got %d", *calls)
+	}
+	if !strings.Contains(logs.String(), "connection refused") {
+		t.Fatalf("expected the cache failure to be logged, got %q", logs.String())
 	}
-	defer client.inMemory.Stop()
+}
 
-	params := Params{
-		Query:      "SELECT * FROM table",
-		CacheDelay: time.Minute,
+func TestQuery_ExternalCacheErrorStrict(t *testing.T) {
+	cache := newFakeCache()
+	cache.getErr = errors.New("connection refused")
+	client, logs, calls, cleanup := newDegradeClient(cache, NewMutex())
+	defer cleanup()
+	client.failOnCacheError = true
+
+	params := Params{Query: "SELECT * FROM table", CacheDelay: time.Minute}
+	res, err := Query(client, params, scanStrings)
+	if !errors.Is(err, ErrCacheUnavailable) || res != nil {
+		t.Fatalf("expected ErrCacheUnavailable, got %v %+v", res, err)
 	}
 
-	res, err := Query(client, params, func(rows Rows) (*[]string, *MySQLError) {
-		t.Fatal("callback should not be invoked on lock error")
-		return nil, nil
-	})
-	if err != nil || res != nil {
-		t.Fatalf("expected nil result and nil error on lock failure")
+	// A lock failure must never look like an empty result
+	client.cache = newFakeCache()
+	client.mutex = &fakeMutex{lockErr: errors.New("lock failed")}
+	res, err = Query(client, params, scanStrings)
+	if !errors.Is(err, ErrLockFailed) || res != nil {
+		t.Fatalf("expected ErrLockFailed, got %v %+v", res, err)
+	}
+	if errors.Is(err, ErrCacheUnavailable) {
+		t.Fatalf("expected lock and cache failures to be distinguishable")
+	}
+	if !strings.Contains(logs.String(), "connection refused") || !strings.Contains(logs.String(), "lock failed") {
+		t.Fatalf("expected causes to be logged, got %q", logs.String())
+	}
+	if *calls != 0 {
+		t.Fatalf("expected no database reads in strict mode, got %d", *calls)
 	}
 }
 
@@ -560,3 +633,352 @@ func TestQuery_ExternalQueryGenericError(t *testing.T) {
 		t.Fatalf("expected generic error, got %+v", err)
 	}
 }
+
+func TestQuery_ExternalForceRefresh(t *testing.T) {
+	cache := newFakeCache()
+	calls := 0
+	stmt := &MockStmt{
+		Factory: func() Rows {
+			calls++
+			return &MockRows{data: [][]any{{2}}}
+		},
+	}
+	db := NewMockDB()
+	db.WithStmt("SELECT * FROM table", stmt)
+
+	client, cleanup := newExternalClient(db, cache)
+	defer cleanup()
+
+	params := Params{
+		Query:          "SELECT * FROM table",
+		CacheDelay:     time.Minute,
+		NodeCacheDelay: time.Minute,
+		ForceRefresh:   true,
+	}
+	key := CreateKey(params, client)
+
+	// Seed both cache levels with a stale value
+	stale := []int{1}
+	data, _ := MsgpackCodec{}.Marshal(&stale)
+	_ = cache.Set(key, data, time.Minute)
+	_ = client.inMemory.Set(key, &stale, time.Minute)
+
+	res, err := Query(client, params, func(rows Rows) (*[]int, *MySQLError) {
+		var out []int
+		for rows.Next() {
+			var v int
+			_ = rows.Scan(&v)
+			out = append(out, v)
+		}
+		return &out, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected database to be queried once, got %d", calls)
+	}
+	if len(*res) != 1 || (*res)[0] != 2 {
+		t.Fatalf("expected fresh result, got %v", *res)
+	}
+
+	var cached []int
+	raw, _ := cache.Get(key)
+	if err := (MsgpackCodec{}).Unmarshal(raw, &cached); err != nil || len(cached) != 1 || cached[0] != 2 {
+		t.Fatalf("expected L2 cache to be refreshed, got %v (%v)", cached, err)
+	}
+	if val, err := client.inMemory.Get(key); err != nil || (*val.(*[]int))[0] != 2 {
+		t.Fatalf("expected L1 cache to be refreshed, got %v (%v)", val, err)
+	}
+}
+
+func TestQuery_ExternalMaxValueBytesSkipsCache(t *testing.T) {
+	cache := newFakeCache()
+	stmt := &MockStmt{
+		Factory: func() Rows {
+			return &MockRows{data: [][]any{{"0123456789012345678901234567890123456789"}}}
+		},
+	}
+	db := NewMockDB()
+	db.WithStmt("SELECT * FROM table", stmt)
+
+	client, cleanup := newExternalClient(db, cache)
+	defer cleanup()
+	client.maxValueBytes = 16
+
+	params := Params{Query: "SELECT * FROM table", CacheDelay: time.Minute, NodeCacheDelay: time.Minute}
+	res, err := Query(client, params, func(rows Rows) (*string, *MySQLError) {
+		var v string
+		rows.Next()
+		_ = rows.Scan(&v)
+		return &v, nil
+	})
+	if err != nil || res == nil || len(*res) != 40 {
+		t.Fatalf("expected query to succeed despite size limit, got %v (%v)", res, err)
+	}
+
+	key := CreateKey(params, client)
+	if cache.setCalls != 0 {
+		t.Fatalf("expected oversized result not to be written to L2")
+	}
+	if _, err := client.inMemory.Get(key); err != ErrNotFound {
+		t.Fatalf("expected oversized result not to be written to L1, got %v", err)
+	}
+}
+
+func TestQuery_CacheVersionOrphansOldEntries(t *testing.T) {
+	cache := newFakeCache()
+	calls := 0
+	db := NewMockDB()
+	db.WithStmt("SELECT * FROM table", &MockStmt{Factory: func() Rows {
+		calls++
+		return &MockRows{data: [][]any{{"fresh"}}}
+	}})
+
+	client, cleanup := newExternalClient(db, cache)
+	defer cleanup()
+	client.cacheVersion = "v1"
+
+	params := Params{Query: "SELECT * FROM table", CacheDelay: time.Minute, NodeCacheDelay: time.Minute}
+	if _, err := Query(client, params, scanStrings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	oldKey := CreateKey(params, client)
+	if _, err := cache.Get(oldKey); err != nil {
+		t.Fatalf("expected L2 entry under the versioned key, got %v", err)
+	}
+	if _, err := client.inMemory.Get(oldKey); err != nil {
+		t.Fatalf("expected L1 entry under the versioned key, got %v", err)
+	}
+
+	// Bumping the version must bypass both cache levels populated under v1
+	client.cacheVersion = "v2"
+	if _, err := Query(client, params, scanStrings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected the database to be queried again after a version bump, got %d calls", calls)
+	}
+	if newKey := CreateKey(params, client); newKey == oldKey {
+		t.Fatalf("expected a different key after the version bump")
+	}
+}
+
+func TestQuery_MaxKeyBytesSharedByL1AndL2(t *testing.T) {
+	cache := newFakeCache()
+	calls := 0
+	db := NewMockDB()
+	db.WithStmt("SELECT * FROM table", &MockStmt{Factory: func() Rows {
+		calls++
+		return &MockRows{data: [][]any{{"row"}}}
+	}})
+	params := Params{
+		Query:          "SELECT * FROM table",
+		Args:           []any{strings.Repeat("tenant-", 50), 1},
+		CacheDelay:     time.Minute,
+		NodeCacheDelay: time.Minute,
+	}
+
+	client, cleanup := newExternalClient(db, cache)
+	defer cleanup()
+	client.maxKeyBytes = 250
+	if _, err := Query(client, params, scanStrings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	key := CreateKey(params, client)
+	if len(key) != 250 {
+		t.Fatalf("expected a 250-byte key, got %d bytes", len(key))
+	}
+	if len(cache.items) != 1 {
+		t.Fatalf("expected one L2 entry, got %d", len(cache.items))
+	}
+	if _, err := cache.Get(key); err != nil {
+		t.Fatalf("expected the L2 entry under the shortened key, got %v", err)
+	}
+	if _, err := client.inMemory.Get(key); err != nil {
+		t.Fatalf("expected the L1 entry under the shortened key, got %v", err)
+	}
+
+	// Another node computes the same key and is served from L2
+	other, cleanupOther := newExternalClient(db, cache)
+	defer cleanupOther()
+	other.maxKeyBytes = 250
+	if res, err := Query(other, params, scanStrings); err != nil || (*res)[0] != "row" || calls != 1 {
+		t.Fatalf("expected an L2 hit, got %v (%v) after %d queries", res, err, calls)
+	}
+}
+
+// leaderStmt blocks the first query until its context is cancelled and
+// answers every later query immediately.
+type leaderStmt struct {
+	mu      sync.Mutex
+	calls   int
+	started chan struct{}
+}
+
+func (s *leaderStmt) QueryContext(ctx context.Context, args ...any) (Rows, error) {
+	s.mu.Lock()
+	s.calls++
+	first := s.calls == 1
+	s.mu.Unlock()
+
+	if first {
+		close(s.started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return &MockRows{data: [][]any{{"fresh"}}}, nil
+}
+
+func (s *leaderStmt) QueryRowContext(ctx context.Context, args ...any) Row {
+	return rowFromRows(s.QueryContext(ctx, args...))
+}
+
+func (s *leaderStmt) ExecContext(ctx context.Context, args ...any) (sql.Result, error) {
+	return MockResult{}, nil
+}
+
+func (s *leaderStmt) Close() error { return nil }
+
+type leaderDB struct {
+	noDirectStatements
+	stmt *leaderStmt
+}
+
+func (d *leaderDB) PrepareContext(ctx context.Context, query string) (Stmt, error) {
+	return d.stmt, nil
+}
+
+func (d *leaderDB) Close() error { return nil }
+
+func TestQuery_ExternalCancelledLeaderReleasesLock(t *testing.T) {
+	stmt := &leaderStmt{started: make(chan struct{})}
+	client, cleanup := newExternalClient(&leaderDB{stmt: stmt}, newFakeCache())
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	params := Params{Query: "SELECT * FROM table", CacheDelay: time.Minute}
+
+	leaderErr := make(chan *MySQLError, 1)
+	go func() {
+		leaderParams := params
+		leaderParams.Context = ctx
+		_, err := Query(client, leaderParams, scanStrings)
+		leaderErr <- err
+	}()
+
+	// The waiter queues on the keyed mutex behind the blocked leader
+	<-stmt.started
+	waiterRes := make(chan *[]string, 1)
+	go func() {
+		res, err := Query(client, params, scanStrings)
+		if err != nil {
+			t.Errorf("waiter: unexpected error: %v", err)
+		}
+		waiterRes <- res
+	}()
+
+	cancel()
+
+	select {
+	case err := <-leaderErr:
+		if err == nil {
+			t.Fatal("expected the cancelled leader to fail")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("leader did not abort after cancellation")
+	}
+
+	select {
+	case res := <-waiterRes:
+		if res == nil || len(*res) != 1 || (*res)[0] != "fresh" {
+			t.Fatalf("unexpected waiter result: %v", res)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter was not released after the leader was cancelled")
+	}
+}
+
+func TestQuery_DisableL1(t *testing.T) {
+	cache := newFakeCache()
+	var calls atomic.Int32
+	db := NewMockDB()
+	db.WithStmt("SELECT * FROM table", &MockStmt{Delay: 10 * time.Millisecond, Factory: func() Rows {
+		calls.Add(1)
+		return &MockRows{data: [][]any{{"fresh"}}}
+	}})
+
+	client, cleanup := newExternalClient(db, cache)
+	defer cleanup()
+	client.disableL1 = true
+
+	params := Params{Query: "SELECT * FROM table", CacheDelay: time.Minute, NodeCacheDelay: time.Minute}
+
+	// Concurrent misses still coalesce on the keyed mutex
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if res, err := Query(client, params, scanStrings); err != nil || (*res)[0] != "fresh" {
+				t.Errorf("unexpected result %v (%v)", res, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected a single database query, got %d", n)
+	}
+
+	key := CreateKey(params, client)
+	if _, err := client.inMemory.Get(key); err != ErrNotFound {
+		t.Fatalf("expected L1 to stay empty, got %v", err)
+	}
+
+	// Later reads are L2 hits that do not warm L1 either
+	if _, err := Query(client, params, scanStrings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls.Load() != 1 || len(client.inMemory.(*InMemoryStorage).Keys()) != 0 {
+		t.Fatalf("expected an L2 hit without L1 writes")
+	}
+}
+
+func TestQuery_CorruptCacheEntryReportsDecodeError(t *testing.T) {
+	cache := newFakeCache()
+	calls := 0
+	db := NewMockDB()
+	db.WithStmt("SELECT name FROM users", &MockStmt{Factory: func() Rows {
+		calls++
+		return &MockRows{data: [][]any{{"alice"}}}
+	}})
+	client, cleanup := newExternalClient(db, cache)
+	defer cleanup()
+	client.codec = MsgpackCodec{}
+
+	var gotKey string
+	var gotErr error
+	client.onCacheDecodeError = func(key string, err error) {
+		gotKey, gotErr = key, err
+	}
+
+	// 0xc1 is never used by MessagePack, so decoding must fail
+	_ = cache.Set("users", []byte{0xc1}, time.Minute)
+
+	params := Params{Query: "SELECT name FROM users", Key: "users", CacheDelay: time.Minute}
+	res, err := Query(client, params, scanStrings)
+	if err != nil || (*res)[0] != "alice" {
+		t.Fatalf("expected fall-through to the database, got %v, %v", res, err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the database to be queried once, got %d", calls)
+	}
+	if gotKey != "users" || gotErr == nil {
+		t.Fatalf("expected hook for key users, got %q, %v", gotKey, gotErr)
+	}
+	// Reported per read: before and again after taking the stampede lock
+	if n := client.Stats().DecodeErrors; n == 0 {
+		t.Fatal("expected decode errors to be counted")
+	}
+}
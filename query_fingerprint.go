@@ -0,0 +1,183 @@
+package mysql
+
+import "strings"
+
+// Fingerprint reduces query to a stable, low-cardinality label for metrics
+// and logs: queries that differ only in literal values share a fingerprint.
+//
+//   - string and number literals (including signed, decimal, exponent and
+//     0x/0b forms) become ?, so WHERE id=42 and WHERE id=7 both yield
+//     WHERE id=?
+//   - IN lists of any length collapse to IN (?+), covering both literal
+//     lists and the placeholders produced by ExpandIN
+//   - comments are dropped and whitespace is collapsed as in NormalizeQueries
+//
+// Identifiers, keywords and their case are kept as written, and quoted
+// identifiers (`...`) are preserved; "..." is treated as a string literal,
+// which is wrong only under the ANSI_QUOTES SQL mode.
+func Fingerprint(query string) string {
+	buf := make([]byte, 0, len(query))
+	pendingSpace := false
+
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+
+		switch {
+		case isSpace(ch):
+			pendingSpace = len(buf) > 0
+			continue
+		case ch == '#' || startsDashComment(query, i):
+			i = skipLine(query, i)
+			pendingSpace = len(buf) > 0
+			continue
+		case ch == '/' && i+1 < len(query) && query[i+1] == '*':
+			i = skipBlockComment(query, i)
+			pendingSpace = len(buf) > 0
+			continue
+		}
+		if pendingSpace {
+			buf = append(buf, ' ')
+			pendingSpace = false
+		}
+
+		switch {
+		case ch == '\'' || ch == '"':
+			i = skipQuoted(query, i, ch)
+			// Adjacent literals ('it''s', 'a' 'b') form one value
+			for i+1 < len(query) && query[i+1] == ch {
+				i = skipQuoted(query, i+1, ch)
+			}
+			buf = append(buf, '?')
+		case ch == '`':
+			end := skipQuoted(query, i, ch)
+			buf = append(buf, query[i:end+1]...)
+			i = end
+		case isIdentByte(ch) && !isDigit(ch):
+			end := scanIdent(query, i)
+			buf = append(buf, query[i:end]...)
+			i = end - 1
+		case isDigit(ch) || ch == '.' && startsNumber(query, i) && !endsQualifier(buf):
+			end := scanNumber(query, i)
+			if end < len(query) && isIdentByte(query[end]) {
+				// An identifier starting with digits, e.g. 1st_column
+				end = scanIdent(query, end)
+				buf = append(buf, query[i:end]...)
+			} else {
+				buf = append(buf, '?')
+			}
+			i = end - 1
+		case (ch == '-' || ch == '+') && startsNumber(query, i+1) && signsLiteral(buf):
+			// Drop the sign; the number that follows becomes ?
+		case ch == ')':
+			buf = append(collapseInList(buf), ch)
+		default:
+			buf = append(buf, ch)
+		}
+	}
+
+	return string(buf)
+}
+
+// collapseInList rewrites buf ending in "IN (?, ?, ?" (any case, spacing or
+// count) to end in "IN (?+" instead, just before the closing parenthesis is
+// appended. Other parenthesized lists are returned unchanged.
+func collapseInList(buf []byte) []byte {
+	open := len(buf) - 1
+	items := 0
+	for ; open >= 0 && buf[open] != '('; open-- {
+		switch buf[open] {
+		case '?':
+			items++
+		case ',', ' ':
+		default:
+			return buf
+		}
+	}
+	if open < 0 || items == 0 {
+		return buf
+	}
+
+	word := strings.TrimRight(string(buf[:open]), " ")
+	if len(word) < 2 || !strings.EqualFold(word[len(word)-2:], "in") ||
+		len(word) > 2 && isIdentByte(word[len(word)-3]) {
+		return buf
+	}
+	return append(buf[:open+1], '?', '+')
+}
+
+// isIdentByte reports whether ch can appear in an unquoted identifier.
+func isIdentByte(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || isDigit(ch) || ch == '_' || ch == '$' || ch >= 0x80
+}
+
+// isDigit reports whether ch is an ASCII digit.
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+// scanIdent returns the index just past the identifier bytes starting at i.
+func scanIdent(query string, i int) int {
+	for i < len(query) && isIdentByte(query[i]) {
+		i++
+	}
+	return i
+}
+
+// startsNumber reports whether a numeric literal starts at i: a digit, or a
+// '.' followed by one.
+func startsNumber(query string, i int) bool {
+	if i >= len(query) {
+		return false
+	}
+	if query[i] == '.' {
+		return i+1 < len(query) && isDigit(query[i+1])
+	}
+	return isDigit(query[i])
+}
+
+// scanNumber returns the index just past the numeric literal starting at i.
+func scanNumber(query string, i int) int {
+	if i+2 < len(query) && query[i] == '0' && strings.IndexByte("xXbB", query[i+1]) >= 0 {
+		j := i + 2
+		for j < len(query) && strings.IndexByte("0123456789abcdefABCDEF", query[j]) >= 0 {
+			j++
+		}
+		if j > i+2 {
+			return j
+		}
+	}
+
+	j := i
+	for j < len(query) && isDigit(query[j]) {
+		j++
+	}
+	if j < len(query) && query[j] == '.' {
+		for j++; j < len(query) && isDigit(query[j]); j++ {
+		}
+	}
+	if j < len(query) && (query[j] == 'e' || query[j] == 'E') {
+		k := j + 1
+		if k < len(query) && (query[k] == '+' || query[k] == '-') {
+			k++
+		}
+		if k < len(query) && isDigit(query[k]) {
+			for j = k; j < len(query) && isDigit(query[j]); j++ {
+			}
+		}
+	}
+	return j
+}
+
+// endsQualifier reports whether buf ends in a name, so a following '.'
+// qualifies it (t.col) rather than starting a number (.5).
+func endsQualifier(buf []byte) bool {
+	return len(buf) > 0 && (isIdentByte(buf[len(buf)-1]) || buf[len(buf)-1] == '`')
+}
+
+// signsLiteral reports whether a '+' or '-' appended to buf would be the
+// sign of a literal rather than a binary operator: it starts the query or
+// follows an operator, '(' or ','.
+func signsLiteral(buf []byte) bool {
+	last := strings.TrimRight(string(buf), " ")
+	return last == "" || strings.IndexByte("=<>!(,+-*/%", last[len(last)-1]) >= 0
+}
@@ -0,0 +1,45 @@
+package mysql
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"numbers", "SELECT * FROM users WHERE id=42", "SELECT * FROM users WHERE id=?"},
+		{"placeholders kept", "SELECT * FROM users WHERE id = ?", "SELECT * FROM users WHERE id = ?"},
+		{"strings", `SELECT id FROM users WHERE name = 'O\'Brien' OR name = "x" OR name = 'it''s'`, "SELECT id FROM users WHERE name = ? OR name = ? OR name = ?"},
+		{"numeric forms", "SELECT 1.5, .25, 1e-3, 0x1F, 0b101, -7, +3", "SELECT ?, ?, ?, ?, ?, ?, ?"},
+		{"binary minus kept", "SELECT a-1, b - 2, c=-3 FROM t", "SELECT a-?, b - ?, c=? FROM t"},
+		{"identifiers with digits", "SELECT t1.col2, `tbl 3`.x, 1st_col FROM t1", "SELECT t1.col2, `tbl 3`.x, 1st_col FROM t1"},
+		{"in lists", "SELECT * FROM t WHERE id IN (1, 2, 3) AND k in('a','b') AND z NOT IN ( ? )", "SELECT * FROM t WHERE id IN (?+) AND k in(?+) AND z NOT IN (?+)"},
+		{"other lists kept", "SELECT COALESCE(a, 1), f(?, ?) FROM t JOIN (SELECT 1) s", "SELECT COALESCE(a, ?), f(?, ?) FROM t JOIN (SELECT ?) s"},
+		{"comments and whitespace", "/* app */ SELECT  *\n\tFROM t -- trailing\nWHERE x = 1 # note", "SELECT * FROM t WHERE x = ?"},
+		{"limit", "SELECT * FROM t LIMIT 10 OFFSET 20", "SELECT * FROM t LIMIT ? OFFSET ?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Fingerprint(tt.query); got != tt.want {
+				t.Fatalf("Fingerprint(%q)\n got %q\nwant %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFingerprint_StableAcrossValues(t *testing.T) {
+	expanded, _ := ExpandIN("SELECT name FROM users WHERE tenant = 'd' AND id IN (?)", []any{[]int{1, 2, 3, 4, 5}})
+	queries := []string{
+		"SELECT name FROM users WHERE tenant = 'a' AND id IN (1)",
+		"SELECT name FROM users WHERE tenant = 'bb' AND id IN (1, 2, 3)",
+		"SELECT name\nFROM users WHERE tenant = \"c\" AND id IN (?, ?)",
+		expanded,
+	}
+	want := Fingerprint(queries[0])
+	for _, q := range queries[1:] {
+		if got := Fingerprint(q); got != want {
+			t.Fatalf("Fingerprint(%q) = %q, want %q", q, got, want)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package mysql
 
-import "sync"
+import (
+	"strings"
+	"sync"
+)
 
 // keyBufPool is a pool of reusable byte buffers for query generation.
 // Each buffer is initially allocated with 1024 bytes capacity to accommodate
@@ -15,6 +18,18 @@ var keyBufPool = sync.Pool{
 	},
 }
 
+// withDefaultDatabase returns p with Database set to the client's database
+// when empty, so stored procedure calls are qualified ("CALL app.get_user(?)")
+// without repeating the name on every call. An Exec that is already
+// qualified ("otherdb.proc") is left as is. Cache keys already fall back to
+// the client database, so they are unaffected.
+func (c *MySQL) withDefaultDatabase(p Params) Params {
+	if p.Database == "" && !strings.Contains(p.Exec, ".") {
+		p.Database = c.dbName
+	}
+	return p
+}
+
 // generateQuery constructs a MySQL stored procedure call query from parameters.
 // It's optimized for minimal allocations by reusing byte buffers from a pool
 // and pre-calculating the exact buffer size needed.
@@ -24,7 +39,15 @@ var keyBufPool = sync.Pool{
 //
 // This function is particularly useful when working with prepared statements
 // that call stored procedures with variable numbers of parameters.
+//
+// A Params.Comment is prepended to either form (see prependComment).
 func generateQuery(params Params) string {
+	if params.Comment != "" {
+		comment := params.Comment
+		params.Comment = ""
+		return prependComment(comment, generateQuery(params))
+	}
+
 	// Fast path: if a direct query is provided, return it unchanged
 	if params.Query != "" {
 		return params.Query
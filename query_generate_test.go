@@ -150,3 +150,51 @@ func TestGenerateQuery_LargeBuffer(t *testing.T) {
 		t.Fatalf("unexpected query length=%d", len(got))
 	}
 }
+
+func TestQuery_ProcedureUsesClientDatabase(t *testing.T) {
+	db := NewMockDB()
+	for _, q := range []string{"CALL app.get_user(?)", "CALL reporting.get_user(?)"} {
+		db.WithStmt(q, &MockStmt{Factory: func() Rows {
+			return &MockRows{data: [][]any{{"alice"}}}
+		}})
+	}
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+	client.dbName = "app"
+
+	if _, err := Query(client, Params{Exec: "get_user", Args: []any{1}}, scanStrings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := client.prepare["CALL app.get_user(?)"]; !ok {
+		t.Fatalf("expected the client database to qualify the call, got %v", client.prepare)
+	}
+
+	if _, err := Query(client, Params{Exec: "get_user", Database: "reporting", Args: []any{1}}, scanStrings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := client.prepare["CALL reporting.get_user(?)"]; !ok {
+		t.Fatalf("expected Params.Database to override the client database, got %v", client.prepare)
+	}
+
+	if got := client.DebugQuery(Params{Exec: "get_user", Args: []any{1}}); got != "CALL app.get_user(1)" {
+		t.Fatalf("unexpected debug query: %q", got)
+	}
+}
+
+func TestQuery_QualifiedExecKeepsItsDatabase(t *testing.T) {
+	client := &MySQL{dbName: "app"}
+	if got := client.DebugQuery(Params{Exec: "otherdb.get_user", Args: []any{1}}); got != "CALL otherdb.get_user(1)" {
+		t.Fatalf("unexpected debug query: %q", got)
+	}
+	if got := client.withDefaultDatabase(Params{Exec: "otherdb.get_user"}); got.Database != "" {
+		t.Fatalf("expected no default database, got %q", got.Database)
+	}
+}
+
+func TestQuery_DefaultDatabaseKeepsCacheKey(t *testing.T) {
+	client := &MySQL{dbName: "app"}
+	params := Params{Exec: "get_user", Args: []any{1}}
+	if CreateKey(params, client) != CreateKey(client.withDefaultDatabase(params), client) {
+		t.Fatal("expected the defaulted database not to change the cache key")
+	}
+}
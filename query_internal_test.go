@@ -126,3 +126,80 @@ func TestQuery_InternalQueryGenericError(t *testing.T) {
 		t.Fatalf("expected generic error, got %+v", err)
 	}
 }
+
+func TestQuery_InternalForceRefresh(t *testing.T) {
+	calls := 0
+	stmt := &MockStmt{
+		Factory: func() Rows {
+			calls++
+			return &MockRows{data: [][]any{{2}}}
+		},
+	}
+	db := NewMockDB()
+	db.WithStmt("SELECT * FROM table", stmt)
+
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	params := Params{Query: "SELECT * FROM table", CacheDelay: time.Minute, ForceRefresh: true}
+	key := CreateKey(params, client)
+	stale := []int{1}
+	_ = client.inMemory.Set(key, &stale, time.Minute)
+
+	res, err := Query(client, params, func(rows Rows) (*[]int, *MySQLError) {
+		var out []int
+		for rows.Next() {
+			var v int
+			_ = rows.Scan(&v)
+			out = append(out, v)
+		}
+		return &out, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 || (*res)[0] != 2 {
+		t.Fatalf("expected a fresh database read, calls=%d res=%v", calls, *res)
+	}
+	if val, err := client.inMemory.Get(key); err != nil || (*val.(*[]int))[0] != 2 {
+		t.Fatalf("expected L1 cache to be refreshed, got %v (%v)", val, err)
+	}
+}
+
+func TestQuery_InternalMaxValueBytesSkipsCache(t *testing.T) {
+	db := NewMockDB()
+	db.WithStmt("SELECT big", &MockStmt{Factory: func() Rows {
+		return &MockRows{data: [][]any{{"0123456789012345678901234567890123456789"}}}
+	}})
+	db.WithStmt("SELECT small", &MockStmt{Factory: func() Rows {
+		return &MockRows{data: [][]any{{"ok"}}}
+	}})
+
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+	client.codec = MsgpackCodec{}
+	client.maxValueBytes = 16
+
+	callback := func(rows Rows) (*string, *MySQLError) {
+		var v string
+		rows.Next()
+		_ = rows.Scan(&v)
+		return &v, nil
+	}
+
+	big := Params{Query: "SELECT big", CacheDelay: time.Minute}
+	small := Params{Query: "SELECT small", CacheDelay: time.Minute}
+	if _, err := Query(client, small, callback); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res, err := Query(client, big, callback); err != nil || len(*res) != 40 {
+		t.Fatalf("expected oversized query to succeed, got %v (%v)", res, err)
+	}
+
+	if _, err := client.inMemory.Get(CreateKey(big, client)); err != ErrNotFound {
+		t.Fatalf("expected oversized result not to be cached, got %v", err)
+	}
+	if _, err := client.inMemory.Get(CreateKey(small, client)); err != nil {
+		t.Fatalf("expected small result to stay cached, got %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+package mysql
+
+import "context"
+
+// acquireQuerySlot registers a statement about to run against the database.
+// With MaxConcurrentQueries set it waits for a free slot, giving up when ctx
+// is done. The returned function releases the slot and must be called once
+// the statement (including scanning its rows) has finished.
+func (c *MySQL) acquireQuerySlot(ctx context.Context) (func(), error) {
+	if c.querySlots != nil {
+		select {
+		case c.querySlots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	c.activeQueries.Add(1)
+	return func() {
+		c.activeQueries.Add(-1)
+		if c.querySlots != nil {
+			<-c.querySlots
+		}
+	}, nil
+}
@@ -0,0 +1,124 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// concurrencyStmt records the peak number of overlapping QueryContext calls.
+type concurrencyStmt struct {
+	active atomic.Int32
+	peak   atomic.Int32
+	delay  time.Duration
+}
+
+func (s *concurrencyStmt) QueryContext(ctx context.Context, args ...any) (Rows, error) {
+	n := s.active.Add(1)
+	defer s.active.Add(-1)
+	for {
+		p := s.peak.Load()
+		if n <= p || s.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	time.Sleep(s.delay)
+	return &MockRows{data: [][]any{{"x"}}}, nil
+}
+
+func (s *concurrencyStmt) QueryRowContext(ctx context.Context, args ...any) Row {
+	return rowFromRows(s.QueryContext(ctx, args...))
+}
+
+func (s *concurrencyStmt) ExecContext(ctx context.Context, args ...any) (sql.Result, error) {
+	return MockResult{}, nil
+}
+
+func (s *concurrencyStmt) Close() error { return nil }
+
+type concurrencyDB struct {
+	noDirectStatements
+	stmt *concurrencyStmt
+}
+
+func (d *concurrencyDB) PrepareContext(ctx context.Context, query string) (Stmt, error) {
+	return d.stmt, nil
+}
+
+func (d *concurrencyDB) Close() error { return nil }
+
+func TestQuery_MaxConcurrentQueriesBoundsDB(t *testing.T) {
+	stmt := &concurrencyStmt{delay: 10 * time.Millisecond}
+	client, cleanup := newInternalClient(&concurrencyDB{stmt: stmt})
+	defer cleanup()
+	client.querySlots = make(chan struct{}, 2)
+
+	var wg sync.WaitGroup
+	errs := make(chan *MySQLError, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := Query(client, Params{Query: "SELECT 1", Timeout: time.Second}, scanStrings)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if peak := stmt.peak.Load(); peak > 2 {
+		t.Fatalf("expected at most 2 concurrent queries, saw %d", peak)
+	}
+	if n := client.Stats().InFlightQueries; n != 0 {
+		t.Fatalf("expected no in-flight queries after completion, got %d", n)
+	}
+}
+
+func TestQuery_MaxConcurrentQueriesTimesOutWhileQueued(t *testing.T) {
+	stmt := &concurrencyStmt{}
+	client, cleanup := newInternalClient(&concurrencyDB{stmt: stmt})
+	defer cleanup()
+	client.querySlots = make(chan struct{}, 1)
+
+	release, err := client.acquireQuerySlot(context.Background())
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	defer release()
+	if n := client.Stats().InFlightQueries; n != 1 {
+		t.Fatalf("expected 1 in-flight query, got %d", n)
+	}
+
+	_, merr := Query(client, Params{Query: "SELECT 1", Timeout: 20 * time.Millisecond}, scanStrings)
+	if !errors.Is(merr, ErrTimeout) {
+		t.Fatalf("expected ErrTimeout while queued, got %v", merr)
+	}
+	if stmt.peak.Load() != 0 {
+		t.Fatal("queued query must not reach the database")
+	}
+}
+
+func TestQuery_CacheHitsBypassConcurrencyLimit(t *testing.T) {
+	client, cleanup := newInternalClient(&countingDB{})
+	defer cleanup()
+	client.querySlots = make(chan struct{}, 1)
+	client.querySlots <- struct{}{} // Exhaust the only slot
+
+	params := Params{Query: "SELECT 1", Key: "hit", CacheDelay: time.Minute}
+	want := []string{"cached"}
+	client.inMemory.Set("hit", &want, time.Minute)
+
+	res, err := Query(client, params, scanStrings)
+	if err != nil || res == nil || (*res)[0] != "cached" {
+		t.Fatalf("expected cache hit, got %v, %v", res, err)
+	}
+}
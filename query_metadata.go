@@ -0,0 +1,30 @@
+package mysql
+
+import "context"
+
+// metadataKey is the context key under which Params.Metadata is stored.
+type metadataKey struct{}
+
+// MetadataFromContext returns the Params.Metadata attached to a context
+// created for a query, or nil if there is none. Custom DB and Stmt
+// implementations can use it to read values such as tenant or trace IDs.
+func MetadataFromContext(ctx context.Context) map[string]any {
+	if ctx == nil {
+		return nil
+	}
+	md, _ := ctx.Value(metadataKey{}).(map[string]any)
+	return md
+}
+
+// requestContext returns params.Context carrying params.Metadata, if any.
+// The result may be nil, which createContextWithTimeout treats as Background.
+func (p Params) requestContext() context.Context {
+	if len(p.Metadata) == 0 {
+		return p.Context
+	}
+	parent := p.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	return context.WithValue(parent, metadataKey{}, p.Metadata)
+}
@@ -0,0 +1,83 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+// metadataDB records the metadata seen on the contexts it receives.
+type metadataDB struct {
+	noDirectStatements
+	prepared map[string]any
+	queried  map[string]any
+	executed map[string]any
+}
+
+func (d *metadataDB) PrepareContext(ctx context.Context, query string) (Stmt, error) {
+	d.prepared = MetadataFromContext(ctx)
+	return &metadataStmt{db: d}, nil
+}
+
+func (d *metadataDB) Close() error { return nil }
+
+type metadataStmt struct {
+	db *metadataDB
+}
+
+func (s *metadataStmt) QueryContext(ctx context.Context, args ...any) (Rows, error) {
+	s.db.queried = MetadataFromContext(ctx)
+	return &MockRows{data: [][]any{{"x"}}}, nil
+}
+
+func (s *metadataStmt) QueryRowContext(ctx context.Context, args ...any) Row {
+	return rowFromRows(s.QueryContext(ctx, args...))
+}
+
+func (s *metadataStmt) ExecContext(ctx context.Context, args ...any) (sql.Result, error) {
+	s.db.executed = MetadataFromContext(ctx)
+	return MockResult{}, nil
+}
+
+func (s *metadataStmt) Close() error { return nil }
+
+func TestQuery_MetadataReachesDB(t *testing.T) {
+	db := &metadataDB{}
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	type traceKey struct{}
+	parent := context.WithValue(context.Background(), traceKey{}, "kept")
+	md := map[string]any{"tenant": "acme", "trace": "t-1"}
+
+	_, err := Query(client, Params{Query: "SELECT 1", Context: parent, Metadata: md}, scanStrings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if db.prepared["tenant"] != "acme" || db.queried["trace"] != "t-1" {
+		t.Fatalf("metadata not propagated: prepare=%v query=%v", db.prepared, db.queried)
+	}
+
+	if _, err := Exec(client, Params{Query: "UPDATE t SET x = 1", Metadata: md}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if db.executed["tenant"] != "acme" {
+		t.Fatalf("metadata not propagated to Exec: %v", db.executed)
+	}
+}
+
+func TestParams_RequestContext(t *testing.T) {
+	if ctx := (Params{}).requestContext(); ctx != nil {
+		t.Fatalf("expected nil context without metadata, got %v", ctx)
+	}
+	if md := MetadataFromContext(context.Background()); md != nil {
+		t.Fatalf("expected no metadata, got %v", md)
+	}
+
+	type key struct{}
+	parent := context.WithValue(context.Background(), key{}, "v")
+	ctx := Params{Context: parent, Metadata: map[string]any{"a": 1}}.requestContext()
+	if ctx.Value(key{}) != "v" || MetadataFromContext(ctx)["a"] != 1 {
+		t.Fatal("expected metadata layered on the request context")
+	}
+}
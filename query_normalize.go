@@ -0,0 +1,93 @@
+package mysql
+
+// normalizeQuery collapses runs of whitespace into a single space and trims
+// leading and trailing whitespace, so that queries differing only in
+// formatting share one prepared statement. Quoted string literals ('...',
+// "..."), quoted identifiers (`...`) and comments (-- ..., # ..., /* ... */)
+// are copied verbatim, using the same scanner as forEachPlaceholder. The
+// newline ending a line comment is kept, so the text after it is not pulled
+// into the comment; whitespace following that newline is dropped.
+//
+// The input is returned unchanged (without allocating) when it is already
+// in normalized form.
+func normalizeQuery(query string) string {
+	if isNormalized(query) {
+		return query
+	}
+
+	buf := make([]byte, 0, len(query))
+	pendingSpace := false
+
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+
+		if isSpace(ch) {
+			pendingSpace = len(buf) > 0
+			continue
+		}
+		if pendingSpace {
+			buf = append(buf, ' ')
+			pendingSpace = false
+		}
+
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			end := skipQuoted(query, i, ch)
+			buf = append(buf, query[i:end+1]...)
+			i = end
+		case ch == '#' || startsDashComment(query, i):
+			end := skipLine(query, i)
+			if end == len(query) {
+				return string(append(buf, query[i:]...))
+			}
+			// Keep the newline; it ends the comment and separates the next token
+			buf = append(buf, query[i:end+1]...)
+			for i = end; i+1 < len(query) && isSpace(query[i+1]); i++ {
+			}
+		case ch == '/' && i+1 < len(query) && query[i+1] == '*':
+			end := skipBlockComment(query, i)
+			buf = append(buf, query[i:end+1]...)
+			i = end
+		default:
+			buf = append(buf, ch)
+		}
+	}
+
+	return string(buf)
+}
+
+// isNormalized reports whether normalizeQuery would return query unchanged.
+func isNormalized(query string) bool {
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			i = skipQuoted(query, i, ch)
+		case ch == '#' || startsDashComment(query, i):
+			i = skipLine(query, i)
+			// The newline ending the comment stays, but not whitespace after it
+			if i+1 < len(query) && isSpace(query[i+1]) {
+				return false
+			}
+		case ch == '/' && i+1 < len(query) && query[i+1] == '*':
+			i = skipBlockComment(query, i)
+		case ch == ' ':
+			// A single space is fine unless it is leading, trailing, or doubled
+			if i == 0 || i == len(query)-1 || isSpace(query[i+1]) {
+				return false
+			}
+		case isSpace(ch):
+			return false
+		}
+	}
+	return true
+}
+
+// isSpace reports whether ch is ASCII whitespace.
+func isSpace(ch byte) bool {
+	switch ch {
+	case ' ', '\t', '\n', '\r', '\f', '\v':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"already_normalized", "SELECT * FROM users WHERE id = ?", "SELECT * FROM users WHERE id = ?"},
+		{"collapse_runs", "SELECT  *\n\tFROM users\r\n WHERE id = ?", "SELECT * FROM users WHERE id = ?"},
+		{"trim", "  SELECT 1 \n", "SELECT 1"},
+		{"single_quoted_literal", "SELECT * FROM t WHERE a = 'x   y'\n", "SELECT * FROM t WHERE a = 'x   y'"},
+		{"double_quoted_literal", "SELECT  \"a\tb\"", "SELECT \"a\tb\""},
+		{"backtick_identifier", "SELECT  `my  col` FROM t", "SELECT `my  col` FROM t"},
+		{"escaped_quote", "SELECT  'it\\'s   fine'  ,  1", "SELECT 'it\\'s   fine' , 1"},
+		{"doubled_quote", "SELECT  'it''s   fine'", "SELECT 'it''s   fine'"},
+		{"dash_comment", "SELECT a -- note\n  FROM t  WHERE id = ?", "SELECT a -- note\nFROM t WHERE id = ?"},
+		{"hash_comment", "SELECT a  # it's\nFROM t WHERE b = ' a  b '", "SELECT a # it's\nFROM t WHERE b = ' a  b '"},
+		{"block_comment", "SELECT /*  it's  */  ' a  b '", "SELECT /*  it's  */ ' a  b '"},
+		{"trailing_comment", "SELECT 1  -- done  ", "SELECT 1 -- done  "},
+		{"double_dash_not_comment", "SELECT  1--1", "SELECT 1--1"},
+		{"empty", "", ""},
+		{"only_space", " \t ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeQuery(tt.query); got != tt.want {
+				t.Fatalf("normalizeQuery(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPreparedStatement_NormalizedSharesStatement(t *testing.T) {
+	stmt := &stubStmt{}
+	db := &stubDB{stmt: stmt}
+	client := &MySQL{
+		DB:               db,
+		prepare:          make(map[string]Stmt),
+		normalizeQueries: true,
+	}
+
+	first, err := client.getPreparedStatement(context.Background(), "SELECT *\n  FROM users WHERE id = ?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := client.getPreparedStatement(context.Background(), "SELECT * FROM users\tWHERE id = ?  ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if first != second {
+		t.Fatalf("expected whitespace variants to share a statement")
+	}
+	if db.prepareCalls != 1 {
+		t.Fatalf("expected a single prepare, got %d", db.prepareCalls)
+	}
+	if _, ok := client.prepare["SELECT * FROM users WHERE id = ?"]; !ok {
+		t.Fatalf("expected statement cached under normalized text")
+	}
+}
+
+func TestGetPreparedStatement_NormalizationDisabled(t *testing.T) {
+	db := &stubDB{stmt: &stubStmt{}}
+	client := &MySQL{
+		DB:      db,
+		prepare: make(map[string]Stmt),
+	}
+
+	_, _ = client.getPreparedStatement(context.Background(), "SELECT  1")
+	_, _ = client.getPreparedStatement(context.Background(), "SELECT 1")
+	if db.prepareCalls != 2 {
+		t.Fatalf("expected distinct statements without normalization, got %d prepares", db.prepareCalls)
+	}
+}
+
+func BenchmarkNormalizeQuery(b *testing.B) {
+	b.Run("normalized", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = normalizeQuery("SELECT * FROM users WHERE id = ? AND name = 'a  b'")
+		}
+	})
+	b.Run("formatted", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = normalizeQuery("SELECT *\n  FROM users\n  WHERE id = ?\n    AND name = 'a  b'")
+		}
+	})
+}
+
+func TestIsNormalized_Comments(t *testing.T) {
+	for _, query := range []string{
+		"SELECT a -- note\nFROM t",
+		"SELECT a # x\nFROM t",
+		"SELECT /*  x  */ 1",
+		"SELECT 1 -- end",
+	} {
+		if !isNormalized(query) || normalizeQuery(query) != query {
+			t.Fatalf("expected %q to be normalized", query)
+		}
+	}
+	if isNormalized("SELECT a -- note\n FROM t") {
+		t.Fatal("expected whitespace after a comment newline to need normalizing")
+	}
+}
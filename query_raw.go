@@ -0,0 +1,196 @@
+package mysql
+
+import "bytes"
+
+// QueryRaw returns the serialized result for params without decoding it, for
+// passthrough callers (proxies, caches of caches) that would otherwise decode
+// and re-encode the value. The bytes are exactly what the codec produced:
+// Params.Codec, or the client codec when unset. hit reports whether they
+// came from the cache.
+//
+// On a miss the query runs, every row is read with ScanAll, and the [][]any
+// rows are serialized and cached in the external cache under the usual key,
+// so the entry is also readable by Query[[][]any]. Without an external cache
+// the bytes are kept in L1 under a separate raw key (see rawL1Key), since L1
+// holds decoded values for Query under the usual one. Cached entries written
+// by a different codec are treated as misses. Params.SessionVars queries run
+// on a pinned connection as with Query and bypass the cache.
+func QueryRaw(c *MySQL, params Params) ([]byte, bool, *MySQLError) {
+	if !c.beginQuery() {
+		return nil, false, ErrClosed
+	}
+	defer c.endQuery()
+
+	params = c.withDefaultDatabase(params.withExpandedSlices())
+
+	// Session state is not part of the cache key, so such queries skip the cache
+	if len(params.SessionVars) > 0 {
+		data, merr := sessionQuery(c, params, func(rows Rows) (*[]byte, *MySQLError) {
+			values, err := ScanAll(rows)
+			if err != nil {
+				return nil, c.mapError(err)
+			}
+			data, err := c.marshalCacheValue(params.Codec, values)
+			if err != nil {
+				return nil, ErrSerialize
+			}
+			return &data, nil
+		})
+		if merr != nil {
+			return nil, false, merr
+		}
+		payload, _ := c.rawPayload(params.Codec, *data)
+		return payload, false, nil
+	}
+
+	var key string
+	if params.CacheDelay > 0 {
+		key = params.Key
+		if key == "" {
+			key = CreateKey(params, c)
+		}
+	}
+
+	if c.cache == nil {
+		return queryRawInternal(c, params, key)
+	}
+	if key == "" || !c.CacheEnabled() {
+		data, merr := c.fetchRaw(params)
+		if merr != nil {
+			return nil, false, merr
+		}
+		payload, _ := c.rawPayload(params.Codec, data)
+		return payload, false, nil
+	}
+
+	fp := c.fingerprint(params)
+	res, unlock, merr := lookupExternal(c, key, params.ForceRefresh, func() (*[]byte, error) {
+		data, err := c.readExternalPayload(params.requestContext(), key, fp)
+		if data == nil || err != nil {
+			return nil, err
+		}
+		payload, ok := c.rawPayload(params.Codec, data)
+		if !ok {
+			return nil, nil
+		}
+		return &payload, nil
+	})
+	if unlock != nil {
+		defer unlock()
+	}
+	if merr != nil {
+		return nil, false, merr
+	}
+	if res != nil {
+		return *res, true, nil
+	}
+
+	data, merr := c.fetchRaw(params)
+	if merr != nil {
+		return nil, false, merr
+	}
+	c.storeExternalData(key, params, fp, data)
+	payload, _ := c.rawPayload(params.Codec, data)
+	return payload, false, nil
+}
+
+// rawL1Key namespaces key for serialized QueryRaw entries in L1, so they do
+// not collide with the decoded values Query keeps under key. It is a suffix
+// so InvalidatePrefix still reaches both.
+func rawL1Key(key string) string {
+	return key + "#raw"
+}
+
+// queryRawInternal is QueryRaw for clients without an external cache; the
+// serialized result is kept in L1 under rawL1Key for CacheDelay.
+func queryRawInternal(c *MySQL, params Params, key string) ([]byte, bool, *MySQLError) {
+	if key != "" {
+		key = rawL1Key(key)
+	}
+	if key != "" && !params.ForceRefresh {
+		if data, ok := getL1[[]byte](c, key); ok {
+			if payload, ok := c.rawPayload(params.Codec, *data); ok {
+				return payload, true, nil
+			}
+		}
+		c.cacheStats.misses.Add(1)
+	}
+
+	data, merr := c.fetchRaw(params)
+	if merr != nil {
+		return nil, false, merr
+	}
+	if key != "" && (c.maxValueBytes <= 0 || len(data) <= c.maxValueBytes) {
+		c.setL1(key, &data, params.CacheDelay)
+	}
+	payload, _ := c.rawPayload(params.Codec, data)
+	return payload, false, nil
+}
+
+// fetchRaw runs params and serializes all rows (see ScanAll) with the
+// query's codec, tagged like any other cache value.
+func (c *MySQL) fetchRaw(params Params) ([]byte, *MySQLError) {
+	if merr := c.validateStatement(params); merr != nil {
+		return nil, merr
+	}
+
+	query := generateQuery(params)
+	prepare, err := c.prepareStatement(params, query)
+	if err != nil {
+		return nil, c.mapError(err)
+	}
+
+	ctx, cancel := createContextWithTimeout(params.requestContext(), params.Timeout)
+	defer cancel()
+
+	release, err := c.acquireQuerySlot(ctx)
+	if err != nil {
+		return nil, c.mapError(err)
+	}
+	defer release()
+
+	rows, err := c.queryStmt(ctx, params, query, prepare)
+	if err != nil {
+		return nil, c.mapError(err)
+	}
+	defer rows.Close()
+
+	limited, limiter := limitRows(rows, params.MaxRows)
+	values, err := ScanAll(limited)
+	if err != nil {
+		return nil, c.mapError(err)
+	}
+	if limiter.Exceeded() {
+		return nil, ErrRowLimit
+	}
+	// Never cache a result cut short by cancellation
+	if ctx.Err() != nil {
+		return nil, c.mapError(ctx.Err())
+	}
+
+	data, err := c.marshalCacheValue(params.Codec, values)
+	if err != nil {
+		return nil, ErrSerialize
+	}
+	return data, nil
+}
+
+// rawPayload strips the codec tag from a cached value, reporting false when
+// the value was written by a codec other than override (or the client codec
+// when override is nil).
+func (c *MySQL) rawPayload(override Codec, data []byte) ([]byte, bool) {
+	want := c.codec
+	if override != nil {
+		want = override
+	}
+
+	if !bytes.HasPrefix(data, codecHeader) {
+		// Untagged values were written by the client codec
+		return data, codecName(want) == codecName(c.codec)
+	}
+	name, payload, ok := readField(data[len(codecHeader):])
+	if !ok || string(name) != codecName(want) {
+		return nil, false
+	}
+	return payload, true
+}
@@ -0,0 +1,154 @@
+package mysql
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func newRawDB(calls *int) *MockDB {
+	db := NewMockDB()
+	db.WithStmt("SELECT id, name FROM users", &MockStmt{Factory: func() Rows {
+		*calls++
+		return &MockRows{cols: []string{"id", "name"}, data: [][]any{{int64(1), "alice"}, {int64(2), "bob"}}}
+	}})
+	return db
+}
+
+func TestQueryRaw_ExternalMissThenHit(t *testing.T) {
+	calls := 0
+	client, cleanup := newExternalClient(newRawDB(&calls), newFakeCache())
+	defer cleanup()
+
+	params := Params{Query: "SELECT id, name FROM users", CacheDelay: time.Minute}
+	data, hit, err := QueryRaw(client, params)
+	if err != nil || hit {
+		t.Fatalf("expected a miss, got hit=%v err=%v", hit, err)
+	}
+
+	want, _ := client.codec.Marshal([][]any{{int64(1), "alice"}, {int64(2), "bob"}})
+	if !bytes.Equal(data, want) {
+		t.Fatalf("expected codec bytes %x, got %x", want, data)
+	}
+
+	again, hit, err := QueryRaw(client, params)
+	if err != nil || !hit {
+		t.Fatalf("expected a hit, got hit=%v err=%v", hit, err)
+	}
+	if !bytes.Equal(again, want) {
+		t.Fatalf("expected cached bytes %x, got %x", want, again)
+	}
+	if calls != 1 {
+		t.Fatalf("expected one database call, got %d", calls)
+	}
+}
+
+func TestQueryRaw_ServesEntryWrittenByQuery(t *testing.T) {
+	calls := 0
+	client, cleanup := newExternalClient(newRawDB(&calls), newFakeCache())
+	defer cleanup()
+
+	params := Params{Query: "SELECT id, name FROM users", CacheDelay: time.Minute, Codec: JSONCodec{}}
+	res, err := Query(client, params, func(rows Rows) (*[][]any, *MySQLError) {
+		all, err := ScanAll(rows)
+		if err != nil {
+			return nil, ErrSerialize
+		}
+		return &all, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, hit, err := QueryRaw(client, params)
+	if err != nil || !hit {
+		t.Fatalf("expected a hit, got hit=%v err=%v", hit, err)
+	}
+	want, _ := JSONCodec{}.Marshal(res)
+	if !bytes.Equal(data, want) {
+		t.Fatalf("expected %s, got %s", want, data)
+	}
+
+	// A different codec must not be handed the JSON payload
+	if _, hit, _ := QueryRaw(client, Params{Query: params.Query, CacheDelay: time.Minute}); hit {
+		t.Fatal("expected a miss for a different codec")
+	}
+}
+
+func TestQueryRaw_Internal(t *testing.T) {
+	calls := 0
+	client, cleanup := newInternalClient(newRawDB(&calls))
+	defer cleanup()
+	client.codec = MsgpackCodec{}
+
+	params := Params{Query: "SELECT id, name FROM users", CacheDelay: time.Minute}
+	first, hit, err := QueryRaw(client, params)
+	if err != nil || hit {
+		t.Fatalf("expected a miss, got hit=%v err=%v", hit, err)
+	}
+	second, hit, err := QueryRaw(client, params)
+	if err != nil || !hit || !bytes.Equal(first, second) {
+		t.Fatalf("expected identical cached bytes, got hit=%v err=%v", hit, err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected one database call, got %d", calls)
+	}
+}
+
+func TestQueryRaw_InternalKeySeparateFromQuery(t *testing.T) {
+	calls := 0
+	client, cleanup := newInternalClient(newRawDB(&calls))
+	defer cleanup()
+	client.codec = MsgpackCodec{}
+
+	params := Params{Query: "SELECT id, name FROM users", CacheDelay: time.Minute}
+	if _, _, err := QueryRaw(client, params); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	res, err := Query(client, params, func(rows Rows) (*[][]any, *MySQLError) {
+		all, err := ScanAll(rows)
+		if err != nil {
+			return nil, ErrSerialize
+		}
+		return &all, nil
+	})
+	if err != nil || res == nil || len(*res) != 2 {
+		t.Fatalf("expected decoded rows from Query, got %v, %v", res, err)
+	}
+	if _, hit, _ := QueryRaw(client, params); !hit {
+		t.Fatal("expected the raw entry to survive Query")
+	}
+	if calls != 2 {
+		t.Fatalf("expected one database call each, got %d", calls)
+	}
+}
+
+func TestQueryRaw_SessionVarsBypassCache(t *testing.T) {
+	db := newSessionDB()
+	db.WithStmt(sessionUserQuery, &MockStmt{Factory: func() Rows {
+		return &MockRows{cols: []string{"names"}, data: [][]any{{"alice,bob"}}}
+	}})
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+	client.codec = MsgpackCodec{}
+
+	params := Params{
+		Query:       sessionUserQuery,
+		CacheDelay:  time.Minute,
+		SessionVars: map[string]string{"sql_mode": "ANSI", "group_concat_max_len": "1000000"},
+	}
+	for i := 0; i < 2; i++ {
+		data, hit, err := QueryRaw(client, params)
+		if err != nil || hit {
+			t.Fatalf("QueryRaw #%d: expected an uncached result, got hit=%v err=%v", i+1, hit, err)
+		}
+		var rows [][]any
+		if err := (MsgpackCodec{}).Unmarshal(data, &rows); err != nil || len(rows) != 1 || rows[0][0] != "alice,bob" {
+			t.Fatalf("QueryRaw #%d: unexpected payload %v (%v)", i+1, rows, err)
+		}
+	}
+	if len(db.Sessions) != 2 || len(client.inMemory.(*InMemoryStorage).Keys()) != 0 {
+		t.Fatalf("expected two uncached session queries, got %d sessions", len(db.Sessions))
+	}
+}
@@ -0,0 +1,155 @@
+package mysql
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// errRowColumns is returned by rowRows.Columns: a Row does not expose them.
+var errRowColumns = errors.New("mysql: columns are not available on a single-row result")
+
+// QueryRow executes a query that is expected to return a single row.
+// The scan callback is invoked once with rows positioned on the first row,
+// so it only needs to call rows.Scan. When the result set is empty, ErrNoRows
+// is returned. When params.Strict is set and more than one row is present,
+// ErrTooManyRows is returned instead of silently ignoring the extra rows.
+//
+// QueryRow is built on top of Query and therefore shares its caching,
+// prepared statement reuse, and error conversion behavior. Empty and
+// ambiguous results are reported as errors and are never cached.
+func QueryRow[T any](
+	c *MySQL,
+	params Params,
+	scan func(rows Rows) (*T, *MySQLError),
+) (*T, *MySQLError) {
+	return Query(c, params, func(rows Rows) (*T, *MySQLError) {
+		if !rows.Next() {
+			return nil, ErrNoRows
+		}
+
+		res, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		// In strict mode a second row means the query is not selective enough.
+		if params.Strict && rows.Next() {
+			return nil, ErrTooManyRows
+		}
+		return res, nil
+	})
+}
+
+// QueryRowPtr is QueryRow for struct results that may be entirely NULL, as
+// in a LEFT JOIN without a match. The row is scanned into a T with
+// ScanStruct; when every column is NULL the returned *T is nil instead of a
+// zero struct. An empty result set is still ErrNoRows.
+//
+//	owner, err := mysql.QueryRowPtr[Owner](db, params)
+//	if err == nil && *owner == nil {
+//	    // no matching owner
+//	}
+func QueryRowPtr[T any](c *MySQL, params Params) (**T, *MySQLError) {
+	return QueryRow(c, params, scanNullableStruct[T])
+}
+
+// QueryScalar is QueryRow for queries returning a single value, such as
+// SELECT COUNT(*) or SELECT EXISTS(...). The only column of the first row is
+// scanned into a T; an empty result set is ErrNoRows. Aggregates that yield
+// NULL on no input (SUM, MAX) need a nullable T such as sql.NullInt64 or
+// *float64. Results are cached like any other Query.
+//
+// The statement runs with Stmt.QueryRowContext, which reads a single row.
+// With params.Strict it runs as a regular query instead, so that a second
+// row can be detected.
+//
+//	n, err := mysql.QueryScalar[int64](db, mysql.Params{
+//	    Query: "SELECT COUNT(*) FROM users WHERE tenant = ?",
+//	    Args:  []any{tenant},
+//	})
+func QueryScalar[T any](c *MySQL, params Params) (*T, *MySQLError) {
+	params.singleRow = !params.Strict
+	return QueryRow(c, params, func(rows Rows) (*T, *MySQLError) {
+		out := new(T)
+		if err := rows.Scan(out); err != nil {
+			if !params.singleRow {
+				return nil, NewError(err)
+			}
+			// Query errors surface from Row.Scan
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrNoRows
+			}
+			return nil, c.mapError(err)
+		}
+		return out, nil
+	})
+}
+
+// rowRows presents a Row as Rows holding one row, so the Query pipeline can
+// run on Stmt.QueryRowContext. Next reports a row once; whether there is
+// one, and any query error, is only known from Scan.
+type rowRows struct {
+	row  Row
+	done bool
+}
+
+// Next implements Rows.
+func (r *rowRows) Next() bool {
+	if r.done {
+		return false
+	}
+	r.done = true
+	return true
+}
+
+// Scan implements Rows.
+func (r *rowRows) Scan(dest ...any) error { return r.row.Scan(dest...) }
+
+// Columns implements Rows; a Row does not report its columns.
+func (r *rowRows) Columns() ([]string, error) { return nil, errRowColumns }
+
+// NextResultSet implements Rows.
+func (r *rowRows) NextResultSet() bool { return false }
+
+// Close implements Rows. The Row releases its result in Scan.
+func (r *rowRows) Close() error { return nil }
+
+// scanNullableStruct is the QueryRow callback used by QueryRowPtr.
+func scanNullableStruct[T any](rows Rows) (**T, *MySQLError) {
+	null, err := rowIsNull(rows)
+	if err != nil {
+		return nil, NewError(err)
+	}
+
+	var out *T
+	if !null {
+		out = new(T)
+		if err := ScanStruct(rows, out); err != nil {
+			return nil, NewError(err)
+		}
+	}
+	return &out, nil
+}
+
+// rowIsNull reports whether every column of the current row is NULL. It
+// scans the row into untyped values, leaving it available for another Scan.
+func rowIsNull(rows Rows) (bool, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return false, err
+	}
+	values := make([]any, len(cols))
+	dest := make([]any, len(cols))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return false, err
+	}
+	for _, v := range values {
+		if v != nil {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,307 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	driver "github.com/go-sql-driver/mysql"
+)
+
+func scanUser(rows Rows) (*User, *MySQLError) {
+	var u User
+	if err := rows.Scan(&u.ID, &u.Name); err != nil {
+		return nil, NewError(err)
+	}
+	return &u, nil
+}
+
+func TestQueryRow_NoRows(t *testing.T) {
+	client := &MySQL{
+		DB:      newMockDBWithRows([][]any{}),
+		prepare: make(map[string]Stmt),
+	}
+
+	res, err := QueryRow(client, Params{Query: "SELECT * FROM table"}, scanUser)
+	if res != nil {
+		t.Fatalf("expected nil result, got %+v", res)
+	}
+	if !errors.Is(err, ErrNoRows) {
+		t.Fatalf("expected ErrNoRows, got %+v", err)
+	}
+}
+
+func TestQueryRow_SingleRow(t *testing.T) {
+	client := &MySQL{
+		DB:      newMockDBWithRows([][]any{{1, "Alice"}}),
+		prepare: make(map[string]Stmt),
+	}
+
+	res, err := QueryRow(client, Params{Query: "SELECT * FROM table", Strict: true}, scanUser)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if res.ID != 1 || res.Name != "Alice" {
+		t.Fatalf("unexpected row: %+v", res)
+	}
+}
+
+func TestQueryRow_MultipleRows(t *testing.T) {
+	client := &MySQL{
+		DB:      newMockDBWithRows([][]any{{1, "Alice"}, {2, "Bob"}}),
+		prepare: make(map[string]Stmt),
+	}
+
+	// Without strict mode the first row wins.
+	res, err := QueryRow(client, Params{Query: "SELECT * FROM table"}, scanUser)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if res.ID != 1 {
+		t.Fatalf("expected first row, got %+v", res)
+	}
+
+	// Strict mode rejects ambiguous results.
+	res, err = QueryRow(client, Params{Query: "SELECT * FROM table", Strict: true}, scanUser)
+	if res != nil {
+		t.Fatalf("expected nil result, got %+v", res)
+	}
+	if !errors.Is(err, ErrTooManyRows) {
+		t.Fatalf("expected ErrTooManyRows, got %+v", err)
+	}
+}
+
+func TestQueryRow_CacheHit(t *testing.T) {
+	client, cleanup := newInternalClient(newMockDBWithRows([][]any{{1, "Alice"}}))
+	defer cleanup()
+	client.cacheEnabled.Store(true)
+
+	params := Params{Query: "SELECT * FROM table", CacheDelay: time.Minute}
+	if _, err := QueryRow(client, params, scanUser); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	res, err := QueryRow(client, params, func(rows Rows) (*User, *MySQLError) {
+		t.Fatal("scan should not be invoked on cache hit")
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if res.Name != "Alice" {
+		t.Fatalf("unexpected cached row: %+v", res)
+	}
+}
+
+func TestQueryRow_StrictDoesNotReuseNonStrictEntry(t *testing.T) {
+	client, cleanup := newInternalClient(newMockDBWithRows([][]any{{1, "Alice"}, {2, "Bob"}}))
+	defer cleanup()
+	client.cacheEnabled.Store(true)
+
+	params := Params{Query: "SELECT * FROM table", CacheDelay: time.Minute}
+	if _, err := QueryRow(client, params, scanUser); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	params.Strict = true
+	if _, err := QueryRow(client, params, scanUser); !errors.Is(err, ErrTooManyRows) {
+		t.Fatalf("expected ErrTooManyRows, got %+v", err)
+	}
+}
+
+func TestQueryRow_EmptyResultNotCached(t *testing.T) {
+	db := newMockDBWithRows([][]any{})
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+	client.cacheEnabled.Store(true)
+
+	params := Params{Query: "SELECT * FROM table", CacheDelay: time.Minute}
+	for i := 0; i < 2; i++ {
+		if _, err := QueryRow(client, params, scanUser); !errors.Is(err, ErrNoRows) {
+			t.Fatalf("expected ErrNoRows, got %+v", err)
+		}
+	}
+	if _, err := client.inMemory.Get(CreateKey(params, client)); err != ErrNotFound {
+		t.Fatalf("expected empty result not to be cached, got %v", err)
+	}
+}
+
+func newMockDBWithColumns(cols []string, data [][]any) *MockDB {
+	db := NewMockDB()
+	db.WithStmt("SELECT * FROM table", &MockStmt{Factory: func() Rows {
+		return &MockRows{cols: cols, data: data}
+	}})
+	return db
+}
+
+func TestQueryRowPtr_AllNullReturnsNil(t *testing.T) {
+	client := &MySQL{
+		DB:      newMockDBWithColumns([]string{"id", "name"}, [][]any{{nil, nil}}),
+		prepare: make(map[string]Stmt),
+	}
+
+	res, err := QueryRowPtr[User](client, Params{Query: "SELECT * FROM table"})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if res == nil || *res != nil {
+		t.Fatalf("expected a nil *User for an all-NULL row, got %+v", res)
+	}
+}
+
+func TestQueryRowPtr_PopulatedRow(t *testing.T) {
+	client := &MySQL{
+		DB:      newMockDBWithColumns([]string{"id", "name"}, [][]any{{1, "Alice"}}),
+		prepare: make(map[string]Stmt),
+	}
+
+	res, err := QueryRowPtr[User](client, Params{Query: "SELECT * FROM table"})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if res == nil || *res == nil || (*res).ID != 1 || (*res).Name != "Alice" {
+		t.Fatalf("unexpected row: %+v", res)
+	}
+}
+
+func TestQueryRowPtr_NoRows(t *testing.T) {
+	client := &MySQL{
+		DB:      newMockDBWithColumns([]string{"id", "name"}, [][]any{}),
+		prepare: make(map[string]Stmt),
+	}
+
+	if _, err := QueryRowPtr[User](client, Params{Query: "SELECT * FROM table"}); !errors.Is(err, ErrNoRows) {
+		t.Fatalf("expected ErrNoRows, got %+v", err)
+	}
+}
+
+func TestQueryScalar_Count(t *testing.T) {
+	queries := 0
+	db := NewMockDB()
+	db.WithStmt("SELECT COUNT(*) FROM users", &MockStmt{Factory: func() Rows {
+		queries++
+		return &MockRows{cols: []string{"COUNT(*)"}, data: [][]any{{int64(42)}}}
+	}})
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	// The count is cached like any other result
+	params := Params{Query: "SELECT COUNT(*) FROM users", CacheDelay: time.Minute}
+	for i := 0; i < 2; i++ {
+		n, err := QueryScalar[int64](client, params)
+		if err != nil || n == nil || *n != 42 {
+			t.Fatalf("expected 42, got %v (%+v)", n, err)
+		}
+	}
+	if queries != 1 {
+		t.Fatalf("expected the second count to be served from cache, got %d queries", queries)
+	}
+}
+
+func TestQueryScalar_NullableAndString(t *testing.T) {
+	client := &MySQL{
+		DB:      newMockDBWithColumns([]string{"SUM(x)"}, [][]any{{nil}}),
+		prepare: make(map[string]Stmt),
+	}
+	sum, err := QueryScalar[*int64](client, Params{Query: "SELECT * FROM table"})
+	if err != nil || sum == nil || *sum != nil {
+		t.Fatalf("expected a nil sum for NULL, got %v (%+v)", sum, err)
+	}
+
+	client = &MySQL{
+		DB:      newMockDBWithColumns([]string{"name"}, [][]any{{"Alice"}}),
+		prepare: make(map[string]Stmt),
+	}
+	name, err := QueryScalar[string](client, Params{Query: "SELECT * FROM table"})
+	if err != nil || *name != "Alice" {
+		t.Fatalf("expected Alice, got %v (%+v)", name, err)
+	}
+}
+
+func TestQueryScalar_NoRows(t *testing.T) {
+	client := &MySQL{
+		DB:      newMockDBWithColumns([]string{"COUNT(*)"}, [][]any{}),
+		prepare: make(map[string]Stmt),
+	}
+
+	res, err := QueryScalar[int64](client, Params{Query: "SELECT * FROM table"})
+	if res != nil || !errors.Is(err, ErrNoRows) {
+		t.Fatalf("expected ErrNoRows, got %v (%+v)", res, err)
+	}
+}
+
+// rowOnlyStmt fails QueryContext, proving a caller used QueryRowContext.
+type rowOnlyStmt struct {
+	*MockStmt
+	rowQueries int
+}
+
+func (s *rowOnlyStmt) QueryContext(ctx context.Context, args ...any) (Rows, error) {
+	return nil, errors.New("QueryContext called")
+}
+
+func (s *rowOnlyStmt) QueryRowContext(ctx context.Context, args ...any) Row {
+	s.rowQueries++
+	return s.MockStmt.QueryRowContext(ctx, args...)
+}
+
+func TestQueryScalar_UsesQueryRowContext(t *testing.T) {
+	stmt := &rowOnlyStmt{MockStmt: &MockStmt{Factory: func() Rows {
+		return &MockRows{data: [][]any{{int64(7)}, {int64(8)}}}
+	}}}
+	client := &MySQL{DB: NewMockDB(), prepare: map[string]Stmt{"SELECT n": stmt}}
+
+	n, err := QueryScalar[int64](client, Params{Query: "SELECT n"})
+	if err != nil || *n != 7 || stmt.rowQueries != 1 {
+		t.Fatalf("expected 7 from QueryRowContext, got %v (%+v) after %d row queries", n, err, stmt.rowQueries)
+	}
+
+	// Strict must see the second row, so it runs as a regular query
+	if _, err := QueryScalar[int64](client, Params{Query: "SELECT n", Strict: true}); err == nil || stmt.rowQueries != 1 {
+		t.Fatalf("expected Strict to use QueryContext, got %v after %d row queries", err, stmt.rowQueries)
+	}
+}
+
+func TestQueryScalar_RowErrorsAreMapped(t *testing.T) {
+	deadlock := &driver.MySQLError{Number: 1213, Message: "Deadlock found"}
+	client := &MySQL{
+		DB:      NewMockDB(),
+		prepare: map[string]Stmt{"SELECT n": &MockStmt{Err: deadlock}},
+	}
+	want := client.mapError(deadlock)
+	if _, err := QueryScalar[int64](client, Params{Query: "SELECT n"}); err == nil || err.Message != want.Message || !errors.Is(err, deadlock) {
+		t.Fatalf("expected %+v, got %+v", want, err)
+	}
+}
+
+func TestQuery_SQLNoRowsMapsToErrNoRows(t *testing.T) {
+	db := NewMockDB()
+	db.WithStmt("SELECT name FROM users WHERE id = ?", &MockStmt{Factory: func() Rows { return &MockRows{} }})
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	// A callback that reads a missing row the database/sql way
+	scanOne := func(rows Rows) (*string, *MySQLError) {
+		var name string
+		if err := rowFromRows(rows, nil).Scan(&name); err != nil {
+			return nil, NewError(err)
+		}
+		return &name, nil
+	}
+	params := Params{Query: "SELECT name FROM users WHERE id = ?", Args: []any{1}, CacheDelay: time.Minute}
+	res, err := Query(client, params, scanOne)
+	if res != nil || !errors.Is(err, ErrNoRows) || !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected ErrNoRows wrapping sql.ErrNoRows, got %v (%+v)", res, err)
+	}
+	if len(client.inMemory.(*InMemoryStorage).Keys()) != 0 {
+		t.Fatal("an empty result must not be cached")
+	}
+
+	// A statement missing from the mock is an error, not an empty result
+	_, err = Query(client, Params{Query: "SELECT unregistered"}, scanOne)
+	if err == nil || errors.Is(err, ErrNoRows) {
+		t.Fatalf("expected a missing mock statement to be reported as such, got %+v", err)
+	}
+}
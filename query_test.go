@@ -3,6 +3,7 @@ package mysql
 import (
 	"context"
 	"errors"
+	"io"
 	"testing"
 	"time"
 )
@@ -228,12 +229,12 @@ func TestQuery_CacheHit(t *testing.T) {
 	defer inMemory.Stop()
 
 	mysql := &MySQL{
-		DB:           mockDB,
-		prepare:      make(map[string]Stmt),
-		inMemory:     inMemory,
-		cache:        nil,
-		CacheEnabled: true,
+		DB:       mockDB,
+		prepare:  make(map[string]Stmt),
+		inMemory: inMemory,
+		cache:    nil,
 	}
+	mysql.cacheEnabled.Store(true)
 
 	type User struct {
 		ID   int
@@ -447,3 +448,73 @@ func BenchmarkQuery_WithDelay(b *testing.B) {
 		})
 	}
 }
+
+// TestQuery_MultipleResultSets verifies that callbacks can walk every result
+// set returned by a stored procedure via NextResultSet.
+func TestQuery_MultipleResultSets(t *testing.T) {
+	stmt := &MockStmt{Factory: func() Rows {
+		return &MockRows{
+			data: [][]any{{1, "Alice"}, {2, "Bob"}},
+			sets: [][][]any{{{2}}},
+		}
+	}}
+	mockDB := NewMockDB()
+	mockDB.WithStmt("CALL app.users_with_total(?)", stmt)
+
+	mysql := &MySQL{
+		DB:      mockDB,
+		prepare: make(map[string]Stmt),
+	}
+
+	type page struct {
+		Users []User
+		Total int
+	}
+
+	res, err := Query(mysql, Params{
+		Database: "app",
+		Exec:     "users_with_total",
+		Args:     []any{10},
+	}, func(rows Rows) (*page, *MySQLError) {
+		var p page
+		for rows.Next() {
+			var u User
+			_ = rows.Scan(&u.ID, &u.Name)
+			p.Users = append(p.Users, u)
+		}
+		if !rows.NextResultSet() {
+			return nil, &MySQLError{Number: 45000, Message: "missing total"}
+		}
+		for rows.Next() {
+			_ = rows.Scan(&p.Total)
+		}
+		return &p, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(res.Users) != 2 || res.Total != 2 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
+
+func TestQuery_EmptyParams(t *testing.T) {
+	db := NewMockDB()
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	_, err := Query(client, Params{}, func(rows Rows) (*[]int, *MySQLError) {
+		t.Fatal("callback should not be invoked for empty params")
+		return nil, nil
+	})
+	if !errors.Is(err, ErrEmptyQuery) {
+		t.Fatalf("expected ErrEmptyQuery, got %+v", err)
+	}
+	if db.Prepares != 0 {
+		t.Fatalf("expected nothing to be prepared, got %d prepares", db.Prepares)
+	}
+
+	if err := StreamJSON(client, Params{Args: []any{1}}, io.Discard); !errors.Is(err, ErrEmptyQuery) {
+		t.Fatalf("expected ErrEmptyQuery from StreamJSON, got %+v", err)
+	}
+}
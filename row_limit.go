@@ -0,0 +1,63 @@
+package mysql
+
+import "database/sql"
+
+// limitedRows wraps the Rows handed to a callback and stops iteration once
+// more than max rows were produced, across all result sets.
+type limitedRows struct {
+	Rows
+	max      int
+	n        int
+	exceeded bool
+}
+
+// typedLimitedRows is a limitedRows over TypedRows, so helpers such as
+// ScanAll still see column type metadata.
+type typedLimitedRows struct {
+	*limitedRows
+	typed TypedRows
+}
+
+// ColumnTypes implements TypedRows.
+func (r typedLimitedRows) ColumnTypes() ([]*sql.ColumnType, error) {
+	return r.typed.ColumnTypes()
+}
+
+// limitRows wraps rows to enforce Params.MaxRows. It returns rows unchanged
+// and a nil limiter when limit is not positive.
+func limitRows(rows Rows, limit int) (Rows, *limitedRows) {
+	if limit <= 0 {
+		return rows, nil
+	}
+	lr := &limitedRows{Rows: rows, max: limit}
+	if typed, ok := rows.(TypedRows); ok {
+		return typedLimitedRows{limitedRows: lr, typed: typed}, lr
+	}
+	return lr, lr
+}
+
+// Next advances like Rows.Next but reports false, and records the overflow,
+// when a row beyond the limit is available.
+func (r *limitedRows) Next() bool {
+	if r.exceeded || !r.Rows.Next() {
+		return false
+	}
+	if r.n == r.max {
+		r.exceeded = true
+		return false
+	}
+	r.n++
+	return true
+}
+
+// NextResultSet advances like Rows.NextResultSet; rows keep counting
+// towards the same limit.
+func (r *limitedRows) NextResultSet() bool {
+	return !r.exceeded && r.Rows.NextResultSet()
+}
+
+// Exceeded reports whether iteration was cut off at the limit. A nil
+// limiter is never exceeded.
+func (r *limitedRows) Exceeded() bool {
+	return r != nil && r.exceeded
+}
@@ -0,0 +1,101 @@
+package mysql
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func rowsOf(n int) *MockRows {
+	data := make([][]any, n)
+	for i := range data {
+		data[i] = []any{"row"}
+	}
+	return &MockRows{cols: []string{"v"}, data: data}
+}
+
+func TestLimitRows_HaltsAtLimit(t *testing.T) {
+	rows, limiter := limitRows(rowsOf(5), 3)
+
+	n := 0
+	for rows.Next() {
+		n++
+	}
+	if n != 3 || !limiter.Exceeded() {
+		t.Fatalf("expected 3 rows and an exceeded limit, got %d rows, exceeded=%v", n, limiter.Exceeded())
+	}
+	if rows.Next() || rows.NextResultSet() {
+		t.Fatal("expected iteration to stay stopped")
+	}
+
+	// Exactly at the limit is fine
+	rows, limiter = limitRows(rowsOf(3), 3)
+	for rows.Next() {
+	}
+	if limiter.Exceeded() {
+		t.Fatal("expected a result of exactly MaxRows to pass")
+	}
+
+	// No limit leaves rows untouched
+	plain := rowsOf(1)
+	if rows, limiter := limitRows(plain, 0); rows != Rows(plain) || limiter.Exceeded() {
+		t.Fatal("expected MaxRows 0 to disable the wrapper")
+	}
+}
+
+// typedMockRows is a MockRows exposing (empty) column type metadata.
+type typedMockRows struct{ *MockRows }
+
+func (typedMockRows) ColumnTypes() ([]*sql.ColumnType, error) { return nil, nil }
+
+func TestLimitRows_KeepsColumnTypes(t *testing.T) {
+	rows, _ := limitRows(typedMockRows{rowsOf(1)}, 1)
+	if _, ok := rows.(TypedRows); !ok {
+		t.Fatal("expected the wrapper to keep TypedRows")
+	}
+	rows, _ = limitRows(rowsOf(1), 1)
+	if _, ok := rows.(TypedRows); ok {
+		t.Fatal("expected plain Rows not to gain TypedRows")
+	}
+}
+
+func TestQuery_MaxRows(t *testing.T) {
+	db := NewMockDB()
+	db.WithStmt("SELECT v", &MockStmt{Factory: func() Rows { return rowsOf(10) }})
+
+	for _, external := range []bool{false, true} {
+		var client *MySQL
+		var cleanup func()
+		if external {
+			client, cleanup = newExternalClient(db, newFakeCache())
+		} else {
+			client, cleanup = newInternalClient(db)
+			client.codec = MsgpackCodec{}
+		}
+
+		scanned := 0
+		params := Params{Query: "SELECT v", CacheDelay: time.Minute, MaxRows: 4}
+		res, err := Query(client, params, func(rows Rows) (*[]string, *MySQLError) {
+			res, merr := scanStrings(rows)
+			scanned = len(*res)
+			return res, merr
+		})
+		if res != nil || !errors.Is(err, ErrRowLimit) || scanned != 4 {
+			t.Fatalf("external=%v: expected ErrRowLimit after 4 rows, got %v, %v, %d scanned", external, res, err, scanned)
+		}
+		if _, ok := getL1[[]string](client, CreateKey(params, client)); ok {
+			t.Fatalf("external=%v: expected the truncated result not to be cached", external)
+		}
+
+		params.MaxRows = 10
+		if res, err := Query(client, params, scanStrings); err != nil || len(*res) != 10 {
+			t.Fatalf("external=%v: expected the full result within the limit, got %v", external, err)
+		}
+
+		if _, _, err := QueryRaw(client, Params{Query: "SELECT v", MaxRows: 2}); !errors.Is(err, ErrRowLimit) {
+			t.Fatalf("external=%v: expected QueryRaw to enforce MaxRows, got %v", external, err)
+		}
+		cleanup()
+	}
+}
@@ -0,0 +1,37 @@
+package mysql
+
+// ScanAll reads every remaining row of the current result set into a slice of
+// values, one []any per row sized by rows.Columns(). Values are whatever the
+// driver produces for untyped destinations: with database/sql, text columns
+// arrive as []byte (copied, so they remain valid), numbers as int64/float64,
+// and NULL as nil. When rows implements TypedRows, []byte values of textual
+// columns (CHAR, TEXT, DECIMAL, DATE, ...) are returned as strings, while
+// binary columns keep []byte. The caller still owns rows and must close it.
+//
+// ScanAll is intended for generic tooling such as exporters and admin
+// endpoints; prefer ScanStruct or typed Scan calls in application code.
+func ScanAll(rows Rows) ([][]any, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	typeNames := columnTypeNames(rows)
+
+	dest := make([]any, len(cols))
+	var out [][]any
+	for rows.Next() {
+		row := make([]any, len(cols))
+		for i := range row {
+			dest[i] = &row[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return out, err
+		}
+		if typeNames != nil {
+			coerceRow(row, typeNames)
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
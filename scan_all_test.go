@@ -0,0 +1,67 @@
+package mysql
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScanAll_Mock(t *testing.T) {
+	ts := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	rows := &MockRows{
+		cols: []string{"id", "name", "score", "raw", "at", "missing"},
+		data: [][]any{
+			{int64(1), "alice", 9.5, []byte("x"), ts, nil},
+			{int64(2), "bob", 7.25, []byte{}, ts.Add(time.Hour), nil},
+		},
+	}
+
+	got, err := ScanAll(rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := [][]any{
+		{int64(1), "alice", 9.5, []byte("x"), ts, nil},
+		{int64(2), "bob", 7.25, []byte{}, ts.Add(time.Hour), nil},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ScanAll() = %v, want %v", got, want)
+	}
+}
+
+func TestScanAll_Empty(t *testing.T) {
+	got, err := ScanAll(&MockRows{cols: []string{"id"}})
+	if err != nil || len(got) != 0 {
+		t.Fatalf("expected no rows, got %v (%v)", got, err)
+	}
+}
+
+func TestScanAll_SQLRows(t *testing.T) {
+	db := &sqlDB{db: newTestSQLDB(nil)}
+	defer db.Close()
+
+	stmt, err := db.PrepareContext(context.Background(), "SELECT value")
+	if err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+	defer stmt.Close()
+	rows, err := stmt.QueryContext(context.Background())
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	defer rows.Close()
+
+	got, err := ScanAll(rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || len(got[0]) != 1 {
+		t.Fatalf("expected one single-column row, got %v", got)
+	}
+	if v, ok := got[0][0].(string); !ok || v != "ok" {
+		if b, ok := got[0][0].([]byte); !ok || string(b) != "ok" {
+			t.Fatalf("unexpected value %#v", got[0][0])
+		}
+	}
+}
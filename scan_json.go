@@ -0,0 +1,79 @@
+package mysql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var (
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+	timeType       = reflect.TypeOf(time.Time{})
+	scannerType    = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+// jsonColumn is a sql.Scanner that unmarshals a JSON column value into dest.
+// NULL is treated like the JSON literal null.
+type jsonColumn struct {
+	dest any
+}
+
+// Scan implements sql.Scanner.
+func (j jsonColumn) Scan(src any) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		data = []byte("null")
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("mysql: cannot decode JSON from %T", src)
+	}
+	return json.Unmarshal(data, j.dest)
+}
+
+// ScanJSON scans the current row of rows and unmarshals the JSON value in
+// column colIndex into dest, discarding the other columns. A NULL value is
+// decoded like the JSON literal null.
+func ScanJSON(rows Rows, colIndex int, dest any) error {
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if colIndex < 0 || colIndex >= len(cols) {
+		return fmt.Errorf("mysql: ScanJSON column index %d out of range [0,%d)", colIndex, len(cols))
+	}
+
+	targets := make([]any, len(cols))
+	for i := range targets {
+		targets[i] = new(any)
+	}
+	targets[colIndex] = jsonColumn{dest: dest}
+	return rows.Scan(targets...)
+}
+
+// jsonDecodable reports whether a struct field of type t should be filled by
+// unmarshaling JSON rather than by a plain scan: json.RawMessage, maps,
+// non-byte slices and structs that are neither time.Time nor sql.Scanners.
+func jsonDecodable(t reflect.Type) bool {
+	if t == rawMessageType {
+		return true
+	}
+	if reflect.PointerTo(t).Implements(scannerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Map:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Uint8
+	case reflect.Struct:
+		return t != timeType
+	default:
+		return false
+	}
+}
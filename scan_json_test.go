@@ -0,0 +1,107 @@
+package mysql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type jsonPrefs struct {
+	Theme string   `json:"theme"`
+	Tags  []string `json:"tags"`
+}
+
+type jsonProfile struct {
+	ID    int             `db:"id"`
+	Prefs jsonPrefs       `db:"prefs"`
+	Meta  map[string]any  `db:"meta"`
+	Raw   json.RawMessage `db:"raw"`
+}
+
+func TestScanJSON(t *testing.T) {
+	rows := &MockRows{
+		cols: []string{"id", "prefs"},
+		data: [][]any{{1, []byte(`{"theme":"dark","tags":["a","b"]}`)}},
+	}
+	rows.Next()
+
+	var prefs jsonPrefs
+	if err := ScanJSON(rows, 1, &prefs); err != nil {
+		t.Fatalf("ScanJSON: %v", err)
+	}
+	want := jsonPrefs{Theme: "dark", Tags: []string{"a", "b"}}
+	if !reflect.DeepEqual(prefs, want) {
+		t.Fatalf("got %+v, want %+v", prefs, want)
+	}
+
+	if err := ScanJSON(rows, 2, &prefs); err == nil {
+		t.Fatal("expected error for out-of-range column")
+	}
+}
+
+func TestScanJSON_InvalidDocument(t *testing.T) {
+	rows := &MockRows{cols: []string{"prefs"}, data: [][]any{{[]byte(`{`)}}}
+	rows.Next()
+
+	var prefs jsonPrefs
+	if err := ScanJSON(rows, 0, &prefs); err == nil {
+		t.Fatal("expected error for malformed JSON")
+	}
+}
+
+func TestScanStruct_JSONColumns(t *testing.T) {
+	rows := &MockRows{
+		cols: []string{"id", "prefs", "meta", "raw"},
+		data: [][]any{{
+			7,
+			[]byte(`{"theme":"light","tags":["x"]}`),
+			[]byte(`{"score":1}`),
+			[]byte(`[1,2]`),
+		}},
+	}
+	rows.Next()
+
+	var p jsonProfile
+	if err := ScanStruct(rows, &p); err != nil {
+		t.Fatalf("ScanStruct: %v", err)
+	}
+	if p.ID != 7 || p.Prefs.Theme != "light" || !reflect.DeepEqual(p.Prefs.Tags, []string{"x"}) {
+		t.Fatalf("unexpected profile: %+v", p)
+	}
+	if p.Meta["score"] != float64(1) {
+		t.Fatalf("unexpected meta: %#v", p.Meta)
+	}
+	if string(p.Raw) != "[1,2]" {
+		t.Fatalf("unexpected raw message: %s", p.Raw)
+	}
+}
+
+func TestScanStruct_JSONColumnType(t *testing.T) {
+	db := sql.OpenDB(&typedConnector{
+		cols:  []string{"id", "prefs", "meta", "raw"},
+		types: []string{"INT", "JSON", "JSON", "JSON"},
+		rows: [][]driver.Value{
+			{int64(3), []byte(`{"theme":"dark"}`), nil, []byte(`"s"`)},
+		},
+	})
+	defer db.Close()
+
+	rows, err := db.Query("SELECT 1")
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		t.Fatal("expected a row")
+	}
+	var p jsonProfile
+	if err := ScanStruct(rows, &p); err != nil {
+		t.Fatalf("ScanStruct: %v", err)
+	}
+	if p.ID != 3 || p.Prefs.Theme != "dark" || p.Meta != nil || string(p.Raw) != `"s"` {
+		t.Fatalf("unexpected profile: %+v", p)
+	}
+}
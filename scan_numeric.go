@@ -0,0 +1,154 @@
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// numericColumn is a sql.Scanner that stores integer, unsigned, float and
+// numeric string values into the numeric destination dest.
+type numericColumn struct {
+	dest any
+}
+
+// ScanNumeric wraps dest, a pointer to an integer, unsigned integer or float
+// kind, in a sql.Scanner that also parses string and []byte values. MySQL
+// reports DECIMAL and large BIGINT UNSIGNED values as strings, which would
+// otherwise fail to scan into such destinations. Overflowing values and NULL
+// are reported as errors.
+//
+//	var price float64
+//	err := rows.Scan(mysql.ScanNumeric(&price))
+func ScanNumeric(dest any) sql.Scanner {
+	return numericColumn{dest: dest}
+}
+
+// Scan implements sql.Scanner.
+func (n numericColumn) Scan(src any) error {
+	ptr := reflect.ValueOf(n.dest)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() {
+		return fmt.Errorf("mysql: ScanNumeric destination must be a non-nil pointer, got %T", n.dest)
+	}
+	if src == nil {
+		return fmt.Errorf("cannot scan NULL into %s", ptr.Elem().Type())
+	}
+	if !isNumericKind(ptr.Elem().Kind()) {
+		return fmt.Errorf("mysql: ScanNumeric destination must point to a numeric kind, got %T", n.dest)
+	}
+	return setNumeric(ptr.Elem(), src)
+}
+
+// isNumericKind reports whether k is an integer, unsigned integer or float kind.
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// setNumeric stores v into the numeric value elem. Integer and unsigned
+// sources are converted with overflow checks, and string or []byte sources
+// are parsed as the destination kind. Float sources are stored into integer
+// kinds only when integral (a DOUBLE holding 3.0), as database/sql does.
+func setNumeric(elem reflect.Value, v any) error {
+	src := reflect.ValueOf(v)
+	str, isStr := "", false
+	switch {
+	case src.Kind() == reflect.String:
+		str, isStr = src.String(), true
+	case src.Kind() == reflect.Slice && src.Type().Elem().Kind() == reflect.Uint8:
+		str, isStr = string(src.Bytes()), true
+	}
+
+	switch elem.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch {
+		case isStr:
+			parsed, err := strconv.ParseInt(str, 10, 64)
+			if err != nil {
+				return fmt.Errorf("cannot parse %q as %s: %w", str, elem.Type(), err)
+			}
+			n = parsed
+		case src.CanInt():
+			n = src.Int()
+		case src.CanUint():
+			if src.Uint() > math.MaxInt64 {
+				return fmt.Errorf("value %v overflows %s", v, elem.Type())
+			}
+			n = int64(src.Uint())
+		case src.CanFloat():
+			f := src.Float()
+			if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+				return fmt.Errorf("cannot assign non-integral or out-of-range %v to %s", v, elem.Type())
+			}
+			n = int64(f)
+		default:
+			return fmt.Errorf("cannot assign %T to %s", v, elem.Type())
+		}
+		if elem.OverflowInt(n) {
+			return fmt.Errorf("value %v overflows %s", v, elem.Type())
+		}
+		elem.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		switch {
+		case isStr:
+			parsed, err := strconv.ParseUint(str, 10, 64)
+			if err != nil {
+				return fmt.Errorf("cannot parse %q as %s: %w", str, elem.Type(), err)
+			}
+			n = parsed
+		case src.CanUint():
+			n = src.Uint()
+		case src.CanInt():
+			if src.Int() < 0 {
+				return fmt.Errorf("value %v overflows %s", v, elem.Type())
+			}
+			n = uint64(src.Int())
+		case src.CanFloat():
+			f := src.Float()
+			if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+				return fmt.Errorf("cannot assign non-integral or out-of-range %v to %s", v, elem.Type())
+			}
+			n = uint64(f)
+		default:
+			return fmt.Errorf("cannot assign %T to %s", v, elem.Type())
+		}
+		if elem.OverflowUint(n) {
+			return fmt.Errorf("value %v overflows %s", v, elem.Type())
+		}
+		elem.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch {
+		case isStr:
+			parsed, err := strconv.ParseFloat(str, elem.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("cannot parse %q as %s: %w", str, elem.Type(), err)
+			}
+			f = parsed
+		case src.CanFloat():
+			f = src.Float()
+		case src.CanInt():
+			f = float64(src.Int())
+		case src.CanUint():
+			f = float64(src.Uint())
+		default:
+			return fmt.Errorf("cannot assign %T to %s", v, elem.Type())
+		}
+		if elem.OverflowFloat(f) {
+			return fmt.Errorf("value %v overflows %s", v, elem.Type())
+		}
+		elem.SetFloat(f)
+	default:
+		return fmt.Errorf("cannot assign %T to %s", v, elem.Type())
+	}
+	return nil
+}
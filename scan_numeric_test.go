@@ -0,0 +1,100 @@
+package mysql
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMockRows_ScanNumericStrings(t *testing.T) {
+	rows := &MockRows{data: [][]any{{"123.45", "99999999999999", []byte("-7")}}}
+	rows.Next()
+
+	var price float64
+	var total uint64
+	var delta int
+	if err := rows.Scan(&price, &total, &delta); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if price != 123.45 || total != 99999999999999 || delta != -7 {
+		t.Fatalf("unexpected values: %v %v %v", price, total, delta)
+	}
+}
+
+func TestScanStruct_NumericStrings(t *testing.T) {
+	type account struct {
+		Balance float64 `db:"balance"`
+		Total   uint64  `db:"total"`
+	}
+	rows := &MockRows{
+		cols: []string{"balance", "total"},
+		data: [][]any{{"123.45", "99999999999999"}},
+	}
+	rows.Next()
+
+	var a account
+	if err := ScanStruct(rows, &a); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a.Balance != 123.45 || a.Total != 99999999999999 {
+		t.Fatalf("unexpected values: %+v", a)
+	}
+}
+
+func TestScanNumeric(t *testing.T) {
+	var f float32
+	if err := ScanNumeric(&f).Scan([]byte("1.5")); err != nil || f != 1.5 {
+		t.Fatalf("expected 1.5, got %v, %v", f, err)
+	}
+	var i int64
+	if err := ScanNumeric(&i).Scan(int64(42)); err != nil || i != 42 {
+		t.Fatalf("expected 42, got %v, %v", i, err)
+	}
+
+	var small int8
+	if err := ScanNumeric(&small).Scan("300"); err == nil || !strings.Contains(err.Error(), "overflows") {
+		t.Fatalf("expected overflow error, got %v", err)
+	}
+	var u uint64
+	if err := ScanNumeric(&u).Scan("-1"); err == nil {
+		t.Fatal("expected negative string to fail for uint64")
+	}
+	if err := ScanNumeric(&i).Scan("12.5"); err == nil {
+		t.Fatal("expected fractional string to fail for int64")
+	}
+	if err := ScanNumeric(&i).Scan(nil); err == nil {
+		t.Fatal("expected NULL to fail")
+	}
+	var s string
+	if err := ScanNumeric(&s).Scan("1"); err == nil {
+		t.Fatal("expected non-numeric destination to fail")
+	}
+}
+
+func TestScanStruct_IntegralFloats(t *testing.T) {
+	type item struct {
+		Qty   int    `db:"qty"`
+		Count uint32 `db:"count"`
+	}
+	rows := &MockRows{
+		cols: []string{"qty", "count"},
+		data: [][]any{{float64(3), float64(7)}},
+	}
+	rows.Next()
+
+	var it item
+	if err := ScanStruct(rows, &it); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if it.Qty != 3 || it.Count != 7 {
+		t.Fatalf("unexpected values: %+v", it)
+	}
+
+	var i int
+	if err := ScanNumeric(&i).Scan(3.5); err == nil {
+		t.Fatal("expected fractional float to fail for int")
+	}
+	var u uint
+	if err := ScanNumeric(&u).Scan(float64(-1)); err == nil {
+		t.Fatal("expected negative float to fail for uint")
+	}
+}
@@ -0,0 +1,125 @@
+package mysql
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// structFields describes how result columns map onto the fields of a struct type.
+type structFields struct {
+	byColumn map[string][]int // Lowercased column name to field index path
+}
+
+// structFieldsCache caches reflection metadata per struct type.
+var structFieldsCache sync.Map // map[reflect.Type]*structFields
+
+// ScanStruct scans the current row of rows into the struct pointed to by dest.
+// Columns are matched to fields by their `db:"column"` tag; untagged exported
+// fields match on their lowercased name and fields tagged `db:"-"` are skipped.
+// Matching is case-insensitive, as are MySQL column names. Fields of embedded
+// structs are promoted. Columns without a matching field are discarded.
+//
+// Fields may be pointers (e.g. *string) to receive NULL values when rows is
+// backed by database/sql. Struct, map, non-byte slice and json.RawMessage
+// fields are filled by unmarshaling JSON when the column is of type JSON (or,
+// for Rows without column types, whenever such a field is matched).
+// Integer, unsigned and float fields also accept numeric strings, such as
+// DECIMAL or BIGINT UNSIGNED values reported as text (see ScanNumeric).
+// Reflection metadata is cached per type, so repeated
+// calls only pay for the column lookup.
+func ScanStruct(rows Rows, dest any) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("mysql: ScanStruct destination must be a non-nil pointer to a struct, got %T", dest)
+	}
+	v = v.Elem()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if len(cols) == 0 {
+		return errors.New("mysql: ScanStruct requires column names")
+	}
+
+	fields := fieldsOf(v.Type())
+	typeNames := columnTypeNames(rows)
+	targets := make([]any, len(cols))
+	for i, col := range cols {
+		path, ok := fields.byColumn[strings.ToLower(col)]
+		if !ok {
+			// Unmapped column - scan into a throwaway value
+			targets[i] = new(any)
+			continue
+		}
+		field := v.FieldByIndex(path)
+		if jsonDecodable(field.Type()) && (typeNames == nil || (i < len(typeNames) && typeNames[i] == "JSON")) {
+			targets[i] = jsonColumn{dest: field.Addr().Interface()}
+			continue
+		}
+		if isNumericKind(field.Kind()) && !reflect.PointerTo(field.Type()).Implements(scannerType) {
+			targets[i] = numericColumn{dest: field.Addr().Interface()}
+			continue
+		}
+		targets[i] = field.Addr().Interface()
+	}
+
+	return rows.Scan(targets...)
+}
+
+// fieldsOf returns cached column-to-field metadata for struct type t.
+func fieldsOf(t reflect.Type) *structFields {
+	if cached, ok := structFieldsCache.Load(t); ok {
+		return cached.(*structFields)
+	}
+
+	fields := &structFields{byColumn: make(map[string][]int)}
+	collectFields(t, nil, fields)
+
+	actual, _ := structFieldsCache.LoadOrStore(t, fields)
+	return actual.(*structFields)
+}
+
+// collectFields walks the fields of t, recursing into embedded structs.
+// Fields closer to the root win over promoted fields with the same column.
+func collectFields(t reflect.Type, index []int, fields *structFields) {
+	var embedded []reflect.StructField
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("db")
+		if tag == "-" {
+			continue
+		}
+
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			embedded = append(embedded, f)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		name := tag
+		if name == "" {
+			name = f.Name
+		}
+		name = strings.ToLower(name)
+		if _, exists := fields.byColumn[name]; exists {
+			continue
+		}
+
+		path := make([]int, len(index)+1)
+		copy(path, index)
+		path[len(index)] = i
+		fields.byColumn[name] = path
+	}
+
+	for _, f := range embedded {
+		path := append(append([]int(nil), index...), f.Index...)
+		collectFields(f.Type, path, fields)
+	}
+}
@@ -0,0 +1,132 @@
+package mysql
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type scanAudit struct {
+	CreatedBy string `db:"created_by"`
+}
+
+type scanAccount struct {
+	scanAudit
+	ID       int    `db:"id"`
+	Name     string `db:"name"`
+	Email    string // Matched by lowercased field name
+	Internal string `db:"-"`
+	note     string
+}
+
+func TestScanStruct_ShuffledColumns(t *testing.T) {
+	rows := &MockRows{
+		cols: []string{"email", "NAME", "created_by", "id", "unmapped"},
+		data: [][]any{
+			{"a@example.com", "Alice", "admin", 1, "ignored"},
+			{"b@example.com", "Bob", "system", 2, "ignored"},
+		},
+	}
+
+	var got []scanAccount
+	for rows.Next() {
+		var acc scanAccount
+		if err := ScanStruct(rows, &acc); err != nil {
+			t.Fatalf("ScanStruct failed: %v", err)
+		}
+		got = append(got, acc)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(got))
+	}
+	want := scanAccount{ID: 1, Name: "Alice", Email: "a@example.com", scanAudit: scanAudit{CreatedBy: "admin"}}
+	if got[0] != want {
+		t.Fatalf("unexpected first row: %+v", got[0])
+	}
+	if got[1].ID != 2 || got[1].Name != "Bob" || got[1].CreatedBy != "system" {
+		t.Fatalf("unexpected second row: %+v", got[1])
+	}
+}
+
+func TestScanStruct_SkipsIgnoredFields(t *testing.T) {
+	fields := fieldsOf(typeOf[scanAccount]())
+	if _, ok := fields.byColumn["internal"]; ok {
+		t.Fatalf("expected db:\"-\" field to be skipped")
+	}
+	if _, ok := fields.byColumn["note"]; ok {
+		t.Fatalf("expected unexported field to be skipped")
+	}
+	if fieldsOf(typeOf[scanAccount]()) != fields {
+		t.Fatalf("expected metadata to be cached per type")
+	}
+}
+
+func TestScanStruct_InvalidDestination(t *testing.T) {
+	rows := &MockRows{cols: []string{"id"}, data: [][]any{{1}}}
+	rows.Next()
+
+	var acc scanAccount
+	for _, dest := range []any{acc, (*scanAccount)(nil), new(int)} {
+		if err := ScanStruct(rows, dest); err == nil {
+			t.Fatalf("expected error for destination %T", dest)
+		}
+	}
+}
+
+func TestScanStruct_MissingColumns(t *testing.T) {
+	rows := &MockRows{data: [][]any{{1}}}
+	rows.Next()
+
+	var acc scanAccount
+	if err := ScanStruct(rows, &acc); err == nil {
+		t.Fatalf("expected error when rows report no columns")
+	}
+}
+
+func TestScanStruct_SQLRowsPointerField(t *testing.T) {
+	db := newTestSQLDB(nil)
+	defer db.Close()
+
+	stmt, err := (&sqlDB{db: db}).PrepareContext(context.Background(), "SELECT value")
+	if err != nil {
+		t.Fatalf("PrepareContext failed: %v", err)
+	}
+	rows, err := stmt.QueryContext(context.Background())
+	if err != nil {
+		t.Fatalf("QueryContext failed: %v", err)
+	}
+	defer rows.Close()
+
+	var dest struct {
+		Value *string `db:"value"`
+	}
+	if !rows.Next() {
+		t.Fatalf("expected one row")
+	}
+	if err := ScanStruct(rows, &dest); err != nil {
+		t.Fatalf("ScanStruct failed: %v", err)
+	}
+	if dest.Value == nil || *dest.Value != "ok" {
+		t.Fatalf("unexpected value: %v", dest.Value)
+	}
+}
+
+func BenchmarkScanStruct(b *testing.B) {
+	rows := &MockRows{
+		cols: []string{"id", "name", "email"},
+		data: [][]any{{1, "Alice", "a@example.com"}},
+	}
+	rows.Next()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var acc scanAccount
+		_ = ScanStruct(rows, &acc)
+	}
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
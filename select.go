@@ -0,0 +1,26 @@
+package mysql
+
+// Select runs params through Query and scans every row into a T with
+// ScanStruct, so no callback is needed:
+//
+//	accounts, err := mysql.Select[Account](db, params)
+//
+// T must be a struct type; columns map onto fields by `db` tag (see
+// ScanStruct). An empty result yields a pointer to an empty slice. Caching
+// behaves exactly as for Query with the same params.
+func Select[T any](c *MySQL, params Params) (*[]T, *MySQLError) {
+	return Query(c, params, scanSlice[T])
+}
+
+// scanSlice is the Query callback used by Select.
+func scanSlice[T any](rows Rows) (*[]T, *MySQLError) {
+	out := []T{}
+	for rows.Next() {
+		var v T
+		if err := ScanStruct(rows, &v); err != nil {
+			return nil, NewError(err)
+		}
+		out = append(out, v)
+	}
+	return &out, nil
+}
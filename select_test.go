@@ -0,0 +1,67 @@
+package mysql
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSelect_ScansRows(t *testing.T) {
+	calls := 0
+	db := NewMockDB()
+	db.WithStmt("SELECT id, name, email, created_by FROM accounts", &MockStmt{Factory: func() Rows {
+		calls++
+		return &MockRows{
+			cols: []string{"id", "name", "email", "created_by"},
+			data: [][]any{
+				{1, "Alice", "a@example.com", "admin"},
+				{2, "Bob", "b@example.com", "system"},
+			},
+		}
+	}})
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	params := Params{Query: "SELECT id, name, email, created_by FROM accounts", CacheDelay: time.Minute}
+	got, err := Select[scanAccount](client, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*got) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(*got))
+	}
+	if a := (*got)[1]; a.ID != 2 || a.Name != "Bob" || a.Email != "b@example.com" || a.CreatedBy != "system" {
+		t.Fatalf("unexpected second row: %+v", a)
+	}
+
+	// Results are cached like any other Query
+	if again, err := Select[scanAccount](client, params); err != nil || again != got || calls != 1 {
+		t.Fatalf("expected a cache hit, got %d calls (%v)", calls, err)
+	}
+}
+
+func TestSelect_Empty(t *testing.T) {
+	db := NewMockDB()
+	db.WithStmt("SELECT id FROM accounts", &MockStmt{Factory: func() Rows {
+		return &MockRows{cols: []string{"id"}}
+	}})
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	got, err := Select[scanAccount](client, Params{Query: "SELECT id FROM accounts"})
+	if err != nil || got == nil || len(*got) != 0 {
+		t.Fatalf("expected an empty slice, got %v (%v)", got, err)
+	}
+}
+
+func TestSelect_ScanError(t *testing.T) {
+	db := NewMockDB()
+	db.WithStmt("SELECT id FROM accounts", &MockStmt{Factory: func() Rows {
+		return &MockRows{data: [][]any{{1}}} // no column names
+	}})
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	if _, err := Select[scanAccount](client, Params{Query: "SELECT id FROM accounts"}); err == nil {
+		t.Fatal("expected a scan error")
+	}
+}
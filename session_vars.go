@@ -0,0 +1,239 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SessionDB is an optional extension of DB for implementations that can pin
+// a single connection. Params.SessionVars requires it; the DB built by New
+// and NewWithDB implements it with sql.DB.Conn.
+type SessionDB interface {
+	// Session reserves one connection until the returned Session is closed.
+	Session(ctx context.Context) (Session, error)
+}
+
+// Session is a pinned connection: every statement runs on the same server
+// session, so session variables set by one are seen by the next.
+type Session interface {
+	// QueryContext runs query on the pinned connection.
+	QueryContext(ctx context.Context, query string, args ...any) (Rows, error)
+
+	// ExecContext runs a statement that returns no rows on the pinned connection.
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+
+	// Close returns the connection to the pool.
+	Close() error
+
+	// Discard closes the connection instead of returning it to the pool,
+	// for sessions whose state could not be restored.
+	Discard() error
+}
+
+var _ SessionDB = (*sqlDB)(nil)
+
+// Session implements SessionDB using sql.DB.Conn.
+func (s *sqlDB) Session(ctx context.Context) (Session, error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlSession{conn: conn}, nil
+}
+
+// sqlSession adapts *sql.Conn to Session.
+type sqlSession struct {
+	conn *sql.Conn
+}
+
+// QueryContext implements Session.
+func (s *sqlSession) QueryContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	return s.conn.QueryContext(ctx, query, args...)
+}
+
+// ExecContext implements Session.
+func (s *sqlSession) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return s.conn.ExecContext(ctx, query, args...)
+}
+
+// Close implements Session.
+func (s *sqlSession) Close() error {
+	return s.conn.Close()
+}
+
+// Discard implements Session. Reporting driver.ErrBadConn from Raw makes
+// database/sql close the connection rather than reuse it.
+func (s *sqlSession) Discard() error {
+	_ = s.conn.Raw(func(any) error { return driver.ErrBadConn })
+	return s.conn.Close()
+}
+
+// sessionQuery runs a query with Params.SessionVars on a pinned connection:
+// the current values are saved, the variables set, the query run, and the
+// saved values restored. The result depends on session state that is not
+// part of the cache key, so it is neither read from nor written to the cache.
+// If the variables cannot be restored, the connection is discarded so the
+// changed session never serves another query.
+func sessionQuery[T any](
+	c *MySQL,
+	params Params,
+	callback func(rows Rows) (*T, *MySQLError),
+) (*T, *MySQLError) {
+	if merr := c.validateStatement(params); merr != nil {
+		return nil, merr
+	}
+	sdb, ok := c.DB.(SessionDB)
+	if !ok {
+		return nil, ErrSessionVars.WithCause(fmt.Errorf("%T cannot pin a connection (SessionDB)", c.DB))
+	}
+	names, err := sessionVarNames(params.SessionVars)
+	if err != nil {
+		return nil, ErrSessionVars.WithCause(err)
+	}
+
+	ctx, cancel := createContextWithTimeout(params.requestContext(), params.Timeout)
+	defer cancel()
+
+	release, err := c.acquireQuerySlot(ctx)
+	if err != nil {
+		return nil, c.mapError(err)
+	}
+	defer release()
+
+	sess, err := sdb.Session(ctx)
+	if err != nil {
+		return nil, c.mapError(err)
+	}
+	saved, err := saveSessionVars(ctx, sess, names)
+	if err != nil {
+		_ = sess.Close()
+		return nil, c.mapError(err)
+	}
+	defer func() {
+		restore, args := setSessionVars(names, saved)
+		if _, err := sess.ExecContext(ctx, restore, args...); err != nil {
+			c.log().Warn("mysql: could not restore session variables, discarding connection", "error", err)
+			_ = sess.Discard()
+			return
+		}
+		_ = sess.Close()
+	}()
+
+	values := make([]sql.NullString, len(names))
+	for i, name := range names {
+		values[i] = sql.NullString{String: params.SessionVars[name], Valid: true}
+	}
+	set, args := setSessionVars(names, values)
+	if _, err := sess.ExecContext(ctx, set, args...); err != nil {
+		return nil, c.mapError(err)
+	}
+
+	rows, err := sess.QueryContext(ctx, generateQuery(params), params.Args...)
+	if err != nil {
+		return nil, c.mapError(err)
+	}
+	defer rows.Close()
+	limited, limiter := limitRows(rows, params.MaxRows)
+
+	res, merr := runCallback(c, limited, callback)
+	if limiter.Exceeded() {
+		return nil, ErrRowLimit
+	}
+	if merr == nil && ctx.Err() != nil {
+		return nil, c.mapError(ctx.Err())
+	}
+	return res, merr
+}
+
+// sessionVarNames returns the names in vars, sorted so the generated
+// statements are stable. Names are spliced into SQL, so only identifiers
+// made of letters, digits and '_' are accepted.
+func sessionVarNames(vars map[string]string) ([]string, error) {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		if name == "" || strings.IndexFunc(name, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_')
+		}) >= 0 {
+			return nil, fmt.Errorf("invalid session variable name %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// saveSessionVars reads the current session values of names.
+func saveSessionVars(ctx context.Context, sess Session, names []string) ([]sql.NullString, error) {
+	var sb strings.Builder
+	sb.WriteString("SELECT ")
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("@@SESSION.")
+		sb.WriteString(name)
+	}
+
+	rows, err := sess.QueryContext(ctx, sb.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, errors.New("mysql: reading session variables returned no row")
+	}
+
+	saved := make([]sql.NullString, len(names))
+	dest := make([]any, len(names))
+	for i := range saved {
+		dest[i] = &saved[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// setSessionVars builds one SET SESSION statement assigning values to names
+// and returns it with its arguments. Only the validated names are spliced
+// into the SQL; values are bound as placeholders, so no value can change the
+// statement whatever the SQL mode. NULL restores DEFAULT.
+func setSessionVars(names []string, values []sql.NullString) (string, []any) {
+	buf := []byte("SET SESSION ")
+	args := make([]any, 0, len(names))
+	for i, name := range names {
+		if i > 0 {
+			buf = append(buf, ", "...)
+		}
+		buf = append(buf, name...)
+		if !values[i].Valid {
+			buf = append(buf, " = DEFAULT"...)
+			continue
+		}
+		buf = append(buf, " = ?"...)
+		args = append(args, sessionVarArg(values[i].String))
+	}
+	return string(buf), args
+}
+
+// sessionVarArg converts a session variable value to its bound argument.
+// Numeric strings are bound as int64 or float64, since integer variables
+// reject string values.
+func sessionVarArg(s string) any {
+	if s == "" || strings.ContainsAny(s, " \t\n") {
+		return s
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && !strings.ContainsAny(s, "xXnN") { // rejects hex floats, Inf and NaN
+		return f
+	}
+	return s
+}
@@ -0,0 +1,121 @@
+package mysql
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const (
+	sessionSaveQuery = "SELECT @@SESSION.group_concat_max_len, @@SESSION.sql_mode"
+	sessionUserQuery = "SELECT GROUP_CONCAT(name) FROM users"
+)
+
+func newSessionDB() *MockDB {
+	db := NewMockDB()
+	db.WithStmt(sessionSaveQuery, &MockStmt{Factory: func() Rows {
+		return &MockRows{data: [][]any{{"1024", "STRICT_TRANS_TABLES"}}}
+	}})
+	db.WithStmt(sessionUserQuery, &MockStmt{Factory: func() Rows {
+		return &MockRows{data: [][]any{{"alice,bob"}}}
+	}})
+	return db
+}
+
+func TestQuery_SessionVarsAppliedOnTheQueryConnection(t *testing.T) {
+	db := newSessionDB()
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	params := Params{
+		Query:       sessionUserQuery,
+		CacheDelay:  time.Minute,
+		SessionVars: map[string]string{"sql_mode": "ANSI", "group_concat_max_len": "1000000"},
+	}
+	for i := 0; i < 2; i++ {
+		res, err := Query(client, params, scanStrings)
+		if err != nil || (*res)[0] != "alice,bob" {
+			t.Fatalf("Query #%d: got %v (%v)", i+1, res, err)
+		}
+	}
+
+	// Never cached: both calls ran on their own pinned connection
+	if len(db.Sessions) != 2 || len(client.inMemory.(*InMemoryStorage).Keys()) != 0 || db.Prepares != 0 {
+		t.Fatalf("expected two uncached session queries, got %d sessions, %d prepares", len(db.Sessions), db.Prepares)
+	}
+	sess := db.Sessions[0]
+	const set = "SET SESSION group_concat_max_len = ?, sql_mode = ?"
+	want := []string{sessionSaveQuery, set, sessionUserQuery, set}
+	if !reflect.DeepEqual(sess.Statements, want) {
+		t.Fatalf("unexpected statements on the session:\n got %q\nwant %q", sess.Statements, want)
+	}
+	wantArgs := [][]any{nil, {int64(1000000), "ANSI"}, nil, {int64(1024), "STRICT_TRANS_TABLES"}}
+	if !reflect.DeepEqual(sess.Args, wantArgs) {
+		t.Fatalf("unexpected arguments on the session:\n got %v\nwant %v", sess.Args, wantArgs)
+	}
+	if !sess.Closed || sess.Discarded {
+		t.Fatalf("expected the session to be returned to the pool, got %+v", sess)
+	}
+}
+
+func TestQuery_SessionVarsDiscardConnectionWhenRestoreFails(t *testing.T) {
+	db := newSessionDB()
+	db.ExecErr = errors.New("read-only variable")
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+	client.logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	params := Params{Query: sessionUserQuery, SessionVars: map[string]string{"sql_mode": "ANSI", "group_concat_max_len": "1"}}
+	if _, err := Query(client, params, scanStrings); !errors.Is(err, db.ExecErr) {
+		t.Fatalf("expected the SET error, got %v", err)
+	}
+	sess := db.Sessions[0]
+	if !sess.Discarded || sess.Closed {
+		t.Fatalf("expected the session to be discarded, got %+v", sess)
+	}
+	for _, stmt := range sess.Statements {
+		if stmt == sessionUserQuery {
+			t.Fatal("the query must not run when the variables could not be set")
+		}
+	}
+}
+
+func TestQuery_SessionVarsErrors(t *testing.T) {
+	client, cleanup := newInternalClient(&countingDB{})
+	defer cleanup()
+	params := Params{Query: sessionUserQuery, SessionVars: map[string]string{"sql_mode": "ANSI"}}
+	if _, err := Query(client, params, scanStrings); !errors.Is(err, ErrSessionVars) {
+		t.Fatalf("expected ErrSessionVars without SessionDB, got %v", err)
+	}
+
+	db := newSessionDB()
+	client.DB = db
+	params.SessionVars = map[string]string{"sql_mode = ''; DROP TABLE users; --": "x"}
+	if _, err := Query(client, params, scanStrings); !errors.Is(err, ErrSessionVars) || len(db.Sessions) != 0 {
+		t.Fatalf("expected an invalid name to be rejected before pinning, got %v", err)
+	}
+}
+
+func TestSetSessionVars_BindsValues(t *testing.T) {
+	names := []string{"a", "b", "c", "d", "e"}
+	values := []sql.NullString{
+		{String: "42", Valid: true},
+		{String: "-1.5e3", Valid: true},
+		{String: "it's", Valid: true},
+		{String: "NaN", Valid: true},
+		{},
+	}
+	query, args := setSessionVars(names, values)
+	if want := "SET SESSION a = ?, b = ?, c = ?, d = ?, e = DEFAULT"; query != want {
+		t.Fatalf("got  %s\nwant %s", query, want)
+	}
+	// Values are bound, never spliced, so a quote cannot end a literal
+	// even under NO_BACKSLASH_ESCAPES
+	if want := []any{int64(42), -1500.0, "it's", "NaN"}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("got args %#v, want %#v", args, want)
+	}
+}
@@ -0,0 +1,64 @@
+package mysql
+
+import "context"
+
+// beginQuery registers an in-flight query. It returns false once the client
+// has started shutting down, in which case the query must not run.
+// Every successful call must be paired with endQuery.
+func (c *MySQL) beginQuery() bool {
+	// Fast path without locking once the client is closed
+	if c.closed.Load() {
+		return false
+	}
+
+	c.lifecycle.RLock()
+	defer c.lifecycle.RUnlock()
+
+	// Re-check under the lock: markClosed may have run since the fast path
+	if c.closed.Load() {
+		return false
+	}
+	c.inflight.Add(1)
+	return true
+}
+
+// endQuery marks an in-flight query registered by beginQuery as finished.
+func (c *MySQL) endQuery() {
+	c.inflight.Done()
+}
+
+// markClosed stops the client from accepting new queries.
+// Taking the write lock guarantees no beginQuery is between its check
+// and inflight.Add, so a subsequent Wait cannot race with new registrations.
+func (c *MySQL) markClosed() {
+	c.lifecycle.Lock()
+	c.closed.Store(true)
+	c.lifecycle.Unlock()
+}
+
+// Shutdown gracefully closes the client. It immediately stops accepting new
+// queries (they fail with ErrClosed), waits for in-flight queries to finish,
+// and then releases prepared statements and the database connection.
+//
+// If ctx expires before in-flight queries finish, resources are closed anyway,
+// aborting the remaining queries, and ctx.Err() is returned.
+// Use Close for an immediate shutdown.
+func (c *MySQL) Shutdown(ctx context.Context) error {
+	c.markClosed()
+
+	drained := make(chan struct{})
+	go func() {
+		c.inflight.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	c.Close()
+	return err
+}
@@ -0,0 +1,184 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingStmt blocks QueryContext until released, signalling when it starts.
+type blockingStmt struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingStmt() *blockingStmt {
+	return &blockingStmt{started: make(chan struct{}, 1), release: make(chan struct{})}
+}
+
+func (s *blockingStmt) QueryContext(ctx context.Context, args ...any) (Rows, error) {
+	s.started <- struct{}{}
+	select {
+	case <-s.release:
+		return &MockRows{data: [][]any{{1}}}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *blockingStmt) QueryRowContext(ctx context.Context, args ...any) Row {
+	return rowFromRows(s.QueryContext(ctx, args...))
+}
+
+func (s *blockingStmt) ExecContext(ctx context.Context, args ...any) (sql.Result, error) {
+	return nil, errors.New("unexpected exec")
+}
+
+func (s *blockingStmt) Close() error { return nil }
+
+func countRows(rows Rows) (*int, *MySQLError) {
+	n := 0
+	for rows.Next() {
+		n++
+	}
+	return &n, nil
+}
+
+func TestShutdown_WaitsForInFlightQuery(t *testing.T) {
+	stmt := newBlockingStmt()
+	db := &closeDB{}
+	client := &MySQL{
+		DB:      db,
+		prepare: map[string]Stmt{"SELECT 1": stmt},
+		stop:    make(chan struct{}, 1),
+	}
+
+	queryDone := make(chan *MySQLError, 1)
+	go func() {
+		_, err := Query(client, Params{Query: "SELECT 1"}, countRows)
+		queryDone <- err
+	}()
+	<-stmt.started
+
+	shutdownDone := make(chan error, 1)
+	go func() { shutdownDone <- client.Shutdown(context.Background()) }()
+
+	select {
+	case <-shutdownDone:
+		t.Fatalf("Shutdown returned before the in-flight query finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(stmt.release)
+	if err := <-queryDone; err != nil {
+		t.Fatalf("in-flight query failed: %+v", err)
+	}
+	if err := <-shutdownDone; err != nil {
+		t.Fatalf("unexpected Shutdown error: %v", err)
+	}
+	if !db.closed {
+		t.Fatalf("expected DB to be closed after draining")
+	}
+}
+
+func TestShutdown_RejectsNewQueries(t *testing.T) {
+	client := &MySQL{
+		DB:      &closeDB{},
+		prepare: make(map[string]Stmt),
+		stop:    make(chan struct{}, 1),
+	}
+	if err := client.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected Shutdown error: %v", err)
+	}
+
+	_, err := Query(client, Params{Query: "SELECT 1"}, countRows)
+	if err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %+v", err)
+	}
+}
+
+func TestShutdown_ContextDeadline(t *testing.T) {
+	stmt := newBlockingStmt()
+	db := &closeDB{}
+	client := &MySQL{
+		DB:      db,
+		prepare: map[string]Stmt{"SELECT 1": stmt},
+		stop:    make(chan struct{}, 1),
+	}
+	defer close(stmt.release)
+
+	go func() { _, _ = Query(client, Params{Query: "SELECT 1"}, countRows) }()
+	<-stmt.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := client.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+	if !db.closed {
+		t.Fatalf("expected resources to be closed after the deadline")
+	}
+}
+
+func TestClose_QueryReturnsErrClosed(t *testing.T) {
+	db := NewMockDB()
+	db.WithStmt("SELECT 1", &MockStmt{Factory: func() Rows { return &MockRows{data: [][]any{{1}}} }})
+	client := &MySQL{
+		DB:      db,
+		prepare: make(map[string]Stmt),
+		stop:    make(chan struct{}, 1),
+	}
+
+	client.Close()
+
+	res, err := Query(client, Params{Query: "SELECT 1"}, countRows)
+	if res != nil || err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v, %+v", res, err)
+	}
+	if db.Prepares != 0 {
+		t.Fatalf("expected closed client not to touch the DB")
+	}
+
+	if _, err := BulkInsert(client, "t", []string{"id"}, [][]any{{1}}, 1); err != ErrClosed {
+		t.Fatalf("expected ErrClosed from BulkInsert, got %+v", err)
+	}
+}
+
+func TestClose_ConcurrentWithQueries(t *testing.T) {
+	db := NewMockDB()
+	db.WithStmt("SELECT 1", &MockStmt{Factory: func() Rows { return &MockRows{data: [][]any{{1}}} }})
+	client := &MySQL{
+		DB:      db,
+		prepare: make(map[string]Stmt),
+		stop:    make(chan struct{}, 1),
+	}
+
+	// Warm the statement cache so queries do not race on MockDB internals
+	if _, err := Query(client, Params{Query: "SELECT 1"}, countRows); err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, err := Query(client, Params{Query: "SELECT 1"}, countRows)
+				if err != nil && err != ErrClosed {
+					t.Errorf("unexpected error: %+v", err)
+					return
+				}
+			}
+		}()
+	}
+	client.Close()
+	wg.Wait()
+
+	if _, err := Query(client, Params{Query: "SELECT 1"}, countRows); err != ErrClosed {
+		t.Fatalf("expected ErrClosed after Close, got %+v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package mysql
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// Sizer can be implemented by cached values to report their approximate
+// in-memory size in bytes, bypassing reflection-based estimation.
+type Sizer interface {
+	SizeHint() int
+}
+
+// estimateSize approximates how many bytes v occupies in memory once decoded,
+// including memory reachable through pointers, slices, maps, and interfaces.
+// Shared pointers are counted once. The estimate ignores allocator rounding
+// and runtime bookkeeping, so it is a lower bound suitable for budgeting.
+func estimateSize(v any) int {
+	switch x := v.(type) {
+	case nil:
+		return 0
+	case Sizer:
+		return x.SizeHint()
+	case []byte:
+		return int(unsafe.Sizeof(x)) + cap(x)
+	case string:
+		return int(unsafe.Sizeof(x)) + len(x)
+	}
+
+	rv := reflect.ValueOf(v)
+	e := sizeEstimator{}
+	return int(rv.Type().Size()) + e.indirect(rv)
+}
+
+// sizeEstimator walks values while tracking visited pointers to avoid
+// double counting shared data and looping on cycles.
+type sizeEstimator struct {
+	seen map[uintptr]struct{}
+}
+
+// visit reports whether p is being seen for the first time.
+func (e *sizeEstimator) visit(p uintptr) bool {
+	if e.seen == nil {
+		e.seen = make(map[uintptr]struct{})
+	}
+	if _, ok := e.seen[p]; ok {
+		return false
+	}
+	e.seen[p] = struct{}{}
+	return true
+}
+
+// indirect returns the bytes referenced by v outside of v's own inline storage.
+func (e *sizeEstimator) indirect(v reflect.Value) int {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || !e.visit(v.Pointer()) {
+			return 0
+		}
+		elem := v.Elem()
+		return int(elem.Type().Size()) + e.indirect(elem)
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		elem := v.Elem()
+		return int(elem.Type().Size()) + e.indirect(elem)
+
+	case reflect.String:
+		return v.Len()
+
+	case reflect.Slice:
+		if v.IsNil() || !e.visit(v.Pointer()) {
+			return 0
+		}
+		n := v.Cap() * int(v.Type().Elem().Size())
+		if hasIndirect(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				n += e.indirect(v.Index(i))
+			}
+		}
+		return n
+
+	case reflect.Array:
+		n := 0
+		if hasIndirect(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				n += e.indirect(v.Index(i))
+			}
+		}
+		return n
+
+	case reflect.Struct:
+		n := 0
+		for i := 0; i < v.NumField(); i++ {
+			if hasIndirect(v.Type().Field(i).Type) {
+				n += e.indirect(v.Field(i))
+			}
+		}
+		return n
+
+	case reflect.Map:
+		if v.IsNil() || !e.visit(v.Pointer()) {
+			return 0
+		}
+		t := v.Type()
+		n := v.Len() * int(t.Key().Size()+t.Elem().Size())
+		if hasIndirect(t.Key()) || hasIndirect(t.Elem()) {
+			iter := v.MapRange()
+			for iter.Next() {
+				n += e.indirect(iter.Key()) + e.indirect(iter.Value())
+			}
+		}
+		return n
+
+	default:
+		return 0
+	}
+}
+
+// hasIndirect reports whether values of type t can reference memory outside
+// their inline storage, letting the walk skip flat data such as []int.
+func hasIndirect(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.String, reflect.Slice, reflect.Map:
+		return true
+	case reflect.Array:
+		return t.Len() > 0 && hasIndirect(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if hasIndirect(t.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
@@ -0,0 +1,212 @@
+package mysql
+
+import (
+	"testing"
+	"time"
+)
+
+type sizedValue struct{ n int }
+
+func (v sizedValue) SizeHint() int { return v.n }
+
+func TestEstimateSize(t *testing.T) {
+	type user struct {
+		ID   int64
+		Name string
+		Tags []string
+	}
+
+	if got := estimateSize(nil); got != 0 {
+		t.Fatalf("nil size = %d, want 0", got)
+	}
+	if got := estimateSize(sizedValue{n: 1234}); got != 1234 {
+		t.Fatalf("SizeHint not used, got %d", got)
+	}
+	if got := estimateSize(make([]byte, 10, 100)); got != 24+100 {
+		t.Fatalf("[]byte size = %d, want %d", got, 24+100)
+	}
+
+	// *user: pointer (8) + struct (8+16+24) + name (5) + tags header (2*16) + tag bytes (1+2)
+	u := &user{ID: 1, Name: "alice", Tags: []string{"a", "bb"}}
+	if got, want := estimateSize(u), 8+48+5+32+3; got != want {
+		t.Fatalf("struct size = %d, want %d", got, want)
+	}
+
+	// Decoded objects grow with their contents
+	small := &[]user{{Name: "x"}}
+	large := make([]user, 1000)
+	for i := range large {
+		large[i].Name = "some fairly long user name"
+	}
+	if estimateSize(&large) <= 1000*estimateSize(small)/2 {
+		t.Fatalf("expected large slice to be accounted proportionally")
+	}
+
+	// Shared and cyclic pointers are counted once and terminate
+	type node struct {
+		Next *node
+		Data [64]byte
+	}
+	n := &node{}
+	n.Next = n
+	if got := estimateSize(n); got != 8+72 {
+		t.Fatalf("cyclic size = %d, want %d", got, 8+72)
+	}
+
+	m := map[string]int{"ab": 1, "c": 2}
+	if got := estimateSize(m); got != 8+2*(16+8)+3 {
+		t.Fatalf("map size = %d, want %d", got, 8+2*(16+8)+3)
+	}
+}
+
+func TestInMemoryStorage_ByteBudget(t *testing.T) {
+	store := NewInMemoryStorage(100, time.Minute)
+	defer store.Stop()
+	store.SetMaxBytes(1000)
+
+	_ = store.Set("a", sizedValue{400}, 0)
+	_ = store.Set("b", sizedValue{400}, 0)
+	if got := store.Bytes(); got != 800 {
+		t.Fatalf("Bytes() = %d, want 800", got)
+	}
+
+	// Exceeding the budget evicts the least recently used entry
+	_, _ = store.Get("a")
+	_ = store.Set("c", sizedValue{400}, 0)
+	if _, err := store.Get("b"); err != ErrNotFound {
+		t.Fatalf("expected b to be evicted, got %v", err)
+	}
+	if got := store.Bytes(); got != 800 {
+		t.Fatalf("Bytes() after eviction = %d, want 800", got)
+	}
+
+	// Replacing a value adjusts accounting
+	_ = store.Set("a", sizedValue{100}, 0)
+	if got := store.Bytes(); got != 500 {
+		t.Fatalf("Bytes() after replace = %d, want 500", got)
+	}
+
+	// Explicit size hints bypass estimation
+	_ = store.SetWithSize("d", "tiny", 450, 0)
+	if got := store.Bytes(); got != 950 {
+		t.Fatalf("Bytes() after SetWithSize = %d, want 950", got)
+	}
+
+	// A value larger than the whole budget is rejected and nothing is evicted
+	if err := store.Set("huge", sizedValue{2000}, 0); err != ErrValueTooLarge {
+		t.Fatalf("expected ErrValueTooLarge, got %v", err)
+	}
+	for _, key := range []string{"a", "c", "d"} {
+		if _, err := store.Get(key); err != nil {
+			t.Fatalf("expected %q to survive, got %v", key, err)
+		}
+	}
+
+	_ = store.Delete("d")
+	if got := store.Bytes(); got != 500 {
+		t.Fatalf("Bytes() after delete = %d, want 500", got)
+	}
+	store.Reset()
+	if got := store.Bytes(); got != 0 {
+		t.Fatalf("Bytes() after reset = %d, want 0", got)
+	}
+}
+
+func TestInMemoryStorage_TypedEntriesEvictedByDecodedSize(t *testing.T) {
+	type row struct {
+		ID   int
+		Body string
+	}
+
+	makeRows := func() *[]row {
+		rows := make([]row, 100)
+		for j := range rows {
+			rows[j].Body = "decoded objects are larger than msgpack"
+		}
+		return &rows
+	}
+
+	store := NewInMemoryStorage(1000, time.Minute)
+	defer store.Stop()
+
+	// Entries are already present when the budget is configured
+	for i := 0; i < 10; i++ {
+		_ = store.Set(string(rune('a'+i)), makeRows(), 0)
+	}
+
+	budget := 3 * estimateSize(makeRows())
+	store.SetMaxBytes(budget)
+
+	store.mu.RLock()
+	count := store.curSize
+	store.mu.RUnlock()
+	if count != 3 {
+		t.Fatalf("expected byte budget to keep 3 typed entries, got %d", count)
+	}
+	if _, err := store.Get("j"); err != nil {
+		t.Fatalf("expected most recent entry to survive, got %v", err)
+	}
+	if _, err := store.Get("a"); err != ErrNotFound {
+		t.Fatalf("expected oldest entry to be evicted, got %v", err)
+	}
+	if store.Bytes() > budget {
+		t.Fatalf("Bytes() = %d exceeds budget %d", store.Bytes(), budget)
+	}
+}
+
+// lockProbe records whether the store's write lock was held while its size
+// was estimated, and can write to the store from inside the estimate.
+type lockProbe struct {
+	store   *InMemoryStorage
+	locked  *bool
+	onSize  func()
+	sizeHit int
+}
+
+func (p lockProbe) SizeHint() int {
+	if p.store.mu.TryRLock() {
+		p.store.mu.RUnlock()
+	} else {
+		*p.locked = true
+	}
+	if p.onSize != nil {
+		p.onSize()
+	}
+	return p.sizeHit
+}
+
+func TestInMemoryStorage_EstimatesOutsideWriteLock(t *testing.T) {
+	store := NewInMemoryStorage(10, time.Minute)
+	defer store.Stop()
+	var locked bool
+
+	// Sizing for an existing budget happens before Set locks
+	store.SetMaxBytes(1 << 20)
+	_ = store.Set("a", lockProbe{store: store, locked: &locked, sizeHit: 10}, time.Minute)
+	if locked || store.Bytes() != 10 {
+		t.Fatalf("expected a 10-byte entry sized without the lock, locked=%v bytes=%d", locked, store.Bytes())
+	}
+
+	// Enabling a budget sizes existing entries without the lock; an entry
+	// written meanwhile is sized under it
+	other := NewInMemoryStorage(10, time.Minute)
+	defer other.Stop()
+	_ = other.Set("a", lockProbe{store: other, locked: &locked, sizeHit: 10, onSize: func() {
+		_ = other.Set("late", sizedValue{100}, time.Minute)
+	}}, time.Minute)
+	other.SetMaxBytes(1 << 20)
+	if locked || other.Bytes() != 110 {
+		t.Fatalf("expected 110 bytes sized without the lock, locked=%v bytes=%d", locked, other.Bytes())
+	}
+
+	// An entry overwritten while it was sized is sized again for its new value
+	third := NewInMemoryStorage(10, time.Minute)
+	defer third.Stop()
+	_ = third.Set("a", lockProbe{store: third, locked: &locked, sizeHit: 10, onSize: func() {
+		_ = third.Set("a", sizedValue{50}, time.Minute)
+	}}, time.Minute)
+	third.SetMaxBytes(1 << 20)
+	if third.Bytes() != 50 {
+		t.Fatalf("expected the replaced value to be sized, got %d bytes", third.Bytes())
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"database/sql/driver"
 	"errors"
 	"io"
+	"time"
 )
 
 var lastTestStmt *testStmt
@@ -17,12 +18,14 @@ func (testDriver) Open(name string) (driver.Conn, error) {
 }
 
 type testConnector struct {
-	pingErr    error
-	prepareErr error
+	pingErr      error
+	pingDelay    time.Duration
+	prepareErr   error
+	prepareDelay time.Duration
 }
 
 func (c *testConnector) Connect(ctx context.Context) (driver.Conn, error) {
-	return &testConn{pingErr: c.pingErr, prepareErr: c.prepareErr}, nil
+	return &testConn{pingErr: c.pingErr, pingDelay: c.pingDelay, prepareErr: c.prepareErr, prepareDelay: c.prepareDelay}, nil
 }
 
 func (c *testConnector) Driver() driver.Driver {
@@ -30,8 +33,10 @@ func (c *testConnector) Driver() driver.Driver {
 }
 
 type testConn struct {
-	pingErr    error
-	prepareErr error
+	pingErr      error
+	pingDelay    time.Duration // Ping blocks this long unless ctx ends first
+	prepareErr   error
+	prepareDelay time.Duration // PrepareContext blocks this long unless ctx ends first
 }
 
 func (c *testConn) Prepare(query string) (driver.Stmt, error) {
@@ -39,6 +44,13 @@ func (c *testConn) Prepare(query string) (driver.Stmt, error) {
 }
 
 func (c *testConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if c.prepareDelay > 0 {
+		select {
+		case <-time.After(c.prepareDelay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
 	if c.prepareErr != nil {
 		return nil, c.prepareErr
 	}
@@ -54,6 +66,13 @@ func (c *testConn) Begin() (driver.Tx, error) {
 }
 
 func (c *testConn) Ping(ctx context.Context) error {
+	if c.pingDelay > 0 {
+		select {
+		case <-time.After(c.pingDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
 	return c.pingErr
 }
 
@@ -118,3 +137,19 @@ func newTestSQLDB(pingErr error) *sql.DB {
 func newTestSQLDBWithPrepareErr(pingErr, prepareErr error) *sql.DB {
 	return sql.OpenDB(&testConnector{pingErr: pingErr, prepareErr: prepareErr})
 }
+
+func newTestSQLDBWithPrepareDelay(delay time.Duration) *sql.DB {
+	return sql.OpenDB(&testConnector{prepareDelay: delay})
+}
+
+// noDirectStatements gives DB test doubles the unprepared QueryContext and
+// ExecContext methods for tests that only exercise prepared statements.
+type noDirectStatements struct{}
+
+func (noDirectStatements) QueryContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	return nil, errors.New("unprepared queries not supported")
+}
+
+func (noDirectStatements) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return nil, errors.New("unprepared execs not supported")
+}
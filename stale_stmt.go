@@ -0,0 +1,132 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// errUnknownStmtHandler is the server error for a prepared statement it no
+// longer knows, e.g. after a restart or max_prepared_stmt_count recycling.
+const errUnknownStmtHandler = 1243
+
+// Client errors for a connection that died under a statement.
+const (
+	errServerGone = 2006 // CR_SERVER_GONE_ERROR
+	errServerLost = 2013 // CR_SERVER_LOST
+)
+
+// isStaleStatement reports whether err means the statement must be prepared again.
+func isStaleStatement(err error) bool {
+	var sqlErr *mysql.MySQLError
+	return errors.As(err, &sqlErr) && sqlErr.Number == errUnknownStmtHandler
+}
+
+// isConnectionLost reports whether err means the connection running the
+// statement is dead, so a retry will be served by a freshly dialed one.
+func isConnectionLost(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) {
+		return true
+	}
+	var sqlErr *mysql.MySQLError
+	return errors.As(err, &sqlErr) && (sqlErr.Number == errServerGone || sqlErr.Number == errServerLost)
+}
+
+// canRetryQuery reports whether a query for params that failed with err is
+// run again on a freshly prepared statement. An unknown statement is
+// rejected before executing and is always retried. A lost connection is
+// retried only for a plain Params.Query read: a Params.Exec procedure call
+// may write, and the write may already have been applied.
+func canRetryQuery(params Params, err error) bool {
+	return isStaleStatement(err) || params.Query != "" && isConnectionLost(err)
+}
+
+// queryStmt runs stmt for params. If canRetryQuery allows it, the statement
+// is evicted from the cache, prepared again, and the query retried once; a
+// second failure is returned as is.
+func (c *MySQL) queryStmt(ctx context.Context, params Params, query string, stmt Stmt) (Rows, error) {
+	if params.singleRow {
+		return &rowRows{row: c.queryRowStmt(ctx, params, query, stmt)}, nil
+	}
+	rows, err := stmt.QueryContext(ctx, params.Args...)
+	if !canRetryQuery(params, err) {
+		return rows, err
+	}
+	fresh, perr := c.reprepare(params, query, stmt)
+	if perr != nil {
+		return nil, perr
+	}
+	return fresh.QueryContext(ctx, params.Args...)
+}
+
+// queryRowStmt is queryStmt for Stmt.QueryRowContext. A Row reports errors
+// only from Scan, so that is where the statement is prepared again and the
+// query retried.
+func (c *MySQL) queryRowStmt(ctx context.Context, params Params, query string, stmt Stmt) Row {
+	return &retryRow{
+		row:    stmt.QueryRowContext(ctx, params.Args...),
+		params: params,
+		retry: func() Row {
+			fresh, err := c.reprepare(params, query, stmt)
+			if err != nil {
+				return rowFromRows(nil, err)
+			}
+			return fresh.QueryRowContext(ctx, params.Args...)
+		},
+	}
+}
+
+// retryRow is a Row that runs retry once when Scan fails with an error
+// canRetryQuery allows for params.
+type retryRow struct {
+	row    Row
+	params Params
+	retry  func() Row
+}
+
+// Scan implements Row.
+func (r *retryRow) Scan(dest ...any) error {
+	err := r.row.Scan(dest...)
+	if !canRetryQuery(r.params, err) {
+		return err
+	}
+	return r.retry().Scan(dest...)
+}
+
+// execStmt is the ExecContext counterpart of queryStmt. It only retries an
+// unknown statement, which the server rejects before executing: after a lost
+// connection the write may already have been applied.
+func (c *MySQL) execStmt(ctx context.Context, params Params, query string, stmt Stmt) (sql.Result, error) {
+	res, err := stmt.ExecContext(ctx, params.Args...)
+	if !isStaleStatement(err) {
+		return res, err
+	}
+	fresh, perr := c.reprepare(params, query, stmt)
+	if perr != nil {
+		return nil, perr
+	}
+	return fresh.ExecContext(ctx, params.Args...)
+}
+
+// reprepare drops stale from the statement cache and prepares query again.
+// If another caller already replaced the entry, the replacement is kept and
+// reused.
+func (c *MySQL) reprepare(params Params, query string, stale Stmt) (Stmt, error) {
+	key := query
+	if c.normalizeQueries {
+		key = normalizeQuery(query)
+	}
+
+	c.mx.Lock()
+	if c.prepare[key] == stale {
+		delete(c.prepare, key)
+		c.prepareLRU.remove(key)
+		_ = stale.Close()
+	}
+	c.mx.Unlock()
+
+	return c.prepareStatement(params, query)
+}
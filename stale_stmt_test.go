@@ -0,0 +1,155 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+var errStaleStmt = &mysqldriver.MySQLError{Number: 1243, Message: "Unknown prepared statement handler"}
+
+// sequenceDB hands out its statements in order, one per prepare, repeating
+// the last one once exhausted.
+type sequenceDB struct {
+	noDirectStatements
+	stmts    []*MockStmt
+	prepares int
+}
+
+func (d *sequenceDB) PrepareContext(ctx context.Context, query string) (Stmt, error) {
+	stmt := d.stmts[min(d.prepares, len(d.stmts)-1)]
+	d.prepares++
+	return stmt, nil
+}
+
+func (d *sequenceDB) Close() error { return nil }
+
+func TestQuery_ReprepareAfterUnknownStatement(t *testing.T) {
+	rows := func() Rows { return &MockRows{data: [][]any{{"ok"}}} }
+	fresh := &MockStmt{Factory: rows}
+	db := &sequenceDB{stmts: []*MockStmt{{Err: errStaleStmt, Factory: rows}, fresh}}
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	res, err := Query(client, Params{Query: "SELECT 1"}, scanStrings)
+	if err != nil {
+		t.Fatalf("expected transparent retry, got %v", err)
+	}
+	if len(*res) != 1 || (*res)[0] != "ok" {
+		t.Fatalf("unexpected result: %v", *res)
+	}
+	if db.prepares != 2 {
+		t.Fatalf("expected one re-prepare, got %d prepares", db.prepares)
+	}
+	if client.prepare["SELECT 1"] != Stmt(fresh) {
+		t.Fatal("expected the fresh statement to replace the stale one in the cache")
+	}
+}
+
+func TestQuery_ReprepareRetriesOnce(t *testing.T) {
+	db := &sequenceDB{stmts: []*MockStmt{{Err: errStaleStmt, Factory: func() Rows { return &MockRows{} }}}}
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	_, err := Query(client, Params{Query: "SELECT 1"}, scanStrings)
+	var sqlErr *mysqldriver.MySQLError
+	if !errors.As(err, &sqlErr) || sqlErr.Number != 1243 {
+		t.Fatalf("expected the 1243 error after a single retry, got %v", err)
+	}
+	if db.prepares != 2 {
+		t.Fatalf("expected exactly one re-prepare, got %d prepares", db.prepares)
+	}
+}
+
+func TestExec_ReprepareAfterUnknownStatement(t *testing.T) {
+	db := &sequenceDB{stmts: []*MockStmt{
+		{Err: errStaleStmt, Factory: func() Rows { return &MockRows{} }},
+		{Factory: func() Rows { return &MockRows{} }},
+	}}
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	if _, err := Exec(client, Params{Query: "UPDATE t SET x = 1"}); err != nil {
+		t.Fatalf("expected transparent retry, got %v", err)
+	}
+	if db.prepares != 2 {
+		t.Fatalf("expected one re-prepare, got %d prepares", db.prepares)
+	}
+}
+
+func TestQuery_RetryAfterConnectionLost(t *testing.T) {
+	for _, lost := range []error{
+		&mysqldriver.MySQLError{Number: 2006, Message: "MySQL server has gone away"},
+		&mysqldriver.MySQLError{Number: 2013, Message: "Lost connection to MySQL server during query"},
+		mysqldriver.ErrInvalidConn,
+	} {
+		rows := func() Rows { return &MockRows{data: [][]any{{"ok"}}} }
+		db := &sequenceDB{stmts: []*MockStmt{{Err: lost, Factory: rows}, {Factory: rows}}}
+		client, cleanup := newInternalClient(db)
+
+		res, err := Query(client, Params{Query: "SELECT 1"}, scanStrings)
+		cleanup()
+		if err != nil {
+			t.Fatalf("%v: expected transparent retry, got %v", lost, err)
+		}
+		if (*res)[0] != "ok" || db.prepares != 2 {
+			t.Fatalf("%v: unexpected result %v after %d prepares", lost, *res, db.prepares)
+		}
+	}
+}
+
+func TestExec_NoRetryAfterConnectionLost(t *testing.T) {
+	lost := &mysqldriver.MySQLError{Number: 2013, Message: "Lost connection to MySQL server during query"}
+	db := &sequenceDB{stmts: []*MockStmt{
+		{Err: lost, Factory: func() Rows { return &MockRows{} }},
+		{Factory: func() Rows { return &MockRows{} }},
+	}}
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	if _, err := Exec(client, Params{Query: "UPDATE t SET x = x + 1"}); err == nil {
+		t.Fatal("expected the lost-connection error; the write may have been applied")
+	}
+	if db.prepares != 1 {
+		t.Fatalf("expected no re-prepare for a write, got %d prepares", db.prepares)
+	}
+}
+
+func TestQuery_ProcedureNoRetryAfterConnectionLost(t *testing.T) {
+	lost := &mysqldriver.MySQLError{Number: 2013, Message: "Lost connection to MySQL server during query"}
+	rows := func() Rows { return &MockRows{data: [][]any{{"ok"}}} }
+	db := &sequenceDB{stmts: []*MockStmt{{Err: lost, Factory: rows}, {Factory: rows}}}
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	// The procedure may write, so it must not run twice
+	if _, err := Query(client, Params{Exec: "add_visit", Args: []any{1}}, scanStrings); err == nil {
+		t.Fatal("expected the lost-connection error for a procedure call")
+	}
+	if db.prepares != 1 {
+		t.Fatalf("expected no re-prepare for a procedure call, got %d prepares", db.prepares)
+	}
+
+	// An unknown statement is still prepared again
+	stale := &sequenceDB{stmts: []*MockStmt{{Err: errStaleStmt, Factory: rows}, {Factory: rows}}}
+	client.DB = stale
+	client.prepare = make(map[string]Stmt)
+	if _, err := Query(client, Params{Exec: "add_visit", Args: []any{1}}, scanStrings); err != nil || stale.prepares != 2 {
+		t.Fatalf("expected one re-prepare, got %v after %d prepares", err, stale.prepares)
+	}
+}
+
+func TestQueryScalar_ReprepareAfterUnknownStatement(t *testing.T) {
+	rows := func() Rows { return &MockRows{data: [][]any{{int64(3)}}} }
+	db := &sequenceDB{stmts: []*MockStmt{{Err: errStaleStmt, Factory: rows}, {Factory: rows}}}
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	// The error only surfaces from Row.Scan, where the query is retried
+	n, err := QueryScalar[int64](client, Params{Query: "SELECT 1"})
+	if err != nil || *n != 3 || db.prepares != 2 {
+		t.Fatalf("expected 3 after one re-prepare, got %v (%v) after %d prepares", n, err, db.prepares)
+	}
+}
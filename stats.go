@@ -0,0 +1,38 @@
+package mysql
+
+import "sync/atomic"
+
+// Stats is a point-in-time snapshot of client activity.
+type Stats struct {
+	InFlightQueries int    // Statements currently executing against the database
+	CacheL1Hits     uint64 // Results served from the in-memory cache
+	CacheL2Hits     uint64 // Results served from the external cache
+	CacheMisses     uint64 // Cacheable lookups that fell through to the database or loader
+	DecodeErrors    uint64 // External cache entries that could not be decoded and were treated as misses
+	CodecMismatches uint64 // Decode errors caused by entries tagged with a codec the client does not accept
+	LegacyDecodes   uint64 // External cache entries decoded with one of Options.LegacyCodecs
+}
+
+// cacheCounters accumulates cache outcomes for Stats.
+type cacheCounters struct {
+	l1Hits atomic.Uint64
+	l2Hits atomic.Uint64
+	misses atomic.Uint64
+
+	decodeErrors    atomic.Uint64
+	codecMismatches atomic.Uint64
+	legacyDecodes   atomic.Uint64
+}
+
+// Stats returns current client activity counters.
+func (c *MySQL) Stats() Stats {
+	return Stats{
+		InFlightQueries: int(c.activeQueries.Load()),
+		CacheL1Hits:     c.cacheStats.l1Hits.Load(),
+		CacheL2Hits:     c.cacheStats.l2Hits.Load(),
+		CacheMisses:     c.cacheStats.misses.Load(),
+		DecodeErrors:    c.cacheStats.decodeErrors.Load(),
+		CodecMismatches: c.cacheStats.codecMismatches.Load(),
+		LegacyDecodes:   c.cacheStats.legacyDecodes.Load(),
+	}
+}
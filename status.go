@@ -0,0 +1,42 @@
+package mysql
+
+import "database/sql"
+
+// Status aggregates connection pool, cache, and prepared statement state in
+// one snapshot, e.g. for a /debug/status endpoint.
+type Status struct {
+	Stats // Query and cache counters
+
+	Closed             bool        // Close or Shutdown has begun
+	Healthy            bool        // Last background health check succeeded (always true without HealthCheckInterval)
+	Pool               sql.DBStats // Connection pool statistics (zero without a *sql.DB)
+	PreparedStatements int         // Statements held in the prepared statement cache
+	L1Entries          int         // Entries in the in-memory cache
+	L1Bytes            int         // Approximate bytes held by the in-memory cache
+	CacheBreaker       string      // External cache breaker state: "closed", "open", "half-open" ("" when not configured)
+}
+
+// Status returns a snapshot of the client's pool, cache, and statement state.
+func (c *MySQL) Status() Status {
+	s := Status{
+		Stats:   c.Stats(),
+		Closed:  c.closed.Load(),
+		Healthy: c.Healthy(),
+	}
+	if c.db != nil {
+		s.Pool = c.db.Stats()
+	}
+
+	c.mx.RLock()
+	s.PreparedStatements = len(c.prepare)
+	c.mx.RUnlock()
+
+	if sizer, ok := c.inMemory.(l1Sizer); ok {
+		s.L1Entries = sizer.Len()
+		s.L1Bytes = sizer.Bytes()
+	}
+	if b, ok := c.cache.(*breakerStorage); ok {
+		s.CacheBreaker = b.breaker.current().String()
+	}
+	return s
+}
@@ -0,0 +1,61 @@
+package mysql
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatus_ReflectsStatementsAndCache(t *testing.T) {
+	client, err := NewWithDB(newTestSQLDB(nil), Options{Database: "db", CacheEnabled: true})
+	if err != nil {
+		t.Fatalf("NewWithDB: %v", err)
+	}
+	defer client.Close()
+
+	for _, q := range []string{"SELECT value FROM a", "SELECT value FROM b", "SELECT value FROM a"} {
+		if _, merr := Query(client, Params{Query: q, CacheDelay: time.Minute}, scanStrings); merr != nil {
+			t.Fatalf("Query(%q): %v", q, merr)
+		}
+	}
+
+	s := client.Status()
+	if s.PreparedStatements != 2 {
+		t.Fatalf("expected 2 prepared statements, got %d", s.PreparedStatements)
+	}
+	if s.CacheMisses != 2 || s.CacheL1Hits != 1 || s.CacheL2Hits != 0 {
+		t.Fatalf("unexpected cache counters: %+v", s.Stats)
+	}
+	if s.L1Entries != 2 || s.L1Bytes <= 0 {
+		t.Fatalf("expected 2 L1 entries with tracked bytes, got %d entries, %d bytes", s.L1Entries, s.L1Bytes)
+	}
+	if s.Pool.OpenConnections < 1 {
+		t.Fatalf("expected pool stats from the *sql.DB, got %+v", s.Pool)
+	}
+	if s.CacheBreaker != "" || s.Closed {
+		t.Fatalf("unexpected status: %+v", s)
+	}
+
+	client.Close()
+	if !client.Status().Closed {
+		t.Fatal("expected Closed after Close")
+	}
+}
+
+func TestStatus_ExternalCacheAndBreaker(t *testing.T) {
+	cache := newFakeCache()
+	client, cleanup := newExternalClient(NewMockDB(), cache)
+	defer cleanup()
+	client.cache = newBreakerStorage(cache, 1, time.Minute, realClock{})
+
+	params := Params{Key: "k", Query: "SELECT 1", CacheDelay: time.Minute}
+	data, _ := client.codec.Marshal([]string{"cached"})
+	_ = cache.Set("k", data, time.Minute)
+
+	if _, merr := Query(client, params, scanStrings); merr != nil {
+		t.Fatalf("unexpected error: %v", merr)
+	}
+	s := client.Status()
+	if s.CacheL2Hits != 1 || s.CacheBreaker != "closed" {
+		t.Fatalf("unexpected status: %+v", s)
+	}
+}
@@ -0,0 +1,54 @@
+package mysql
+
+import "container/list"
+
+// stmtLRU tracks the recency of cached prepared statements so the least
+// recently used one can be evicted when MaxPreparedStatements is exceeded.
+// It is not safe for concurrent use; callers hold MySQL.mx.
+type stmtLRU struct {
+	order *list.List               // Query keys, front = most recently used
+	elems map[string]*list.Element // Query key to list element
+}
+
+// touch marks key as most recently used, adding it when absent.
+func (l *stmtLRU) touch(key string) {
+	if l.order == nil {
+		l.order = list.New()
+		l.elems = make(map[string]*list.Element)
+	}
+	if el, ok := l.elems[key]; ok {
+		l.order.MoveToFront(el)
+		return
+	}
+	l.elems[key] = l.order.PushFront(key)
+}
+
+// removeOldest removes and returns the least recently used key.
+func (l *stmtLRU) removeOldest() (string, bool) {
+	if l.order == nil {
+		return "", false
+	}
+	el := l.order.Back()
+	if el == nil {
+		return "", false
+	}
+	key := l.order.Remove(el).(string)
+	delete(l.elems, key)
+	return key, true
+}
+
+// remove stops tracking key.
+func (l *stmtLRU) remove(key string) {
+	if el, ok := l.elems[key]; ok {
+		l.order.Remove(el)
+		delete(l.elems, key)
+	}
+}
+
+// len returns the number of tracked keys.
+func (l *stmtLRU) len() int {
+	if l.order == nil {
+		return 0
+	}
+	return l.order.Len()
+}
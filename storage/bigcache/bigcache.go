@@ -0,0 +1,95 @@
+package bigcache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/allegro/bigcache/v3"
+	"github.com/elum-utils/mysql"
+)
+
+// deadlineSize is the length of the expiry prefix stored with every value.
+const deadlineSize = 8
+
+// Storage implements mysql.Storage over a BigCache instance.
+// It is safe for concurrent use.
+type Storage struct {
+	cache *bigcache.BigCache
+	now   func() time.Time // Time source for per-entry deadlines
+}
+
+// New creates a Storage whose entries live at most lifeWindow, using
+// BigCache's default configuration otherwise.
+func New(lifeWindow time.Duration) (*Storage, error) {
+	return NewWithConfig(bigcache.DefaultConfig(lifeWindow))
+}
+
+// NewWithConfig creates a Storage from a full BigCache configuration, e.g.
+// to bound memory with HardMaxCacheSize. config.LifeWindow caps every TTL.
+func NewWithConfig(config bigcache.Config) (*Storage, error) {
+	cache, err := bigcache.New(context.Background(), config)
+	if err != nil {
+		return nil, err
+	}
+	return &Storage{cache: cache, now: time.Now}, nil
+}
+
+// Get returns the value stored under key. Missing and expired entries are
+// reported as mysql.ErrNotFound.
+func (s *Storage) Get(key string) ([]byte, error) {
+	data, err := s.cache.Get(key)
+	if errors.Is(err, bigcache.ErrEntryNotFound) {
+		return nil, mysql.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < deadlineSize {
+		return nil, mysql.ErrNotFound
+	}
+
+	deadline := int64(binary.BigEndian.Uint64(data))
+	if deadline != 0 && s.now().UnixNano() >= deadline {
+		return nil, mysql.ErrNotFound
+	}
+	// BigCache returns a copy, so the payload can be handed out directly
+	return data[deadlineSize:], nil
+}
+
+// Set stores val under key. A positive exp shorter than the life window
+// expires the entry early; otherwise it lives for the life window.
+func (s *Storage) Set(key string, val []byte, exp time.Duration) error {
+	var deadline int64
+	if exp > 0 {
+		deadline = s.now().Add(exp).UnixNano()
+	}
+
+	data := make([]byte, deadlineSize+len(val))
+	binary.BigEndian.PutUint64(data, uint64(deadline))
+	copy(data[deadlineSize:], val)
+	return s.cache.Set(key, data)
+}
+
+// Delete removes key, returning mysql.ErrNotFound when it is not present.
+func (s *Storage) Delete(key string) error {
+	err := s.cache.Delete(key)
+	if errors.Is(err, bigcache.ErrEntryNotFound) {
+		return mysql.ErrNotFound
+	}
+	return err
+}
+
+// Reset removes all entries.
+func (s *Storage) Reset() error {
+	return s.cache.Reset()
+}
+
+// Close stops BigCache's cleanup goroutine. The Storage must not be used
+// afterwards.
+func (s *Storage) Close() error {
+	return s.cache.Close()
+}
+
+var _ mysql.Storage = (*Storage)(nil)
@@ -0,0 +1,92 @@
+package bigcache
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/elum-utils/mysql"
+)
+
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+	s, err := New(time.Hour)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestStorage_RoundTrip(t *testing.T) {
+	s := newTestStorage(t)
+
+	if err := s.Set("k", []byte("value"), time.Minute); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	got, err := s.Get("k")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !bytes.Equal(got, []byte("value")) {
+		t.Fatalf("expected value, got %q", got)
+	}
+
+	// Empty values and exp 0 round-trip too
+	if err := s.Set("empty", nil, 0); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if got, err := s.Get("empty"); err != nil || len(got) != 0 {
+		t.Fatalf("expected empty value, got %q, %v", got, err)
+	}
+}
+
+func TestStorage_Miss(t *testing.T) {
+	s := newTestStorage(t)
+
+	if _, err := s.Get("missing"); !errors.Is(err, mysql.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if err := s.Delete("missing"); !errors.Is(err, mysql.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound from Delete, got %v", err)
+	}
+}
+
+func TestStorage_PerEntryTTL(t *testing.T) {
+	s := newTestStorage(t)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	_ = s.Set("short", []byte("a"), time.Minute)
+	_ = s.Set("forever", []byte("b"), 0)
+
+	now = now.Add(2 * time.Minute)
+	if _, err := s.Get("short"); !errors.Is(err, mysql.ErrNotFound) {
+		t.Fatalf("expected the short entry to expire, got %v", err)
+	}
+	if _, err := s.Get("forever"); err != nil {
+		t.Fatalf("expected exp 0 to live for the life window, got %v", err)
+	}
+}
+
+func TestStorage_DeleteAndReset(t *testing.T) {
+	s := newTestStorage(t)
+
+	_ = s.Set("a", []byte("1"), time.Minute)
+	_ = s.Set("b", []byte("2"), time.Minute)
+
+	if err := s.Delete("a"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := s.Get("a"); !errors.Is(err, mysql.ErrNotFound) {
+		t.Fatalf("expected deleted key to miss, got %v", err)
+	}
+
+	if err := s.Reset(); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	if _, err := s.Get("b"); !errors.Is(err, mysql.ErrNotFound) {
+		t.Fatalf("expected Reset to clear entries, got %v", err)
+	}
+}
@@ -0,0 +1,10 @@
+// Package bigcache provides a mysql.Storage backed by BigCache, for large
+// in-process caches where the pointer-heavy map of mysql.InMemoryStorage
+// would put pressure on the garbage collector.
+//
+// BigCache expires entries on a single global LifeWindow rather than per
+// entry. Storage records each entry's deadline next to its value and honors
+// it on Get, so per-call TTLs shorter than the life window work as usual;
+// longer TTLs, and exp 0 ("never expire"), are capped at the life window.
+// Expired entries occupy memory until BigCache's cleanup evicts them.
+package bigcache
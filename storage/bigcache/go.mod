@@ -0,0 +1,20 @@
+module github.com/elum-utils/mysql/storage/bigcache
+
+go 1.21.0
+
+require (
+	github.com/allegro/bigcache/v3 v3.1.0
+	github.com/elum-utils/mysql v0.0.0-00010101000000-000000000000
+)
+
+require (
+	filippo.io/edwards25519 v1.1.0 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/go-sql-driver/mysql v1.9.3 // indirect
+	github.com/vmihailenco/msgpack/v5 v5.4.1 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+)
+
+// Built against the enclosing checkout until a root release is required
+// together with its go.sum lines.
+replace github.com/elum-utils/mysql => ../..
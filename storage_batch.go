@@ -0,0 +1,128 @@
+package mysql
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CacheItem is a value and TTL written by BatchStorage.MultiSet.
+type CacheItem struct {
+	Value []byte        // Serialized value
+	TTL   time.Duration // Expiration, as for Storage.Set
+}
+
+// BatchStorage is an optional extension of Storage for backends that can
+// read or write many keys in one round trip (e.g. Redis MGET and pipelined
+// SET). Batch APIs such as LoadMany use it when Options.Cache implements it
+// and fall back to one Get or Set per key otherwise.
+type BatchStorage interface {
+	// MultiGet returns the values for keys, index-aligned with keys. A
+	// missing key has a nil value and ErrNotFound at its index; other
+	// errors are backend failures for that key. The error slice may be nil
+	// when every key was found.
+	MultiGet(keys []string) ([][]byte, []error)
+
+	// MultiSet stores every item under its key. It returns an error if any
+	// write failed; items written before the failure may remain stored.
+	MultiSet(items map[string]CacheItem) error
+}
+
+// multiGet reads keys from s in one MultiGet call when s is a BatchStorage,
+// or with one Get per key otherwise. The returned error slice always has
+// one entry per key.
+func multiGet(s Storage, keys []string) ([][]byte, []error) {
+	if bs, ok := s.(BatchStorage); ok {
+		vals, errs := bs.MultiGet(keys)
+		if len(vals) != len(keys) || (errs != nil && len(errs) != len(keys)) {
+			// Misbehaving backend; report every key as failed
+			err := fmt.Errorf("mysql: MultiGet returned %d values and %d errors for %d keys", len(vals), len(errs), len(keys))
+			errs = make([]error, len(keys))
+			for i := range errs {
+				errs[i] = err
+			}
+			return make([][]byte, len(keys)), errs
+		}
+		if errs == nil {
+			errs = make([]error, len(keys))
+		}
+		return vals, errs
+	}
+
+	vals := make([][]byte, len(keys))
+	errs := make([]error, len(keys))
+	for i, key := range keys {
+		vals[i], errs[i] = s.Get(key)
+	}
+	return vals, errs
+}
+
+// multiSet writes items to s in one MultiSet call when s is a BatchStorage,
+// or with one Set per key otherwise, joining the per-key errors.
+func multiSet(s Storage, items map[string]CacheItem) error {
+	if bs, ok := s.(BatchStorage); ok {
+		return bs.MultiSet(items)
+	}
+
+	var errs []error
+	for key, item := range items {
+		if err := s.Set(key, item.Value, item.TTL); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// MultiGet implements BatchStorage for InMemoryStorage holding []byte values.
+// Values are returned as copies; keys holding other values report ErrNotBytes.
+func (s *inMemoryStore) MultiGet(keys []string) ([][]byte, []error) {
+	vals := make([][]byte, len(keys))
+	errs := make([]error, len(keys))
+	for i, key := range keys {
+		vals[i], errs[i] = s.GetRaw(key)
+	}
+	return vals, errs
+}
+
+// MultiSet implements BatchStorage for InMemoryStorage. Every item is stored
+// like Set; the first failure (e.g. ErrValueTooLarge) is returned after the
+// remaining items have been written.
+func (s *inMemoryStore) MultiSet(items map[string]CacheItem) error {
+	var first error
+	for key, item := range items {
+		if err := s.Set(key, item.Value, item.TTL); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// MultiGet implements BatchStorage, passing the batch through the breaker
+// as a single call that fails if any key hit a backend error.
+func (s *breakerStorage) MultiGet(keys []string) ([][]byte, []error) {
+	var vals [][]byte
+	var errs []error
+	err := s.call(func() error {
+		vals, errs = multiGet(s.Storage, keys)
+		for _, err := range errs {
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, ErrCacheCircuitOpen) {
+		errs = make([]error, len(keys))
+		for i := range errs {
+			errs[i] = err
+		}
+		return make([][]byte, len(keys)), errs
+	}
+	return vals, errs
+}
+
+// MultiSet implements BatchStorage, passing the batch through the breaker
+// as a single call.
+func (s *breakerStorage) MultiSet(items map[string]CacheItem) error {
+	return s.call(func() error { return multiSet(s.Storage, items) })
+}
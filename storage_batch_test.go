@@ -0,0 +1,118 @@
+package mysql
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// batchCache is a fakeCache that also implements BatchStorage and counts
+// the batch and single-key calls it receives.
+type batchCache struct {
+	*fakeCache
+	gets, multiGets, multiSets int
+}
+
+func (c *batchCache) Get(key string) ([]byte, error) {
+	c.gets++
+	return c.fakeCache.Get(key)
+}
+
+func (c *batchCache) MultiGet(keys []string) ([][]byte, []error) {
+	c.multiGets++
+	vals := make([][]byte, len(keys))
+	errs := make([]error, len(keys))
+	for i, key := range keys {
+		vals[i], errs[i] = c.fakeCache.Get(key)
+	}
+	return vals, errs
+}
+
+func (c *batchCache) MultiSet(items map[string]CacheItem) error {
+	c.multiSets++
+	for key, item := range items {
+		_ = c.fakeCache.Set(key, item.Value, item.TTL)
+	}
+	return nil
+}
+
+// countingCache is a fakeCache without batch support that counts Get calls.
+type countingCache struct {
+	*fakeCache
+	gets int
+}
+
+func (c *countingCache) Get(key string) ([]byte, error) {
+	c.gets++
+	return c.fakeCache.Get(key)
+}
+
+func TestInMemoryStorage_MultiGetMultiSet(t *testing.T) {
+	store := NewInMemoryStorage(10, time.Minute)
+	defer store.Stop()
+
+	var _ BatchStorage = store
+	err := store.MultiSet(map[string]CacheItem{
+		"a": {Value: []byte("1"), TTL: time.Minute},
+		"b": {Value: []byte("2")},
+	})
+	if err != nil {
+		t.Fatalf("MultiSet failed: %v", err)
+	}
+	_ = store.Set("obj", 3, time.Minute)
+
+	vals, errs := store.MultiGet([]string{"a", "missing", "b", "obj"})
+	if string(vals[0]) != "1" || string(vals[2]) != "2" || errs[0] != nil || errs[2] != nil {
+		t.Fatalf("unexpected hits: %q, %v", vals, errs)
+	}
+	if !errors.Is(errs[1], ErrNotFound) || vals[1] != nil {
+		t.Fatalf("expected ErrNotFound for the missing key, got %v", errs[1])
+	}
+	if !errors.Is(errs[3], ErrNotBytes) {
+		t.Fatalf("expected ErrNotBytes for a non-byte value, got %v", errs[3])
+	}
+}
+
+func TestMultiGetMultiSet_Fallback(t *testing.T) {
+	cache := &countingCache{fakeCache: newFakeCache()}
+
+	if err := multiSet(cache, map[string]CacheItem{"a": {Value: []byte("1")}, "b": {Value: []byte("2")}}); err != nil {
+		t.Fatalf("multiSet failed: %v", err)
+	}
+	if cache.setCalls != 2 {
+		t.Fatalf("expected one Set per key, got %d", cache.setCalls)
+	}
+
+	vals, errs := multiGet(cache, []string{"a", "x", "b"})
+	if cache.gets != 3 {
+		t.Fatalf("expected one Get per key, got %d", cache.gets)
+	}
+	if string(vals[0]) != "1" || string(vals[2]) != "2" || !errors.Is(errs[1], ErrNotFound) {
+		t.Fatalf("unexpected results: %q, %v", vals, errs)
+	}
+
+	cache.setErr = errors.New("down")
+	if err := multiSet(cache, map[string]CacheItem{"c": {Value: []byte("3")}}); !errors.Is(err, cache.setErr) {
+		t.Fatalf("expected the Set error to be reported, got %v", err)
+	}
+}
+
+func TestBreakerStorage_PassesBatchesThrough(t *testing.T) {
+	inner := &batchCache{fakeCache: newFakeCache()}
+	cache := newBreakerStorage(inner, 1, time.Minute, newFakeClock())
+
+	_ = multiSet(cache, map[string]CacheItem{"a": {Value: []byte("1")}})
+	if _, errs := multiGet(cache, []string{"a", "b"}); errs[0] != nil || !errors.Is(errs[1], ErrNotFound) {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if inner.multiSets != 1 || inner.multiGets != 1 || inner.gets != 0 {
+		t.Fatalf("expected batch calls, got %d MultiSet, %d MultiGet, %d Get", inner.multiSets, inner.multiGets, inner.gets)
+	}
+
+	// A backend failure in the batch opens the breaker
+	inner.getErr = errors.New("down")
+	multiGet(cache, []string{"a"})
+	if _, errs := multiGet(cache, []string{"a"}); !errors.Is(errs[0], ErrCacheCircuitOpen) {
+		t.Fatalf("expected ErrCacheCircuitOpen, got %v", errs[0])
+	}
+}
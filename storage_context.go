@@ -0,0 +1,62 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ContextStorage is an optional extension of Storage for network-backed
+// caches (e.g. Redis) that accept a context. When Options.Cache implements
+// it, Query and the other cache-aside helpers call GetCtx and SetCtx with
+// the request context (Params.Context), so a cancelled or timed-out request
+// also abandons its cache round trips. Get and Set are still used where no
+// request context exists, such as asynchronous cache writes.
+type ContextStorage interface {
+	// GetCtx is Get bounded by ctx.
+	GetCtx(ctx context.Context, key string) ([]byte, error)
+
+	// SetCtx is Set bounded by ctx.
+	SetCtx(ctx context.Context, key string, val []byte, exp time.Duration) error
+}
+
+// cacheGet reads key from s, through GetCtx when s is a ContextStorage and
+// ctx is not nil.
+func cacheGet(ctx context.Context, s Storage, key string) ([]byte, error) {
+	if cs, ok := s.(ContextStorage); ok && ctx != nil {
+		return cs.GetCtx(ctx, key)
+	}
+	return s.Get(key)
+}
+
+// cacheSet writes key to s, through SetCtx when s is a ContextStorage and
+// ctx is not nil.
+func cacheSet(ctx context.Context, s Storage, key string, val []byte, exp time.Duration) error {
+	if cs, ok := s.(ContextStorage); ok && ctx != nil {
+		return cs.SetCtx(ctx, key, val, exp)
+	}
+	return s.Set(key, val, exp)
+}
+
+// GetCtx implements ContextStorage, passing ctx to the wrapped storage when
+// it supports one.
+func (s *breakerStorage) GetCtx(ctx context.Context, key string) ([]byte, error) {
+	var data []byte
+	err := s.call(func() (err error) {
+		data, err = cacheGet(ctx, s.Storage, key)
+		return err
+	})
+	return data, err
+}
+
+// SetCtx implements ContextStorage, passing ctx to the wrapped storage when
+// it supports one.
+func (s *breakerStorage) SetCtx(ctx context.Context, key string, val []byte, exp time.Duration) error {
+	return s.call(func() error { return cacheSet(ctx, s.Storage, key, val, exp) })
+}
+
+// isContextError reports whether err stems from a cancelled or expired
+// context rather than from the cache backend itself.
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
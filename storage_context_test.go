@@ -0,0 +1,125 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type ctxTestKey struct{}
+
+// ctxCache is a fakeCache implementing ContextStorage. GetCtx blocks until
+// ctx is done while block is set, and records the contexts it receives.
+type ctxCache struct {
+	*fakeCache
+	block    bool
+	started  chan struct{}
+	gets     []context.Context
+	sets     []context.Context
+	plainOps int
+}
+
+func newCtxCache() *ctxCache {
+	return &ctxCache{fakeCache: newFakeCache(), started: make(chan struct{}, 1)}
+}
+
+func (c *ctxCache) Get(key string) ([]byte, error) {
+	c.plainOps++
+	return c.fakeCache.Get(key)
+}
+
+func (c *ctxCache) Set(key string, val []byte, exp time.Duration) error {
+	c.plainOps++
+	return c.fakeCache.Set(key, val, exp)
+}
+
+func (c *ctxCache) GetCtx(ctx context.Context, key string) ([]byte, error) {
+	c.gets = append(c.gets, ctx)
+	if c.block {
+		c.started <- struct{}{}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return c.fakeCache.Get(key)
+}
+
+func (c *ctxCache) SetCtx(ctx context.Context, key string, val []byte, exp time.Duration) error {
+	c.sets = append(c.sets, ctx)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.fakeCache.Set(key, val, exp)
+}
+
+func TestQuery_ContextStorageReceivesRequestContext(t *testing.T) {
+	cache := newCtxCache()
+	client, calls, cleanup := newCodecClient(t, cache)
+	defer cleanup()
+
+	ctx := context.WithValue(context.Background(), ctxTestKey{}, "req")
+	params := Params{Query: "SELECT a", CacheDelay: time.Minute, Context: ctx}
+	for i := 0; i < 2; i++ {
+		client.inMemory.(*InMemoryStorage).Reset()
+		if _, err := Query(client, params, scanStrings); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if *calls != 1 || len(cache.sets) != 1 || len(cache.gets) < 2 {
+		t.Fatalf("expected one fill and an L2 hit, got %d calls, %d SetCtx, %d GetCtx", *calls, len(cache.sets), len(cache.gets))
+	}
+	for _, got := range append(cache.gets, cache.sets...) {
+		if got.Value(ctxTestKey{}) != "req" {
+			t.Fatal("expected the cache to receive the request context")
+		}
+	}
+	if cache.plainOps != 0 {
+		t.Fatalf("expected Get/Set to be bypassed, got %d calls", cache.plainOps)
+	}
+}
+
+func TestQuery_ContextStorageCancellation(t *testing.T) {
+	cache := newCtxCache()
+	cache.block = true
+	client, calls, cleanup := newCodecClient(t, cache)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan *MySQLError, 1)
+	go func() {
+		_, err := Query(client, Params{Query: "SELECT a", CacheDelay: time.Minute, Context: ctx}, scanStrings)
+		done <- err
+	}()
+
+	<-cache.started
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrCanceled) {
+			t.Fatalf("expected ErrCanceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cancelling the request did not abort the cache read")
+	}
+	if *calls != 0 {
+		t.Fatalf("expected no database query after cancellation, got %d", *calls)
+	}
+}
+
+func TestBreakerStorage_IgnoresCancellation(t *testing.T) {
+	inner := newCtxCache()
+	cache := newBreakerStorage(inner, 1, time.Minute, newFakeClock())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := cache.SetCtx(ctx, "k", []byte("v"), time.Minute); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if err := cache.SetCtx(context.Background(), "k", []byte("v"), time.Minute); err != nil {
+		t.Fatalf("expected the breaker to stay closed after a cancellation, got %v", err)
+	}
+	if len(inner.sets) != 2 {
+		t.Fatalf("expected both calls to reach the wrapped storage, got %d", len(inner.sets))
+	}
+}
@@ -0,0 +1,84 @@
+package mysql
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrPrefixUnsupported is returned by InvalidatePrefix when the external
+// cache does not implement PrefixStorage.
+var ErrPrefixUnsupported = errors.New("cache storage does not support prefix deletion")
+
+// PrefixStorage is an optional extension of Storage for backends that can
+// delete every key sharing a prefix (e.g. Redis SCAN with MATCH plus UNLINK).
+// InvalidatePrefix requires it of Options.Cache.
+type PrefixStorage interface {
+	// DeletePrefix removes every key starting with prefix and reports how
+	// many were removed.
+	DeletePrefix(prefix string) (int, error)
+}
+
+// DeletePrefix removes every entry whose key starts with prefix and returns
+// the number removed. Eviction hooks see EvictManual, as for Delete.
+//
+// The store has no key index, so this scans all entries in O(n) while holding
+// the write lock; avoid calling it on hot paths of large caches.
+func (s *inMemoryStore) DeletePrefix(prefix string) (int, error) {
+	s.mu.Lock()
+	defer s.unlockAndNotify()
+
+	n := 0
+	for key, e := range s.items {
+		if strings.HasPrefix(key, prefix) {
+			s.removeElement(e, EvictManual)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// DeletePrefix implements PrefixStorage when the wrapped storage does. A
+// backend without prefix support reports ErrPrefixUnsupported without
+// counting against the breaker.
+func (s *breakerStorage) DeletePrefix(prefix string) (int, error) {
+	ps, ok := s.Storage.(PrefixStorage)
+	if !ok {
+		return 0, ErrPrefixUnsupported
+	}
+	var n int
+	err := s.call(func() (err error) {
+		n, err = ps.DeletePrefix(prefix)
+		return err
+	})
+	return n, err
+}
+
+// InvalidatePrefix removes every cached result whose key starts with prefix
+// from L1 and from the external cache, e.g. all manual keys of a tenant
+// ("tenant:42:") or every generated key of a CacheVersion ("v3"). Generated
+// keys condensed by a KeyHasher share no readable prefix.
+//
+// It returns the number of keys removed from the external cache, or from L1
+// when there is none. With an external cache, it must implement
+// PrefixStorage; otherwise L1 is still cleared and ErrPrefixUnsupported is
+// returned. An L1 set through Options.L1 is cleared only if it implements
+// PrefixStorage as well. Writes still queued by AsyncCacheWrites are not
+// affected; call Flush first if they may carry the prefix.
+func (c *MySQL) InvalidatePrefix(prefix string) (int, error) {
+	if prefix == "" {
+		return 0, errors.New("mysql: InvalidatePrefix requires a non-empty prefix")
+	}
+
+	var n int
+	if l1, ok := c.inMemory.(PrefixStorage); ok {
+		n, _ = l1.DeletePrefix(prefix)
+	}
+	if c.cache == nil {
+		return n, nil
+	}
+	ps, ok := c.cache.(PrefixStorage)
+	if !ok {
+		return 0, ErrPrefixUnsupported
+	}
+	return ps.DeletePrefix(prefix)
+}
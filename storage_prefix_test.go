@@ -0,0 +1,105 @@
+package mysql
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// prefixCache adds PrefixStorage to fakeCache.
+type prefixCache struct {
+	*fakeCache
+}
+
+func (c prefixCache) DeletePrefix(prefix string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for key := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.items, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func TestInMemoryStorage_DeletePrefix(t *testing.T) {
+	s := NewInMemoryStorage(10, time.Minute)
+	defer s.Stop()
+
+	var evicted []string
+	s.SetOnEvict(func(key string, _ any, reason EvictReason) {
+		if reason == EvictManual {
+			evicted = append(evicted, key)
+		}
+	})
+	for _, key := range []string{"tenant:1:a", "tenant:1:b", "tenant:10:a", "tenant:2:a", "other"} {
+		_ = s.Set(key, key, 0)
+	}
+
+	n, err := s.DeletePrefix("tenant:1:")
+	if err != nil || n != 2 {
+		t.Fatalf("expected 2 keys removed, got %d (%v)", n, err)
+	}
+	sort.Strings(evicted)
+	if !reflect.DeepEqual(evicted, []string{"tenant:1:a", "tenant:1:b"}) {
+		t.Fatalf("unexpected eviction hooks: %v", evicted)
+	}
+	keys := s.Keys()
+	sort.Strings(keys)
+	if !reflect.DeepEqual(keys, []string{"other", "tenant:10:a", "tenant:2:a"}) {
+		t.Fatalf("unrelated keys should survive, got %v", keys)
+	}
+	if s.Len() != 3 {
+		t.Fatalf("expected size 3, got %d", s.Len())
+	}
+}
+
+func TestInvalidatePrefix_L1AndExternal(t *testing.T) {
+	cache := prefixCache{newFakeCache()}
+	client, cleanup := newExternalClient(NewMockDB(), cache)
+	defer cleanup()
+	client.cache = newBreakerStorage(cache, 1, time.Minute, realClock{})
+
+	for _, key := range []string{"tenant:1:users", "tenant:1:orders", "tenant:2:users"} {
+		_ = cache.Set(key, []byte(key), time.Minute)
+		_ = client.inMemory.Set(key, key, time.Minute)
+	}
+
+	n, err := client.InvalidatePrefix("tenant:1:")
+	if err != nil || n != 2 {
+		t.Fatalf("expected 2 external keys removed, got %d (%v)", n, err)
+	}
+	if _, err := cache.Get("tenant:2:users"); err != nil {
+		t.Fatalf("unrelated external key was removed: %v", err)
+	}
+	if keys := client.inMemory.(*InMemoryStorage).Keys(); !reflect.DeepEqual(keys, []string{"tenant:2:users"}) {
+		t.Fatalf("expected only the unrelated L1 key to survive, got %v", keys)
+	}
+}
+
+func TestInvalidatePrefix_Unsupported(t *testing.T) {
+	cache := newFakeCache()
+	client, cleanup := newExternalClient(NewMockDB(), cache)
+	defer cleanup()
+	client.cache = newBreakerStorage(cache, 1, time.Minute, realClock{})
+
+	_ = client.inMemory.Set("tenant:1:users", "x", time.Minute)
+	if _, err := client.InvalidatePrefix("tenant:1:"); !errors.Is(err, ErrPrefixUnsupported) {
+		t.Fatalf("expected ErrPrefixUnsupported, got %v", err)
+	}
+	if client.inMemory.(*InMemoryStorage).Len() != 0 {
+		t.Fatal("expected L1 to be cleared even without external support")
+	}
+	if client.Status().CacheBreaker != "closed" {
+		t.Fatal("an unsupported operation must not trip the breaker")
+	}
+
+	if _, err := client.InvalidatePrefix(""); err == nil {
+		t.Fatal("expected an empty prefix to be rejected")
+	}
+}
@@ -0,0 +1,148 @@
+package mysql
+
+import (
+	"encoding/json"
+	"io"
+	"unicode/utf8"
+)
+
+// StreamJSON executes the query described by params and writes the result to
+// w as a JSON array of objects keyed by column name, preserving column order:
+//
+//	[{"id":1,"name":"alice"},{"id":2,"name":"bob"}]
+//
+// Each row is encoded and written as soon as it is read, so memory use does
+// not grow with the result size; if w has a Flush method (http.Flusher or
+// bufio.Writer) it is called after every row. Text columns returned by the
+// driver as []byte are written as JSON strings and binary columns (BLOB,
+// BINARY, ...) as base64, like encoding/json does for []byte. Without column
+// type metadata (e.g. MockRows), []byte values that are valid UTF-8 are
+// treated as text.
+//
+// Streaming results are never cached, so cache-related Params are ignored.
+// An empty result set produces "[]". If writing to w fails, the error is
+// returned as a generic 45000 MySQLError; the output is then incomplete. So
+// it is when the query times out or is cancelled mid-stream: the closing
+// bracket is not written and the context error is returned.
+func StreamJSON(c *MySQL, params Params, w io.Writer) *MySQLError {
+	params = c.withDefaultDatabase(params.withExpandedSlices())
+	if merr := c.validateStatement(params); merr != nil {
+		return merr
+	}
+	if !c.beginQuery() {
+		return ErrClosed
+	}
+	defer c.endQuery()
+
+	query := generateQuery(params)
+	prepare, err := c.prepareStatement(params, query)
+	if err != nil {
+		return c.mapError(err)
+	}
+
+	ctx, cancel := createContextWithTimeout(params.requestContext(), params.Timeout)
+	defer cancel()
+
+	release, err := c.acquireQuerySlot(ctx)
+	if err != nil {
+		return c.mapError(err)
+	}
+	defer release()
+
+	rows, err := c.queryStmt(ctx, params, query, prepare)
+	if err != nil {
+		return c.mapError(err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return c.mapError(err)
+	}
+
+	// Pre-encode column names once; they prefix every value in every row
+	keys := make([][]byte, len(cols))
+	for i, col := range cols {
+		name, _ := json.Marshal(col)
+		keys[i] = append(name, ':')
+	}
+
+	typeNames := columnTypeNames(rows)
+
+	values := make([]any, len(cols))
+	dest := make([]any, len(cols))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	buf := make([]byte, 0, 256)
+	buf = append(buf, '[')
+	first := true
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return c.mapError(err)
+		}
+
+		if !first {
+			buf = append(buf, ',')
+		}
+		first = false
+
+		buf = append(buf, '{')
+		for i, v := range values {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = append(buf, keys[i]...)
+			if b, ok := v.([]byte); ok && streamAsText(b, typeNames, i) {
+				v = string(b)
+			}
+			enc, err := json.Marshal(v)
+			if err != nil {
+				return NewError(err)
+			}
+			buf = append(buf, enc...)
+		}
+		buf = append(buf, '}')
+
+		if err := writeFlush(w, buf); err != nil {
+			return NewError(err)
+		}
+		buf = buf[:0]
+	}
+	// An expired or cancelled context ends the rows early; never report the
+	// truncated array as complete
+	if ctx.Err() != nil {
+		return c.mapError(ctx.Err())
+	}
+	buf = append(buf, ']')
+
+	if err := writeFlush(w, buf); err != nil {
+		return NewError(err)
+	}
+	return nil
+}
+
+// streamAsText reports whether the []byte value b of column i is written as
+// a JSON string: for text column types, or for valid UTF-8 when the column
+// types are unknown.
+func streamAsText(b []byte, typeNames []string, i int) bool {
+	if i < len(typeNames) {
+		return textualType(typeNames[i])
+	}
+	return utf8.Valid(b)
+}
+
+// writeFlush writes p to w and flushes w if it supports flushing.
+func writeFlush(w io.Writer, p []byte) error {
+	if _, err := w.Write(p); err != nil {
+		return err
+	}
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case interface{ Flush() }:
+		f.Flush()
+	}
+	return nil
+}
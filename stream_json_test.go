@@ -0,0 +1,167 @@
+package mysql
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func newStreamClient(rows *MockRows) (*MySQL, func()) {
+	db := NewMockDB()
+	db.WithStmt("SELECT id, name FROM users", &MockStmt{Factory: func() Rows { return rows }})
+	return newInternalClient(db)
+}
+
+func TestStreamJSON_MultipleRows(t *testing.T) {
+	client, cleanup := newStreamClient(&MockRows{
+		cols: []string{"id", "name"},
+		data: [][]any{{int64(1), []byte("alice")}, {int64(2), nil}, {int64(3), "o\"brien"}},
+	})
+	defer cleanup()
+
+	var buf bytes.Buffer
+	if err := StreamJSON(client, Params{Query: "SELECT id, name FROM users"}, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `[{"id":1,"name":"alice"},{"id":2,"name":null},{"id":3,"name":"o\"brien"}]`
+	if buf.String() != want {
+		t.Fatalf("StreamJSON() = %s, want %s", buf.String(), want)
+	}
+
+	var decoded []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded) != 3 {
+		t.Fatalf("expected valid JSON with 3 objects, got %v (%v)", decoded, err)
+	}
+}
+
+func TestStreamJSON_EmptyResult(t *testing.T) {
+	client, cleanup := newStreamClient(&MockRows{cols: []string{"id", "name"}})
+	defer cleanup()
+
+	var buf bytes.Buffer
+	if err := StreamJSON(client, Params{Query: "SELECT id, name FROM users"}, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "[]" {
+		t.Fatalf("expected empty array, got %q", buf.String())
+	}
+}
+
+// countingFlusher records how many times Flush is called.
+type countingFlusher struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (f *countingFlusher) Flush() { f.flushes++ }
+
+func TestStreamJSON_FlushesPerRow(t *testing.T) {
+	client, cleanup := newStreamClient(&MockRows{
+		cols: []string{"id"},
+		data: [][]any{{1}, {2}},
+	})
+	defer cleanup()
+
+	var w countingFlusher
+	if err := StreamJSON(client, Params{Query: "SELECT id, name FROM users"}, &w); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w.flushes != 3 { // Two rows plus the closing bracket
+		t.Fatalf("expected 3 flushes, got %d", w.flushes)
+	}
+
+	// bufio.Writer's Flush() error variant is honoured as well
+	var out bytes.Buffer
+	bw := bufio.NewWriterSize(&out, 4096)
+	client2, cleanup2 := newStreamClient(&MockRows{cols: []string{"id"}, data: [][]any{{1}}})
+	defer cleanup2()
+	if err := StreamJSON(client2, Params{Query: "SELECT id, name FROM users"}, bw); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != `[{"id":1}]` {
+		t.Fatalf("expected bufio.Writer to be flushed, got %q", out.String())
+	}
+}
+
+// failingWriter rejects every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestStreamJSON_Errors(t *testing.T) {
+	client, cleanup := newStreamClient(&MockRows{cols: []string{"id"}, data: [][]any{{1}}})
+	defer cleanup()
+
+	err := StreamJSON(client, Params{Query: "SELECT id, name FROM users"}, failingWriter{})
+	if err == nil || err.Message != "disk full" {
+		t.Fatalf("expected write error, got %+v", err)
+	}
+
+	client.markClosed()
+	if err := StreamJSON(client, Params{Query: "SELECT id, name FROM users"}, &bytes.Buffer{}); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %+v", err)
+	}
+}
+
+// cancelRows cancels the request after its first row, as a timeout would.
+type cancelRows struct {
+	*MockRows
+	cancel context.CancelFunc
+}
+
+func (r *cancelRows) Next() bool {
+	if r.idx == 1 {
+		r.cancel()
+		return false
+	}
+	return r.MockRows.Next()
+}
+
+func TestStreamJSON_CancelledMidStream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rows := &cancelRows{MockRows: &MockRows{cols: []string{"id", "name"}, data: [][]any{{1, "a"}, {2, "b"}}}, cancel: cancel}
+	db := NewMockDB()
+	db.WithStmt("SELECT id, name FROM users", &MockStmt{Factory: func() Rows { return rows }})
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	var buf bytes.Buffer
+	err := StreamJSON(client, Params{Query: "SELECT id, name FROM users", Context: ctx}, &buf)
+	if !errors.Is(err, ErrCanceled) {
+		t.Fatalf("expected ErrCanceled, got %v", err)
+	}
+	if bytes.HasSuffix(buf.Bytes(), []byte("]")) {
+		t.Fatalf("a truncated stream must not be closed as complete: %s", buf.String())
+	}
+}
+
+func TestStreamJSON_BinaryColumnsByType(t *testing.T) {
+	sqlDB := sql.OpenDB(&typedConnector{
+		cols:  []string{"name", "avatar"},
+		types: []string{"VARCHAR", "BLOB"},
+		rows:  [][]driver.Value{{[]byte("bob"), []byte{0xff, 0x00, 'a'}}},
+	})
+	defer sqlDB.Close()
+	db := NewMockDB()
+	db.WithStmt("SELECT id, name FROM users", &MockStmt{Factory: func() Rows {
+		rows, _ := sqlDB.Query("SELECT 1")
+		return rows
+	}})
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+
+	var buf bytes.Buffer
+	if err := StreamJSON(client, Params{Query: "SELECT id, name FROM users"}, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `[{"name":"bob","avatar":"/wBh"}]`; buf.String() != want {
+		t.Fatalf("StreamJSON() = %s, want %s", buf.String(), want)
+	}
+}
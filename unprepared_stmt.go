@@ -0,0 +1,32 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+)
+
+// unpreparedStmt stands in for a prepared statement when Options.DisablePrepare
+// is set, so the query paths stay the same: reads and writes go straight to
+// DB.QueryContext and DB.ExecContext.
+type unpreparedStmt struct {
+	db    DB
+	query string
+}
+
+// QueryContext runs the query without preparing it.
+func (s *unpreparedStmt) QueryContext(ctx context.Context, args ...any) (Rows, error) {
+	return s.db.QueryContext(ctx, s.query, args...)
+}
+
+// QueryRowContext runs the query without preparing it and reads its first row.
+func (s *unpreparedStmt) QueryRowContext(ctx context.Context, args ...any) Row {
+	return rowFromRows(s.db.QueryContext(ctx, s.query, args...))
+}
+
+// ExecContext runs the statement without preparing it.
+func (s *unpreparedStmt) ExecContext(ctx context.Context, args ...any) (sql.Result, error) {
+	return s.db.ExecContext(ctx, s.query, args...)
+}
+
+// Close is a no-op; there is no server-side statement to release.
+func (s *unpreparedStmt) Close() error { return nil }
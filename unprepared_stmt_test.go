@@ -0,0 +1,105 @@
+package mysql
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+func TestQuery_DisablePrepareSkipsPrepare(t *testing.T) {
+	db := NewMockDB()
+	db.WithStmt("SELECT name FROM users WHERE id = ?", &MockStmt{Factory: func() Rows {
+		return &MockRows{data: [][]any{{"alice"}}}
+	}})
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+	client.disablePrepare = true
+
+	params := Params{Query: "SELECT name FROM users WHERE id = ?", Args: []any{1}}
+	for i := 0; i < 2; i++ {
+		res, err := Query(client, params, scanStrings)
+		if err != nil || (*res)[0] != "alice" {
+			t.Fatalf("unexpected result %v, %v", res, err)
+		}
+	}
+
+	if db.Prepares != 0 {
+		t.Fatalf("expected no prepares, got %d", db.Prepares)
+	}
+	if db.Queries != 2 {
+		t.Fatalf("expected 2 unprepared queries, got %d", db.Queries)
+	}
+	if len(client.prepare) != 0 {
+		t.Fatalf("expected no cached statements, got %d", len(client.prepare))
+	}
+}
+
+func TestQuery_DisablePrepareCachesResult(t *testing.T) {
+	db := NewMockDB()
+	db.WithStmt("SELECT name FROM users", &MockStmt{Factory: func() Rows {
+		return &MockRows{data: [][]any{{"alice"}}}
+	}})
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+	client.disablePrepare = true
+
+	params := Params{Query: "SELECT name FROM users", CacheDelay: time.Minute}
+	for i := 0; i < 2; i++ {
+		if _, err := Query(client, params, scanStrings); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if db.Queries != 1 || db.Prepares != 0 {
+		t.Fatalf("expected 1 query and no prepares, got %d and %d", db.Queries, db.Prepares)
+	}
+}
+
+func TestDefaultOptions_DisablePrepareInterpolatesParams(t *testing.T) {
+	opts := defaultOptions(Options{DisablePrepare: true})
+	if !strings.Contains(opts.ConnectionString, "&interpolateParams=true") {
+		t.Fatalf("expected interpolateParams in DSN, got %q", opts.ConnectionString)
+	}
+	if strings.Contains(defaultOptions(Options{}).ConnectionString, "interpolateParams") {
+		t.Fatal("expected interpolateParams only with DisablePrepare")
+	}
+}
+
+func TestDefaultOptions_DisablePrepareWithConnectionString(t *testing.T) {
+	dsn := "user:pass@tcp(db.local:3307)/app?parseTime=true&charset=utf8mb4"
+	opts := defaultOptions(Options{ConnectionString: dsn, DisablePrepare: true})
+	parsed, err := mysqldriver.ParseDSN(opts.ConnectionString)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !parsed.InterpolateParams || !parsed.ParseTime || parsed.DBName != "app" || parsed.Addr != "db.local:3307" {
+		t.Fatalf("expected interpolateParams added to the DSN, got %q", opts.ConnectionString)
+	}
+	if !strings.Contains(opts.ConnectionString, "charset=utf8mb4") {
+		t.Fatalf("expected other parameters to be kept, got %q", opts.ConnectionString)
+	}
+
+	if got := defaultOptions(Options{ConnectionString: dsn}).ConnectionString; got != dsn {
+		t.Fatalf("expected the DSN untouched without DisablePrepare, got %q", got)
+	}
+}
+
+func TestExec_DisablePrepareUsesDBExec(t *testing.T) {
+	db := NewMockDB()
+	db.ExecResult = MockResult{LastID: 3, Affected: 1}
+	client, cleanup := newInternalClient(db)
+	defer cleanup()
+	client.disablePrepare = true
+
+	res, err := Exec(client, Params{Query: "INSERT INTO users (name) VALUES (?)", Args: []any{"alice"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.RowsAffected != 1 || res.LastInsertID != 3 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if db.Prepares != 0 || len(db.Execs) != 1 || db.Execs[0].Args[0] != "alice" {
+		t.Fatalf("expected one unprepared exec, got %d prepares and %+v", db.Prepares, db.Execs)
+	}
+}